@@ -1,11 +1,3 @@
 package handle
 
-const (
-	SP1VERIFY      = 1
-	MIDENVERIFY    = 2
-	RISCZEROVERFIY = 3
-	JOLTVERIFY     = 4
-	PLONKY2VERIFY  = 5
-)
-
 const elfValType = 1
@@ -1,10 +1,53 @@
 package handle
 
-type VerifyRequestArgs struct {
-	TxID       string `json:"tx_id"`
-	VerifyType uint32 `json:"verify_type"`
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/protocol"
+	"github.com/sausaging/hyper-pvzk/requester"
+)
+
+// idempotencyKey identifies the submission of [txID] to a verifier, so that
+// retried and failed over submissions are only verified once.
+func idempotencyKey(txID ids.ID, verifyType uint32) string {
+	return txID.String() + "-" + strconv.FormatUint(uint64(verifyType), 10)
+}
+
+func requestVerify(ctx context.Context, endPointRequester *requester.EndpointRequester, txID ids.ID, verifyType uint32) error {
+	vargs := protocol.VerifyRequest{
+		TxID:       txID.String(),
+		VerifyType: verifyType,
+	}
+	reply := new(protocol.SubmitReply)
+	if err := endPointRequester.Post(ctx, protocol.VERIFYENDPOINT, idempotencyKey(txID, verifyType)+"-verify", vargs, reply); err != nil {
+		return fmt.Errorf("failed to request verification: %w", err)
+	}
+	return nil
+}
+
+// describeSegments adds the [segments] following the proof of a segmented
+// proof to the [valTypes] of a job.
+func describeSegments(valTypes map[string]uint16, segments []uint16) map[string]uint16 {
+	for i, valType := range segments {
+		valTypes[protocol.SegmentArtifact(i+1)] = valType
+	}
+	return valTypes
 }
 
-type VerifyReplyArgs struct {
-	IsSubmitted bool `json:"is_submitted"`
+// segmented describes the [segments] following the proof of [job].
+func segmented(job *artifacts.Job, segments []uint16) protocol.Segmented {
+	if len(segments) == 0 {
+		return protocol.Segmented{}
+	}
+	s := protocol.Segmented{Segments: len(segments) + 1}
+	for i := range segments {
+		if path := job.Path(protocol.SegmentArtifact(i + 1)); len(path) > 0 {
+			s.SegmentFilePaths = append(s.SegmentFilePaths, path)
+		}
+	}
+	return s
 }
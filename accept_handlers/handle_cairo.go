@@ -0,0 +1,45 @@
+package handle
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/protocol"
+	"github.com/sausaging/hyper-pvzk/requester"
+)
+
+func HandleCairo(
+	ctx context.Context,
+	txID ids.ID,
+	imageID ids.ID,
+	proofValType uint16,
+	programHash []byte,
+	store *artifacts.Store,
+	endPointRequester *requester.EndpointRequester,
+) error {
+	job, err := store.Describe(ctx, endPointRequester.Version(), imageID, map[string]uint16{
+		protocol.ArtifactProof: proofValType,
+	})
+	if err != nil {
+		return err
+	}
+	args := protocol.CairoJob{
+		TxID:          txID.String(),
+		ProgramHash:   hex.EncodeToString(programHash),
+		ProofFilePath: job.Path(protocol.ArtifactProof),
+		Artifacts:     job.Artifacts,
+	}
+
+	reply := new(protocol.SubmitReply)
+	if err := endPointRequester.Post(ctx, protocol.CAIROENDPOINT, idempotencyKey(txID, protocol.CAIROVERIFY), args, reply); err != nil {
+		return fmt.Errorf("failed to submit cairo request: %w", err)
+	}
+	if reply.IsSubmitted {
+		// call the submit-verify endpoint with txID
+		return requestVerify(ctx, endPointRequester, txID, protocol.CAIROVERIFY)
+	}
+	return nil
+}
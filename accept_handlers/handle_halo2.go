@@ -0,0 +1,48 @@
+package handle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/protocol"
+	"github.com/sausaging/hyper-pvzk/requester"
+)
+
+func HandleHalo2(
+	ctx context.Context,
+	txID ids.ID,
+	imageID ids.ID,
+	proofValType uint16,
+	verifyingKeyValType uint16,
+	instancesValType uint16,
+	store *artifacts.Store,
+	endPointRequester *requester.EndpointRequester,
+) error {
+	job, err := store.Describe(ctx, endPointRequester.Version(), imageID, map[string]uint16{
+		protocol.ArtifactProof:        proofValType,
+		protocol.ArtifactVerifyingKey: verifyingKeyValType,
+		protocol.ArtifactInstances:    instancesValType,
+	})
+	if err != nil {
+		return err
+	}
+	args := protocol.Halo2Job{
+		TxID:                 txID.String(),
+		ProofFilePath:        job.Path(protocol.ArtifactProof),
+		VerifyingKeyFilePath: job.Path(protocol.ArtifactVerifyingKey),
+		InstancesFilePath:    job.Path(protocol.ArtifactInstances),
+		Artifacts:            job.Artifacts,
+	}
+
+	reply := new(protocol.SubmitReply)
+	if err := endPointRequester.Post(ctx, protocol.HALO2ENDPOINT, idempotencyKey(txID, protocol.HALO2VERIFY), args, reply); err != nil {
+		return fmt.Errorf("failed to submit halo2 request: %w", err)
+	}
+	if reply.IsSubmitted {
+		// call the submit-verify endpoint with txID
+		return requestVerify(ctx, endPointRequester, txID, protocol.HALO2VERIFY)
+	}
+	return nil
+}
@@ -1,101 +1,70 @@
 package handle
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hyper-pvzk/actions"
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/protocol"
 	"github.com/sausaging/hyper-pvzk/requester"
-	"github.com/sausaging/hyper-pvzk/storage"
 )
 
-type MidenRequestArgs struct {
-	TxID            string `json:"tx_id"`
-	CodeFrontEnd    string `json:"code_front_end"`
-	InputsFrontEnd  string `json:"inputs_front_end"`
-	OutputsFrontEnd string `json:"outputs_front_end"`
-	ProofFilePath   string `json:"proof_file_path"`
+// MidenSource is a Miden program, inputs or outputs, either registered as an
+// artifact under ValType or carried inline when ValType is
+// [actions.InlineValType].
+type MidenSource struct {
+	ValType uint16
+	Inline  string
 }
 
-type MidenReplyArgs struct {
-	//@todo intro security field
-	IsSubmitted bool `json:"is_submitted"`
-}
-
-func HandleMiden( //@todo send the hashes stored for every proofvaltype to rust server
+func HandleMiden(
+	ctx context.Context,
 	txID ids.ID,
 	imageID ids.ID,
 	proofValType uint16,
-	codeFrontEnd string,
-	inputsFrontEnd string,
-	outputsFrontEnd string,
-	baseDir string,
+	code MidenSource,
+	inputs MidenSource,
+	outputs MidenSource,
+	store *artifacts.Store,
 	endPointRequester *requester.EndpointRequester) error {
-	proofKey := storage.DeployKey(imageID, proofValType)
-	proofFilePath := baseDir + "/" + proofKey
-
-	cli := endPointRequester.Cli
-	uri := endPointRequester.Uri + requester.MIDENENDPOINT
-	args := MidenRequestArgs{
-		TxID:            txID.String(),
-		CodeFrontEnd:    codeFrontEnd,
-		InputsFrontEnd:  inputsFrontEnd,
-		OutputsFrontEnd: outputsFrontEnd,
-		ProofFilePath:   proofFilePath,
+	valTypes := map[string]uint16{
+		protocol.ArtifactProof: proofValType,
 	}
-
-	jsonData, err := json.Marshal(args)
-	if err != nil {
-		return fmt.Errorf("failed to marshal miden request args: %w", err)
-	}
-	req, err := requester.NewRequest(uri, jsonData)
-	if err != nil {
-		return fmt.Errorf("failed to create new request in HandleMiden: %w", err)
-	}
-	resp, err := cli.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to do request in HandleMiden: %w", err)
+	for name, source := range map[string]MidenSource{
+		protocol.ArtifactCode:    code,
+		protocol.ArtifactInputs:  inputs,
+		protocol.ArtifactOutputs: outputs,
+	} {
+		if uint64(source.ValType) != actions.InlineValType {
+			valTypes[name] = source.ValType
+		}
 	}
-	body, err := ioutil.ReadAll(resp.Body)
+	job, err := store.Describe(ctx, endPointRequester.Version(), imageID, valTypes)
 	if err != nil {
-		return fmt.Errorf("failed to read response body in HandleMiden: %w", err)
+		return err
 	}
-	reply := new(MidenReplyArgs)
-	err = json.Unmarshal(body, reply)
-	if err != nil {
-		return fmt.Errorf("failed to unmarshal miden reply: %w", err)
+
+	args := protocol.MidenJob{
+		TxID:            txID.String(),
+		CodeFrontEnd:    code.Inline,
+		InputsFrontEnd:  inputs.Inline,
+		OutputsFrontEnd: outputs.Inline,
+		ProofFilePath:   job.Path(protocol.ArtifactProof),
+		CodeFilePath:    job.Path(protocol.ArtifactCode),
+		InputsFilePath:  job.Path(protocol.ArtifactInputs),
+		OutputsFilePath: job.Path(protocol.ArtifactOutputs),
+		Artifacts:       job.Artifacts,
 	}
 
+	reply := new(protocol.SubmitReply)
+	if err := endPointRequester.Post(ctx, protocol.MIDENENDPOINT, idempotencyKey(txID, protocol.MIDENVERIFY), args, reply); err != nil {
+		return fmt.Errorf("failed to submit miden request: %w", err)
+	}
 	if reply.IsSubmitted {
 		// call the submit-verify endpoint with txID
-		vargs := VerifyRequestArgs{
-			TxID:       txID.String(),
-			VerifyType: MIDENVERIFY,
-		}
-		uri := endPointRequester.Uri + requester.VERIFYENDPOINT
-		vjsonData, err := json.Marshal(vargs)
-		if err != nil {
-			return fmt.Errorf("failed to marshal verify request args in HandleMiden: %w", err)
-		}
-		req, err := requester.NewRequest(uri, vjsonData)
-		if err != nil {
-			return fmt.Errorf("failed to create new verify request in HandleMiden: %w", err)
-		}
-		resp, err := cli.Do(req)
-		if err != nil {
-			return fmt.Errorf("failed to do verify request in HandleMiden: %w", err)
-		}
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("failed to read verify response body in HandleMiden: %w", err)
-		}
-		reply := new(VerifyReplyArgs)
-		err = json.Unmarshal(body, reply)
-		if err != nil {
-			return fmt.Errorf("failed to unmarshal verify reply in HandleMiden: %w", err)
-		}
+		return requestVerify(ctx, endPointRequester, txID, protocol.MIDENVERIFY)
 	}
-
 	return nil
 }
@@ -0,0 +1,51 @@
+package handle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/protocol"
+	"github.com/sausaging/hyper-pvzk/requester"
+)
+
+func HandleNoir(
+	ctx context.Context,
+	txID ids.ID,
+	imageID ids.ID,
+	flavor consts.NoirFlavor,
+	proofValType uint16,
+	verifyingKeyValType uint16,
+	publicInputsValType uint16,
+	store *artifacts.Store,
+	endPointRequester *requester.EndpointRequester,
+) error {
+	job, err := store.Describe(ctx, endPointRequester.Version(), imageID, map[string]uint16{
+		protocol.ArtifactProof:        proofValType,
+		protocol.ArtifactVerifyingKey: verifyingKeyValType,
+		protocol.ArtifactPublicInputs: publicInputsValType,
+	})
+	if err != nil {
+		return err
+	}
+	args := protocol.NoirJob{
+		TxID:                 txID.String(),
+		Flavor:               flavor.String(),
+		ProofFilePath:        job.Path(protocol.ArtifactProof),
+		VerifyingKeyFilePath: job.Path(protocol.ArtifactVerifyingKey),
+		PublicInputsFilePath: job.Path(protocol.ArtifactPublicInputs),
+		Artifacts:            job.Artifacts,
+	}
+
+	reply := new(protocol.SubmitReply)
+	if err := endPointRequester.Post(ctx, protocol.NOIRENDPOINT, idempotencyKey(txID, protocol.NOIRVERIFY), args, reply); err != nil {
+		return fmt.Errorf("failed to submit noir request: %w", err)
+	}
+	if reply.IsSubmitted {
+		// call the submit-verify endpoint with txID
+		return requestVerify(ctx, endPointRequester, txID, protocol.NOIRVERIFY)
+	}
+	return nil
+}
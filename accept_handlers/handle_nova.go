@@ -0,0 +1,52 @@
+package handle
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/protocol"
+	"github.com/sausaging/hyper-pvzk/requester"
+)
+
+func HandleNova(
+	ctx context.Context,
+	txID ids.ID,
+	imageID ids.ID,
+	instanceID ids.ID,
+	step uint64,
+	initialState []byte,
+	proofValType uint16,
+	verifyingKeyValType uint16,
+	store *artifacts.Store,
+	endPointRequester *requester.EndpointRequester,
+) error {
+	job, err := store.Describe(ctx, endPointRequester.Version(), imageID, map[string]uint16{
+		protocol.ArtifactProof:        proofValType,
+		protocol.ArtifactVerifyingKey: verifyingKeyValType,
+	})
+	if err != nil {
+		return err
+	}
+	args := protocol.NovaJob{
+		TxID:                 txID.String(),
+		InstanceID:           instanceID.String(),
+		Step:                 step,
+		InitialState:         hex.EncodeToString(initialState),
+		ProofFilePath:        job.Path(protocol.ArtifactProof),
+		VerifyingKeyFilePath: job.Path(protocol.ArtifactVerifyingKey),
+		Artifacts:            job.Artifacts,
+	}
+
+	reply := new(protocol.SubmitReply)
+	if err := endPointRequester.Post(ctx, protocol.NOVAENDPOINT, idempotencyKey(txID, protocol.NOVAVERIFY), args, reply); err != nil {
+		return fmt.Errorf("failed to submit nova request: %w", err)
+	}
+	if reply.IsSubmitted {
+		// call the submit-verify endpoint with txID
+		return requestVerify(ctx, endPointRequester, txID, protocol.NOVAVERIFY)
+	}
+	return nil
+}
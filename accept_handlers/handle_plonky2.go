@@ -1,101 +1,49 @@
 package handle
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/protocol"
 	"github.com/sausaging/hyper-pvzk/requester"
-	"github.com/sausaging/hyper-pvzk/storage"
 )
 
-type Plonky2RequestArgs struct {
-	TxID                 string `json:"tx_id"`
-	ProofFilePath        string `json:"proof_file_path"`
-	CommonDataFilePath   string `json:"common_data_file_path"`
-	VerifierDataFilePath string `json:"verifier_data_file_path"`
-}
-
-type Plonky2ReplyArgs struct {
-	IsSubmitted bool `json:"is_submitted"`
-}
-
 func HandlePlonky2(
+	ctx context.Context,
 	txID ids.ID,
 	imageID ids.ID,
 	proofValType uint16,
 	commonDataValType uint16,
 	verifierDataValType uint16,
-	baseDir string,
+	store *artifacts.Store,
 	endPointRequester *requester.EndpointRequester,
 ) error { //@todo send the hashes stored for every proofvaltype to rust server
-	commonDataKey := storage.DeployKey(imageID, commonDataValType)
-	verifierDataKey := storage.DeployKey(imageID, verifierDataValType)
-	proofKey := storage.DeployKey(imageID, proofValType)
-	commonDataFilePath := baseDir + "/" + commonDataKey
-	verifierDataFilePath := baseDir + "/" + verifierDataKey
-	proofFilePath := baseDir + "/" + proofKey
+	job, err := store.Describe(ctx, endPointRequester.Version(), imageID, map[string]uint16{
+		protocol.ArtifactProof:        proofValType,
+		protocol.ArtifactCommonData:   commonDataValType,
+		protocol.ArtifactVerifierData: verifierDataValType,
+	})
+	if err != nil {
+		return err
+	}
 	// call the plonky2 endpoint with elfFilePath, proofFilePath, txID
-	cli := endPointRequester.Cli
-	uri := endPointRequester.Uri + requester.PLONKY2ENDPOINT
-	args := Plonky2RequestArgs{
+	args := protocol.Plonky2Job{
 		TxID:                 txID.String(),
-		CommonDataFilePath:   commonDataFilePath,
-		VerifierDataFilePath: verifierDataFilePath,
-		ProofFilePath:        proofFilePath,
+		CommonDataFilePath:   job.Path(protocol.ArtifactCommonData),
+		VerifierDataFilePath: job.Path(protocol.ArtifactVerifierData),
+		ProofFilePath:        job.Path(protocol.ArtifactProof),
+		Artifacts:            job.Artifacts,
 	}
 
-	jsonData, err := json.Marshal(args)
-	if err != nil {
-		return fmt.Errorf("failed to marshal plonky2 request args: %w", err)
-	}
-	req, err := requester.NewRequest(uri, jsonData)
-	if err != nil {
-		return fmt.Errorf("failed to create new request in HandlePlonky2: %w", err)
-	}
-	resp, err := cli.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to do request in HandlePlonky2: %w", err)
-	}
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body in HandlePlonky2: %w", err)
-	}
-	reply := new(Plonky2ReplyArgs)
-	err = json.Unmarshal(body, reply)
-	if err != nil {
-		return fmt.Errorf("failed to unmarshal json in HandlePlonky2: %w", err)
+	reply := new(protocol.SubmitReply)
+	if err := endPointRequester.Post(ctx, protocol.PLONKY2ENDPOINT, idempotencyKey(txID, protocol.PLONKY2VERIFY), args, reply); err != nil {
+		return fmt.Errorf("failed to submit plonky2 request: %w", err)
 	}
-
 	if reply.IsSubmitted {
 		// call the submit-verify endpoint with txID
-		vargs := VerifyRequestArgs{
-			TxID:       txID.String(),
-			VerifyType: PLONKY2VERIFY,
-		}
-		uri := endPointRequester.Uri + requester.VERIFYENDPOINT
-		vjsonData, err := json.Marshal(vargs)
-		if err != nil {
-			return fmt.Errorf("failed to marshal verify request args in HandlePlonky2: %w", err)
-		}
-		req, err := requester.NewRequest(uri, vjsonData)
-		if err != nil {
-			return fmt.Errorf("failed to create new verify request in HandlePlonky2: %w", err)
-		}
-		resp, err := cli.Do(req)
-		if err != nil {
-			return fmt.Errorf("failed to do verify request in HandlePlonky2: %w", err)
-		}
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("failed to read verify response body in HandlePlonky2: %w", err)
-		}
-		reply := new(VerifyReplyArgs)
-		err = json.Unmarshal(body, reply)
-		if err != nil {
-			return fmt.Errorf("failed to unmarshal verify reply in HandlePlonky2: %w", err)
-		}
+		return requestVerify(ctx, endPointRequester, txID, protocol.PLONKY2VERIFY)
 	}
 	return nil
 }
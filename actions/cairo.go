@@ -0,0 +1,108 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*Cairo)(nil)
+
+// Cairo requests the verification of a Stone STARK proof of a Cairo program
+// execution. Verifiers check that the proof is of the program whose hash was
+// pinned when [ImageID] was registered, and report the hash of the program
+// output as the public outputs of the proof.
+type Cairo struct {
+	ImageID      ids.ID `json:"image_id"`
+	ProofValType uint64 `json:"proof_val_type"`
+	// ProgramHash is optional, see [ParseCairoProgramHash]. Requests naming
+	// another program hash than the pinned one are rejected.
+	ProgramHash   string `json:"program_hash"`
+	TimeOutBlocks uint64 `json:"time_out_blocks"`
+}
+
+func (*Cairo) GetTypeID() uint8 {
+	return mconsts.CairoID
+}
+
+func (c *Cairo) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
+	return requestStateKeys(actor, txID, c.artifacts())
+}
+
+func (c *Cairo) StateKeysMaxChunks() []uint16 {
+	return requestStateKeysMaxChunks(c.artifacts())
+}
+
+func (c *Cairo) artifacts() *requestArtifacts {
+	programHash, err := ParseCairoProgramHash(c.ProgramHash)
+	if err != nil {
+		// never matches the pinned program hash
+		programHash = []byte(c.ProgramHash)
+	}
+	return &requestArtifacts{
+		imageID:   c.ImageID,
+		valTypes:  []uint16{uint16(c.ProofValType)},
+		pinned:    mconsts.CairoID,
+		programID: programHash,
+	}
+}
+
+func (*Cairo) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*Cairo) MaxComputeUnits(chain.Rules) uint64 {
+	return CairoComputeUnits
+}
+
+func (c *Cairo) Size() int {
+	return consts.IDLen + consts.Uint64Len*2 + codec.StringLen(c.ProgramHash)
+}
+
+func (c *Cairo) Marshal(p *codec.Packer) {
+	p.PackID(c.ImageID)
+	p.PackUint64(c.ProofValType)
+	p.PackString(c.ProgramHash)
+	p.PackUint64(c.TimeOutBlocks)
+}
+
+func UnmarshalCairo(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var cairo Cairo
+	p.UnpackID(true, &cairo.ImageID)
+	cairo.ProofValType = p.UnpackUint64(true)
+	cairo.ProgramHash = p.UnpackString(false)
+	cairo.TimeOutBlocks = p.UnpackUint64(true)
+	return &cairo, nil
+}
+
+func (*Cairo) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+func (c *Cairo) Execute(
+	ctx context.Context,
+	rules chain.Rules,
+	mu state.Mutable,
+	ts int64,
+	actor codec.Address,
+	txID ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	if err := storage.StoreTimeOut(ctx, mu, txID, c.TimeOutBlocks, ts); err != nil {
+		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store time out", err)
+	}
+	if err := openRequest(ctx, rules, mu, ts, actor, txID, c.GetTypeID(), c.TimeOutBlocks, c.artifacts()); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	return true, CairoComputeUnits, nil, nil, nil
+}
@@ -0,0 +1,209 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*ClaimRewards)(nil)
+
+const MaxClaimRequests = 16
+
+type ClaimRewards struct {
+	// To receives every reward accrued by the validator.
+	To codec.Address `json:"to"`
+
+	// TxIDs are closed verification requests the validator voted on. Each one
+	// is settled against its outcome before the payout.
+	TxIDs []ids.ID `json:"tx_ids"`
+}
+
+func (*ClaimRewards) GetTypeID() uint8 {
+	return mconsts.ClaimRewardsID
+}
+
+func (c *ClaimRewards) StateKeys(actor codec.Address, _ ids.ID) state.Keys {
+	keys := state.Keys{
+		string(storage.ParticipationKey(actor)): state.All,
+		string(storage.BalanceKey(c.To)):        state.All,
+	}
+	for _, txID := range c.TxIDs {
+		keys.Add(string(storage.VoteKey(txID, actor)), state.All)
+		keys.Add(string(storage.StatusKey(txID)), state.Read)
+		keys.Add(string(storage.TimeOutKey(txID)), state.Read)
+		keys.Add(string(storage.TallyKey(txID)), state.Read)
+		keys.Add(string(storage.RewardPoolKey(txID)), state.All)
+		keys.Add(string(storage.CanaryKey(txID)), state.Read)
+	}
+	return keys
+}
+
+func (c *ClaimRewards) StateKeysMaxChunks() []uint16 {
+	chunks := []uint16{storage.ParticipationChunks, storage.BalanceChunks}
+	for range c.TxIDs {
+		chunks = append(chunks, storage.TimeOutChunks, storage.TimeOutChunks, storage.TimeOutChunks, storage.TallyChunks, storage.RewardPoolChunks, storage.CanaryChunks)
+	}
+	return chunks
+}
+
+func (*ClaimRewards) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*ClaimRewards) MaxComputeUnits(chain.Rules) uint64 {
+	return ClaimRewardsComputeUnits
+}
+
+func (c *ClaimRewards) Size() int {
+	return codec.AddressLen + consts.IntLen + len(c.TxIDs)*consts.IDLen
+}
+
+func (c *ClaimRewards) Marshal(p *codec.Packer) {
+	p.PackAddress(c.To)
+	p.PackInt(len(c.TxIDs))
+	for _, txID := range c.TxIDs {
+		p.PackID(txID)
+	}
+}
+
+func UnmarshalClaimRewards(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var claim ClaimRewards
+	p.UnpackAddress(&claim.To)
+	count := p.UnpackInt(false)
+	if count > MaxClaimRequests {
+		return nil, fmt.Errorf("%w: %d requests", ErrTooManyClaimRequests, count)
+	}
+	claim.TxIDs = make([]ids.ID, count)
+	for i := range claim.TxIDs {
+		p.UnpackID(true, &claim.TxIDs[i])
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+func (*ClaimRewards) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+func (c *ClaimRewards) Execute(
+	ctx context.Context,
+	rules chain.Rules,
+	mu state.Mutable,
+	ts int64,
+	actor codec.Address,
+	_ ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	if len(c.TxIDs) > MaxClaimRequests {
+		return false, 1000, utils.ErrBytes(ErrTooManyClaimRequests), nil, nil
+	}
+	p, exists, err := storage.GetParticipation(ctx, mu, actor)
+	if err != nil {
+		return false, 1000, nil, nil, fmt.Errorf("%w: unable to get participation", err)
+	}
+	if !exists {
+		return false, 1000, utils.ErrBytes(ErrNotParticipant), nil, nil
+	}
+	for _, txID := range c.TxIDs {
+		voted, outcome, settled, err := storage.GetVoteRecord(ctx, mu, txID, actor)
+		if err != nil {
+			return false, 2000, nil, nil, fmt.Errorf("%w: unable to get vote", err)
+		}
+		if !voted {
+			return false, 2000, utils.ErrBytes(fmt.Errorf("%w: %s", ErrNoVote, txID)), nil, nil
+		}
+		if settled {
+			return false, 2000, utils.ErrBytes(fmt.Errorf("%w: %s", ErrVoteSettled, txID)), nil, nil
+		}
+		valid, outputsDigest, err := storage.GetOutputs(ctx, mu, txID)
+		if err != nil {
+			return false, 2000, nil, nil, fmt.Errorf("%w: unable to get verify status", err)
+		}
+		timeOut, err := storage.GetTimeOut(ctx, mu, txID)
+		if err != nil {
+			return false, 2000, utils.ErrBytes(fmt.Errorf("%s: cant get timeout from storage", err)), nil, nil
+		}
+		// votes are only final, and their tally with them, once voting is
+		// over
+		if ts <= timeOut {
+			return false, 2000, utils.ErrBytes(fmt.Errorf("%w: %s", ErrVotingOpen, txID)), nil, nil
+		}
+		canary, err := storage.IsCanary(ctx, mu, txID)
+		if err != nil {
+			return false, 2000, nil, nil, fmt.Errorf("%w: unable to get canary", err)
+		}
+		// rewards are held back until the request can no longer be revealed
+		// as a canary
+		if end, ok := canaryRevealEnd(rules, timeOut); ok && !canary && ts <= end {
+			return false, 2000, utils.ErrBytes(fmt.Errorf("%w: %s", ErrCanaryRevealOpen, txID)), nil, nil
+		}
+		result := mconsts.OutcomeInvalid
+		// canary proofs are invalid, whatever the validators agreed on
+		if valid && !canary {
+			result = mconsts.OutcomeValid
+		}
+		agrees := outcome == result
+		if agrees && result == mconsts.OutcomeValid {
+			// valid votes only agree if they reported the outputs that
+			// reached quorum
+			voted, err := storage.GetVotedOutputs(ctx, mu, txID, actor)
+			if err != nil {
+				return false, 2000, nil, nil, fmt.Errorf("%w: unable to get voted outputs", err)
+			}
+			agrees = bytes.Equal(voted, outputsDigest)
+		}
+		if agrees {
+			tally, err := storage.GetTally(ctx, mu, txID)
+			if err != nil {
+				return false, 3000, nil, nil, fmt.Errorf("%w: unable to get tally", err)
+			}
+			// valid votes reporting other outputs count here too, their
+			// share stays in the pool
+			share, err := storage.PayFromRewardPool(ctx, mu, txID, tally[result])
+			if err != nil {
+				return false, 3000, nil, nil, fmt.Errorf("%w: unable to pay from reward pool", err)
+			}
+			p.VotesAgreeing++
+			p.Rewards, err = smath.Add64(p.Rewards, share)
+			if err != nil {
+				return false, 3000, utils.ErrBytes(err), nil, nil
+			}
+		}
+		if err := storage.SettleVote(ctx, mu, txID, actor); err != nil {
+			return false, 3000, nil, nil, fmt.Errorf("%w: unable to settle vote", err)
+		}
+	}
+	// canary penalties are taken from the rewards, and never paid out
+	withheld := min(p.Rewards, p.PenaltiesOwed)
+	p.Rewards -= withheld
+	p.PenaltiesOwed -= withheld
+	if p.Rewards > 0 {
+		if err := storage.AddBalance(ctx, mu, c.To, p.Rewards, true); err != nil {
+			return false, 4000, utils.ErrBytes(err), nil, nil
+		}
+		p.Claimed, err = smath.Add64(p.Claimed, p.Rewards)
+		if err != nil {
+			return false, 4000, utils.ErrBytes(err), nil, nil
+		}
+		p.Rewards = 0
+	}
+	if err := storage.SetParticipation(ctx, mu, actor, p); err != nil {
+		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store participation", err)
+	}
+	return true, ClaimRewardsComputeUnits, nil, nil, nil
+}
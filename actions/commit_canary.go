@@ -0,0 +1,113 @@
+package actions
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*CommitCanary)(nil)
+
+// CommitCanary lets the canary authority designate a request as a canary
+// before it is made, without revealing which one. See [CanaryCommitment].
+type CommitCanary struct {
+	Commitment []byte `json:"commitment"`
+}
+
+func (*CommitCanary) GetTypeID() uint8 {
+	return mconsts.CommitCanaryID
+}
+
+func (c *CommitCanary) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{
+		string(storage.CanaryCommitKey(c.Commitment)): state.All,
+	}
+}
+
+func (*CommitCanary) StateKeysMaxChunks() []uint16 {
+	return []uint16{storage.CanaryChunks}
+}
+
+func (*CommitCanary) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*CommitCanary) MaxComputeUnits(chain.Rules) uint64 {
+	return CommitCanaryComputeUnits
+}
+
+func (c *CommitCanary) Size() int {
+	return codec.BytesLen(c.Commitment)
+}
+
+func (c *CommitCanary) Marshal(p *codec.Packer) {
+	p.PackBytes(c.Commitment)
+}
+
+func UnmarshalCommitCanary(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var cc CommitCanary
+	p.UnpackBytes(sha256.Size, true, &cc.Commitment)
+	return &cc, p.Err()
+}
+
+func (*CommitCanary) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+func (c *CommitCanary) Execute(
+	ctx context.Context,
+	rules chain.Rules,
+	mu state.Mutable,
+	ts int64,
+	actor codec.Address,
+	_ ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	if err := checkCanaryAuthority(rules, actor); err != nil {
+		return false, 500, utils.ErrBytes(err), nil, nil
+	}
+	if len(c.Commitment) != sha256.Size {
+		return false, 500, utils.ErrBytes(fmt.Errorf("invalid commitment length: %d", len(c.Commitment))), nil, nil
+	}
+	_, exists, err := storage.GetCanaryCommitment(ctx, mu, c.Commitment)
+	if err != nil {
+		return false, 500, nil, nil, fmt.Errorf("%w: unable to get canary commitment", err)
+	}
+	if exists {
+		return false, 500, utils.ErrBytes(ErrCanaryCommitted), nil, nil
+	}
+	if err := storage.StoreCanaryCommitment(ctx, mu, c.Commitment, ts); err != nil {
+		return false, 1000, nil, nil, fmt.Errorf("%w: unable to store canary commitment", err)
+	}
+	return true, CommitCanaryComputeUnits, nil, nil, nil
+}
+
+// canaryRevealEnd returns when a request timing out at [timeOut] can no
+// longer be revealed as a canary, or false if canary requests are disabled.
+func canaryRevealEnd(rules chain.Rules, timeOut int64) (int64, bool) {
+	if _, ok := rules.FetchCustom(mconsts.CanaryAuthorityKey); !ok {
+		return 0, false
+	}
+	v, _ := rules.FetchCustom(mconsts.CanaryRevealKey)
+	return timeOut + int64(v.(uint64))*1000, true
+}
+
+func checkCanaryAuthority(rules chain.Rules, actor codec.Address) error {
+	v, ok := rules.FetchCustom(mconsts.CanaryAuthorityKey)
+	if !ok {
+		return ErrCanariesDisabled
+	}
+	if v.(codec.Address) != actor {
+		return ErrNotCanaryAuthority
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/crypto/bls"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*CommitVote)(nil)
+
+const MaxSaltLen = 32
+
+// CommitVote hides a validator's vote until the commit phase of a request is
+// over, so that votes can't be copied from the mempool. The vote is revealed
+// later with a [ValidatorVote] carrying the salt.
+type CommitVote struct {
+	TxID       ids.ID `json:"tx_id"`
+	Commitment []byte `json:"commitment"` // see [VoteCommitment]
+	Signature  []byte `json:"signature"`
+	PublicKey  []byte `json:"public_key"`
+}
+
+func (*CommitVote) GetTypeID() uint8 {
+	return mconsts.CommitVoteID
+}
+
+func (c *CommitVote) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{
+		string(storage.CommitDeadlineKey(c.TxID)):      state.Read,
+		string(storage.CommitKey(c.TxID, c.PublicKey)): state.All,
+	}
+}
+
+func (*CommitVote) StateKeysMaxChunks() []uint16 {
+	return []uint16{storage.TimeOutChunks, storage.CommitChunks}
+}
+
+func (*CommitVote) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*CommitVote) MaxComputeUnits(chain.Rules) uint64 {
+	return CommitVoteComputeUnits
+}
+
+func (c *CommitVote) Size() int {
+	return consts.IDLen + codec.BytesLen(c.Commitment) + codec.BytesLen(c.Signature) + codec.BytesLen(c.PublicKey)
+}
+
+func (c *CommitVote) Marshal(p *codec.Packer) {
+	p.PackID(c.TxID)
+	p.PackBytes(c.Commitment)
+	p.PackBytes(c.Signature)
+	p.PackBytes(c.PublicKey)
+}
+
+func UnmarshalCommitVote(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var cv CommitVote
+	p.UnpackID(true, &cv.TxID)
+	p.UnpackBytes(consts.IDLen, true, &cv.Commitment)
+	p.UnpackBytes(bls.SignatureLen, true, &cv.Signature)
+	p.UnpackBytes(bls.PublicKeyLen, true, &cv.PublicKey)
+	return &cv, p.Err()
+}
+
+func (*CommitVote) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+func (c *CommitVote) Execute(
+	ctx context.Context,
+	rules chain.Rules,
+	mu state.Mutable,
+	ts int64,
+	_ codec.Address,
+	_ ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	deadline, commitReveal, err := storage.GetCommitDeadline(ctx, mu, c.TxID)
+	if err != nil {
+		return false, 1000, nil, nil, fmt.Errorf("%w: unable to get commit deadline", err)
+	}
+	if !commitReveal {
+		return false, 1000, utils.ErrBytes(ErrNotCommitReveal), nil, nil
+	}
+	if ts > deadline {
+		return false, 1000, utils.ErrBytes(ErrCommitPhaseOver), nil, nil
+	}
+	commitment, err := storage.GetCommitment(ctx, mu, c.TxID, c.PublicKey)
+	if err != nil {
+		return false, 1000, nil, nil, fmt.Errorf("%w: unable to get commitment", err)
+	}
+	if commitment != nil {
+		return false, 1000, utils.ErrBytes(ErrAlreadyCommitted), nil, nil
+	}
+	if _, err := verifyValidatorSignature(ctx, rules, c.PublicKey, c.Signature, GetCommitMessage(c.TxID, c.Commitment)); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	if err := storage.StoreCommitment(ctx, mu, c.TxID, c.PublicKey, c.Commitment); err != nil {
+		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store commitment", err)
+	}
+	return true, CommitVoteComputeUnits, nil, nil, nil
+}
@@ -3,10 +3,21 @@
 
 package actions
 
+// ELFValType is the val type programs are deployed and registered under.
+const ELFValType uint16 = 1
+
+// InlineValType marks request inputs carried by the request itself rather
+// than registered as artifacts.
+const InlineValType uint64 = 0
+
 const TransferComputeUnits = 1
 const RegisterComputeUnits = 1000
 const RegisterImageComputeUnits = 4000
 const ValidatorVoteComputeUnits = 5000
+const ClaimRewardsComputeUnits = 4000
+const CommitVoteComputeUnits = 5000
+const CommitCanaryComputeUnits = 1000
+const RevealCanaryComputeUnits = 4000
 
 const SP1ComputeUnits = 8000
 const RiscZeroComputeUnits = 8000
@@ -14,3 +25,18 @@ const MidenComputeUnits = 6000
 const GnarkComputeUnits = 8000
 const JoltComputeUnits = 20_000
 const PLONKY2ComputeUnits = 8000
+const Halo2ComputeUnits = 8000
+const NoirComputeUnits = 8000
+const CairoComputeUnits = 8000
+const IVCStepComputeUnits = 8000
+const OpenIVCComputeUnits = 1000
+const RecordIVCStepComputeUnits = 1000
+
+// SegmentComputeUnits are charged for every segment of a segmented proof
+// beyond the first.
+const SegmentComputeUnits = 500
+
+// Groth16 proofs are verified during execution, at a cost growing with the
+// number of public inputs.
+const Groth16ComputeUnits = 10_000
+const Groth16PublicInputComputeUnits = 500
@@ -0,0 +1,49 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import "errors"
+
+var (
+	ErrTooManyClaimRequests  = errors.New("too many claim requests")
+	ErrNotParticipant        = errors.New("not a participating validator")
+	ErrNoVote                = errors.New("no vote on request")
+	ErrVoteSettled           = errors.New("vote already settled")
+	ErrVotingOpen            = errors.New("voting still open")
+	ErrNotCommitReveal       = errors.New("request doesn't use commit-reveal voting")
+	ErrCommitPhaseOver       = errors.New("commit phase is over")
+	ErrCommitPhaseOpen       = errors.New("commit phase is still open")
+	ErrAlreadyCommitted      = errors.New("already committed")
+	ErrNoCommitment          = errors.New("no commitment to reveal")
+	ErrCommitmentMismatch    = errors.New("revealed vote doesn't match commitment")
+	ErrCanariesDisabled      = errors.New("canary requests are disabled")
+	ErrNotCanaryAuthority    = errors.New("not the canary authority")
+	ErrCanaryCommitted       = errors.New("canary already committed")
+	ErrNoCanaryCommitment    = errors.New("no canary commitment before request")
+	ErrTooManyCanaryVoters   = errors.New("too many canary voters")
+	ErrCanaryRevealOpen      = errors.New("request can still be revealed as a canary")
+	ErrCanaryRevealOver      = errors.New("canary reveal window is over")
+	ErrUnknownOutcome        = errors.New("unknown vote outcome")
+	ErrArtifactNotRegistered = errors.New("artifact commitment not registered")
+	ErrArtifactRegistered    = errors.New("artifact already registered with another commitment")
+	ErrNotArtifactRegistrant = errors.New("artifact registered by another account")
+	ErrVoteBindingMismatch   = errors.New("vote doesn't match request")
+	ErrInlineAndArtifact     = errors.New("input both inline and registered as artifact")
+	ErrOutputsDigest         = errors.New("invalid outputs digest")
+	ErrProgramIDLength       = errors.New("invalid program id length")
+	ErrImageNotRegistered    = errors.New("image not registered")
+	ErrImageProvingSystem    = errors.New("image registered for another proving system")
+	ErrProgramIDMismatch     = errors.New("program id doesn't match the one pinned for the image")
+	ErrUnknownNoirFlavor     = errors.New("unknown noir flavor")
+	ErrIVCStateTooLarge      = errors.New("ivc initial state too large")
+	ErrIVCNotOpen            = errors.New("ivc instance not open")
+	ErrNotIVCOwner           = errors.New("not the owner of the ivc instance")
+	ErrIVCImageMismatch      = errors.New("image doesn't match ivc instance")
+	ErrStaleIVCStep          = errors.New("ivc step doesn't exceed the latest verified step")
+	ErrNotIVCStep            = errors.New("request isn't a step of the ivc instance")
+	ErrIVCStepNotVerified    = errors.New("ivc step not verified")
+	ErrNoStateCommitment     = errors.New("ivc step verified without state commitment")
+	ErrTooManySegments       = errors.New("too many proof segments")
+	ErrDuplicateSegment      = errors.New("proof segment registered under a val type already requested")
+)
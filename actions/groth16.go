@@ -0,0 +1,110 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/groth16"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*Groth16)(nil)
+
+// Groth16 verifies a circom Groth16 proof over BN254 during execution, with
+// no verifier round trip or vote. The snarkjs JSON files are carried by the
+// transaction, as every validator must be able to check them
+// deterministically. A valid proof marks the transaction verified with the
+// digest of its public inputs, see [groth16.Digest].
+type Groth16 struct {
+	// Proof, VerifyingKey and PublicInputs are the contents of the snarkjs
+	// proof.json, verification_key.json and public.json.
+	Proof        string `json:"proof"`
+	VerifyingKey string `json:"verifying_key"`
+	PublicInputs string `json:"public_inputs"`
+}
+
+func (*Groth16) GetTypeID() uint8 {
+	return mconsts.Groth16ID
+}
+
+func (*Groth16) StateKeys(_ codec.Address, txID ids.ID) state.Keys {
+	return state.Keys{
+		string(storage.StatusKey(txID)): state.All,
+	}
+}
+
+func (*Groth16) StateKeysMaxChunks() []uint16 {
+	return []uint16{storage.TimeOutChunks}
+}
+
+func (*Groth16) OutputsWarpMessage() bool {
+	return false
+}
+
+func (g *Groth16) MaxComputeUnits(chain.Rules) uint64 {
+	return g.computeUnits(groth16.CountPublicInputs([]byte(g.PublicInputs)))
+}
+
+func (*Groth16) computeUnits(publicInputs int) uint64 {
+	return Groth16ComputeUnits + Groth16PublicInputComputeUnits*uint64(publicInputs)
+}
+
+func (g *Groth16) Size() int {
+	return codec.StringLen(g.Proof) + codec.StringLen(g.VerifyingKey) + codec.StringLen(g.PublicInputs)
+}
+
+func (g *Groth16) Marshal(p *codec.Packer) {
+	p.PackString(g.Proof)
+	p.PackString(g.VerifyingKey)
+	p.PackString(g.PublicInputs)
+}
+
+func UnmarshalGroth16(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var g Groth16
+	g.Proof = p.UnpackString(true)
+	g.VerifyingKey = p.UnpackString(true)
+	g.PublicInputs = p.UnpackString(true)
+	return &g, nil
+}
+
+func (*Groth16) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+func (g *Groth16) Execute(
+	ctx context.Context,
+	_ chain.Rules,
+	mu state.Mutable,
+	_ int64,
+	_ codec.Address,
+	txID ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	vk, err := groth16.ParseVerifyingKey([]byte(g.VerifyingKey))
+	if err != nil {
+		return false, Groth16ComputeUnits, utils.ErrBytes(err), nil, nil
+	}
+	proof, err := groth16.ParseProof([]byte(g.Proof))
+	if err != nil {
+		return false, Groth16ComputeUnits, utils.ErrBytes(err), nil, nil
+	}
+	inputs, err := groth16.ParsePublicInputs([]byte(g.PublicInputs))
+	if err != nil {
+		return false, Groth16ComputeUnits, utils.ErrBytes(err), nil, nil
+	}
+	computeUnits := g.computeUnits(len(inputs))
+	if err := groth16.Verify(vk, proof, inputs); err != nil {
+		return false, computeUnits, utils.ErrBytes(err), nil, nil
+	}
+	if err := storage.StoreVerified(ctx, mu, txID, groth16.Digest(inputs)); err != nil {
+		return false, computeUnits, nil, nil, err
+	}
+	return true, computeUnits, nil, nil, nil
+}
@@ -0,0 +1,101 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*Halo2)(nil)
+
+// Halo2 requests the verification of a Halo2 proof with the KZG commitment
+// scheme. Verifiers check it against the verifying key and the public
+// instances of the circuit, with their own KZG parameters.
+type Halo2 struct {
+	ImageID             ids.ID `json:"image_id"`
+	ProofValType        uint64 `json:"proof_val_type"`
+	VerifyingKeyValType uint64 `json:"verifying_key_val_type"`
+	InstancesValType    uint64 `json:"instances_val_type"`
+	TimeOutBlocks       uint64 `json:"time_out_blocks"`
+}
+
+func (*Halo2) GetTypeID() uint8 {
+	return mconsts.Halo2ID
+}
+
+func (h *Halo2) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
+	return requestStateKeys(actor, txID, h.artifacts())
+}
+
+func (h *Halo2) StateKeysMaxChunks() []uint16 {
+	return requestStateKeysMaxChunks(h.artifacts())
+}
+
+func (h *Halo2) artifacts() *requestArtifacts {
+	return &requestArtifacts{
+		imageID:  h.ImageID,
+		valTypes: []uint16{uint16(h.ProofValType), uint16(h.VerifyingKeyValType), uint16(h.InstancesValType)},
+	}
+}
+
+func (*Halo2) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*Halo2) MaxComputeUnits(chain.Rules) uint64 {
+	return Halo2ComputeUnits
+}
+
+func (*Halo2) Size() int {
+	return consts.IDLen + consts.Uint64Len*4
+}
+
+func (h *Halo2) Marshal(p *codec.Packer) {
+	p.PackID(h.ImageID)
+	p.PackUint64(h.ProofValType)
+	p.PackUint64(h.VerifyingKeyValType)
+	p.PackUint64(h.InstancesValType)
+	p.PackUint64(h.TimeOutBlocks)
+}
+
+func UnmarshalHalo2(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var halo2 Halo2
+	p.UnpackID(true, &halo2.ImageID)
+	halo2.ProofValType = p.UnpackUint64(true)
+	halo2.VerifyingKeyValType = p.UnpackUint64(true)
+	halo2.InstancesValType = p.UnpackUint64(true)
+	halo2.TimeOutBlocks = p.UnpackUint64(true)
+	return &halo2, nil
+}
+
+func (*Halo2) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+func (h *Halo2) Execute(
+	ctx context.Context,
+	rules chain.Rules,
+	mu state.Mutable,
+	ts int64,
+	actor codec.Address,
+	txID ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	if err := storage.StoreTimeOut(ctx, mu, txID, h.TimeOutBlocks, ts); err != nil {
+		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store time out", err)
+	}
+	if err := openRequest(ctx, rules, mu, ts, actor, txID, h.GetTypeID(), h.TimeOutBlocks, h.artifacts()); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	return true, Halo2ComputeUnits, nil, nil, nil
+}
@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*IVCStep)(nil)
+
+// IVCStep requests the verification of a Nova proof that the computation of
+// IVC instance [InstanceID] ran [Step] steps from its initial state. Each
+// proof covers every step from the initial state, not the steps since the
+// latest recorded one.
+// Verifiers report the digest of the state the computation reached as the
+// public outputs of the proof. Once verified, [RecordIVCStep] records the
+// step and state commitment in the instance.
+//
+// Only the owner of the instance submits steps, and only steps beyond the
+// latest verified one.
+type IVCStep struct {
+	InstanceID ids.ID `json:"instance_id"`
+	// ImageID is the image the instance was opened over.
+	ImageID             ids.ID `json:"image_id"`
+	Step                uint64 `json:"step"`
+	ProofValType        uint64 `json:"proof_val_type"`
+	VerifyingKeyValType uint64 `json:"verifying_key_val_type"`
+	TimeOutBlocks       uint64 `json:"time_out_blocks"`
+}
+
+func (*IVCStep) GetTypeID() uint8 {
+	return mconsts.NovaID
+}
+
+func (s *IVCStep) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
+	keys := requestStateKeys(actor, txID, s.artifacts())
+	keys.Add(string(storage.IVCKey(s.InstanceID)), state.Read)
+	keys.Add(string(storage.IVCStepKey(txID)), state.All)
+	return keys
+}
+
+func (s *IVCStep) StateKeysMaxChunks() []uint16 {
+	return append(requestStateKeysMaxChunks(s.artifacts()), storage.IVCChunks, storage.IVCStepChunks)
+}
+
+func (s *IVCStep) artifacts() *requestArtifacts {
+	return &requestArtifacts{
+		imageID:  s.ImageID,
+		valTypes: []uint16{uint16(s.ProofValType), uint16(s.VerifyingKeyValType)},
+	}
+}
+
+func (*IVCStep) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*IVCStep) MaxComputeUnits(chain.Rules) uint64 {
+	return IVCStepComputeUnits
+}
+
+func (*IVCStep) Size() int {
+	return consts.IDLen*2 + consts.Uint64Len*4
+}
+
+func (s *IVCStep) Marshal(p *codec.Packer) {
+	p.PackID(s.InstanceID)
+	p.PackID(s.ImageID)
+	p.PackUint64(s.Step)
+	p.PackUint64(s.ProofValType)
+	p.PackUint64(s.VerifyingKeyValType)
+	p.PackUint64(s.TimeOutBlocks)
+}
+
+func UnmarshalIVCStep(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var step IVCStep
+	p.UnpackID(true, &step.InstanceID)
+	p.UnpackID(true, &step.ImageID)
+	step.Step = p.UnpackUint64(true)
+	step.ProofValType = p.UnpackUint64(true)
+	step.VerifyingKeyValType = p.UnpackUint64(true)
+	step.TimeOutBlocks = p.UnpackUint64(true)
+	return &step, nil
+}
+
+func (*IVCStep) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+func (s *IVCStep) Execute(
+	ctx context.Context,
+	rules chain.Rules,
+	mu state.Mutable,
+	ts int64,
+	actor codec.Address,
+	txID ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	ivc, err := storage.GetIVC(ctx, mu, s.InstanceID)
+	if errors.Is(err, database.ErrNotFound) {
+		return false, 4000, utils.ErrBytes(fmt.Errorf("%w: %s", ErrIVCNotOpen, s.InstanceID)), nil, nil
+	}
+	if err != nil {
+		return false, 4000, nil, nil, err
+	}
+	if ivc.Owner != actor {
+		return false, 4000, utils.ErrBytes(ErrNotIVCOwner), nil, nil
+	}
+	if ivc.ImageID != s.ImageID {
+		return false, 4000, utils.ErrBytes(fmt.Errorf("%w: %s, opened over %s", ErrIVCImageMismatch, s.ImageID, ivc.ImageID)), nil, nil
+	}
+	if s.Step <= ivc.Step {
+		return false, 4000, utils.ErrBytes(fmt.Errorf("%w: %d, latest %d", ErrStaleIVCStep, s.Step, ivc.Step)), nil, nil
+	}
+	if err := storage.StoreTimeOut(ctx, mu, txID, s.TimeOutBlocks, ts); err != nil {
+		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store time out", err)
+	}
+	if err := openRequest(ctx, rules, mu, ts, actor, txID, s.GetTypeID(), s.TimeOutBlocks, s.artifacts()); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	if err := storage.StoreIVCStep(ctx, mu, txID, s.InstanceID, s.Step); err != nil {
+		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store ivc step", err)
+	}
+	return true, IVCStepComputeUnits, nil, nil, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/consts"
 	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
 )
 
 var _ chain.Action = (*Jolt)(nil)
@@ -27,11 +28,18 @@ func (*Jolt) GetTypeID() uint8 {
 }
 
 func (j *Jolt) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
-	return state.Keys{string(storage.TimeOutKey(txID)): state.All}
+	return requestStateKeys(actor, txID, j.artifacts())
 }
 
-func (*Jolt) StateKeysMaxChunks() []uint16 {
-	return []uint16{storage.TimeOutChunks}
+func (j *Jolt) StateKeysMaxChunks() []uint16 {
+	return requestStateKeysMaxChunks(j.artifacts())
+}
+
+func (j *Jolt) artifacts() *requestArtifacts {
+	return &requestArtifacts{
+		imageID:  j.ImageID,
+		valTypes: []uint16{ELFValType, uint16(j.ProofValType)},
+	}
 }
 
 func (*Jolt) OutputsWarpMessage() bool {
@@ -77,5 +85,8 @@ func (j *Jolt) Execute(
 	if err := storage.StoreTimeOut(ctx, mu, txID, j.TimeOutBlocks, ts); err != nil {
 		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store time out", err)
 	}
+	if err := openRequest(ctx, rules, mu, ts, actor, txID, j.GetTypeID(), j.TimeOutBlocks, j.artifacts()); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
 	return true, 8000, nil, nil, nil
 }
@@ -12,42 +12,66 @@ import (
 	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/consts"
 	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
 )
 
 var _ chain.Action = (*Miden)(nil)
 
+// Miden requests carry the program, its inputs and its outputs either inline,
+// for small programs, or as artifacts registered under the image. A val type
+// of [InlineValType] means the matching front end is carried inline.
 type Miden struct {
-	ImageID      ids.ID `json:"image_id"`
-	ProofValType uint64 `json:"proof_val_type"`
-	//@todo future support: large code, inputs, outputs support
+	ImageID        ids.ID `json:"image_id"`
+	ProofValType   uint64 `json:"proof_val_type"`
+	CodeValType    uint64 `json:"code_val_type"`
+	InputsValType  uint64 `json:"inputs_val_type"`
+	OutputsValType uint64 `json:"outputs_val_type"`
+
 	CodeFrontEnd    string `json:"code_front_end"`
 	InputsFrontEnd  string `json:"inputs_front_end"`
 	OutputsFrontEnd string `json:"outputs_front_end"`
 	TimeOutBlocks   uint64 `json:"time_out_blocks"`
 }
 
-type MidenRequestArgs struct {
-	CodeFrontEnd    string `json:"code_front_end"`
-	InputsFrontEnd  string `json:"inputs_front_end"`
-	OutputsFrontEnd string `json:"outputs_front_end"`
-	ProofFilePath   string `json:"proof_file_path"`
+func (*Miden) GetTypeID() uint8 {
+	return mconsts.MidenID
 }
 
-type MidenReplyArgs struct {
-	//@todo intro security field
-	IsValid bool `json:"is_valid"`
+func (m *Miden) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
+	return requestStateKeys(actor, txID, m.artifacts())
 }
 
-func (*Miden) GetTypeID() uint8 {
-	return mconsts.MidenID
+func (m *Miden) StateKeysMaxChunks() []uint16 {
+	return requestStateKeysMaxChunks(m.artifacts())
 }
 
-func (m *Miden) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
-	return state.Keys{string(storage.TimeOutKey(txID)): state.All}
+func (m *Miden) artifacts() *requestArtifacts {
+	a := &requestArtifacts{
+		imageID:  m.ImageID,
+		valTypes: []uint16{uint16(m.ProofValType)},
+	}
+	for _, input := range m.inputs() {
+		if input.valType == InlineValType {
+			a.inline = append(a.inline, []byte(input.frontEnd))
+		} else {
+			a.valTypes = append(a.valTypes, uint16(input.valType))
+		}
+	}
+	return a
 }
 
-func (*Miden) StateKeysMaxChunks() []uint16 {
-	return []uint16{storage.TimeOutChunks}
+type midenInput struct {
+	valType  uint64
+	frontEnd string
+}
+
+// inputs are the program, inputs and outputs of the request, in that order.
+func (m *Miden) inputs() []midenInput {
+	return []midenInput{
+		{m.CodeValType, m.CodeFrontEnd},
+		{m.InputsValType, m.InputsFrontEnd},
+		{m.OutputsValType, m.OutputsFrontEnd},
+	}
 }
 
 func (*Miden) OutputsWarpMessage() bool {
@@ -59,12 +83,15 @@ func (*Miden) MaxComputeUnits(chain.Rules) uint64 {
 }
 
 func (m *Miden) Size() int {
-	return consts.IDLen + consts.Uint64Len*2 + len(m.CodeFrontEnd) + len(m.InputsFrontEnd) + len(m.OutputsFrontEnd)
+	return consts.IDLen + consts.Uint64Len*5 + codec.StringLen(m.CodeFrontEnd) + codec.StringLen(m.InputsFrontEnd) + codec.StringLen(m.OutputsFrontEnd)
 }
 
 func (m *Miden) Marshal(p *codec.Packer) {
 	p.PackID(m.ImageID)
 	p.PackUint64(m.ProofValType)
+	p.PackUint64(m.CodeValType)
+	p.PackUint64(m.InputsValType)
+	p.PackUint64(m.OutputsValType)
 	p.PackString(m.CodeFrontEnd)
 	p.PackString(m.InputsFrontEnd)
 	p.PackString(m.OutputsFrontEnd)
@@ -75,9 +102,12 @@ func UnmarshalMiden(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
 	var miden Miden
 	p.UnpackID(true, &miden.ImageID)
 	miden.ProofValType = p.UnpackUint64(true)
-	miden.CodeFrontEnd = p.UnpackString(true)
-	miden.InputsFrontEnd = p.UnpackString(true)
-	miden.OutputsFrontEnd = p.UnpackString(true)
+	miden.CodeValType = p.UnpackUint64(false)
+	miden.InputsValType = p.UnpackUint64(false)
+	miden.OutputsValType = p.UnpackUint64(false)
+	miden.CodeFrontEnd = p.UnpackString(miden.CodeValType == InlineValType)
+	miden.InputsFrontEnd = p.UnpackString(miden.InputsValType == InlineValType)
+	miden.OutputsFrontEnd = p.UnpackString(miden.OutputsValType == InlineValType)
 	miden.TimeOutBlocks = p.UnpackUint64(true)
 	return &miden, nil
 }
@@ -96,10 +126,17 @@ func (m *Miden) Execute(
 	txID ids.ID,
 	_ bool,
 ) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
-
+	for _, input := range m.inputs() {
+		if input.valType != InlineValType && len(input.frontEnd) > 0 {
+			return false, 4000, utils.ErrBytes(fmt.Errorf("%w: val type %d", ErrInlineAndArtifact, input.valType)), nil, nil
+		}
+	}
 	if err := storage.StoreTimeOut(ctx, mu, txID, m.TimeOutBlocks, ts); err != nil {
 		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store time out", err)
 	}
+	if err := openRequest(ctx, rules, mu, ts, actor, txID, m.GetTypeID(), m.TimeOutBlocks, m.artifacts()); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
 
 	return true, 6000, nil, nil, nil
 }
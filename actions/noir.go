@@ -0,0 +1,107 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*Noir)(nil)
+
+// Noir requests the verification of a proof of a Noir circuit produced by
+// Barretenberg. Verifiers check it against the verification key and public
+// inputs of the circuit with the verifier of its [mconsts.NoirFlavor].
+type Noir struct {
+	ImageID             ids.ID `json:"image_id"`
+	Flavor              uint8  `json:"flavor"`
+	ProofValType        uint64 `json:"proof_val_type"`
+	VerifyingKeyValType uint64 `json:"verifying_key_val_type"`
+	PublicInputsValType uint64 `json:"public_inputs_val_type"`
+	TimeOutBlocks       uint64 `json:"time_out_blocks"`
+}
+
+func (*Noir) GetTypeID() uint8 {
+	return mconsts.NoirID
+}
+
+func (n *Noir) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
+	return requestStateKeys(actor, txID, n.artifacts())
+}
+
+func (n *Noir) StateKeysMaxChunks() []uint16 {
+	return requestStateKeysMaxChunks(n.artifacts())
+}
+
+func (n *Noir) artifacts() *requestArtifacts {
+	return &requestArtifacts{
+		imageID:  n.ImageID,
+		valTypes: []uint16{uint16(n.ProofValType), uint16(n.VerifyingKeyValType), uint16(n.PublicInputsValType)},
+	}
+}
+
+func (*Noir) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*Noir) MaxComputeUnits(chain.Rules) uint64 {
+	return NoirComputeUnits
+}
+
+func (*Noir) Size() int {
+	return consts.IDLen + consts.ByteLen + consts.Uint64Len*4
+}
+
+func (n *Noir) Marshal(p *codec.Packer) {
+	p.PackID(n.ImageID)
+	p.PackByte(n.Flavor)
+	p.PackUint64(n.ProofValType)
+	p.PackUint64(n.VerifyingKeyValType)
+	p.PackUint64(n.PublicInputsValType)
+	p.PackUint64(n.TimeOutBlocks)
+}
+
+func UnmarshalNoir(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var noir Noir
+	p.UnpackID(true, &noir.ImageID)
+	noir.Flavor = p.UnpackByte()
+	noir.ProofValType = p.UnpackUint64(true)
+	noir.VerifyingKeyValType = p.UnpackUint64(true)
+	noir.PublicInputsValType = p.UnpackUint64(true)
+	noir.TimeOutBlocks = p.UnpackUint64(true)
+	return &noir, nil
+}
+
+func (*Noir) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+func (n *Noir) Execute(
+	ctx context.Context,
+	rules chain.Rules,
+	mu state.Mutable,
+	ts int64,
+	actor codec.Address,
+	txID ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	if !mconsts.NoirFlavor(n.Flavor).Known() {
+		return false, 4000, utils.ErrBytes(fmt.Errorf("%w: %d", ErrUnknownNoirFlavor, n.Flavor)), nil, nil
+	}
+	if err := storage.StoreTimeOut(ctx, mu, txID, n.TimeOutBlocks, ts); err != nil {
+		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store time out", err)
+	}
+	if err := openRequest(ctx, rules, mu, ts, actor, txID, n.GetTypeID(), n.TimeOutBlocks, n.artifacts()); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	return true, NoirComputeUnits, nil, nil, nil
+}
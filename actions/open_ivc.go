@@ -0,0 +1,106 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*OpenIVC)(nil)
+
+// OpenIVC opens an incrementally verifiable computation over a Nova image,
+// identified by the ID of the transaction. Its owner then submits proofs of
+// ever more steps with [IVCStep].
+type OpenIVC struct {
+	ImageID ids.ID `json:"image_id"`
+	// InitialState is the serialized initial state z0 of the computation,
+	// the proofs of every step start from.
+	InitialState []byte `json:"initial_state"`
+}
+
+func (*OpenIVC) GetTypeID() uint8 {
+	return mconsts.OpenIVCID
+}
+
+func (o *OpenIVC) StateKeys(_ codec.Address, txID ids.ID) state.Keys {
+	return state.Keys{
+		string(storage.ImageKey(o.ImageID)): state.Read,
+		string(storage.IVCKey(txID)):        state.All,
+	}
+}
+
+func (*OpenIVC) StateKeysMaxChunks() []uint16 {
+	return []uint16{storage.ImageChunks, storage.IVCChunks}
+}
+
+func (*OpenIVC) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*OpenIVC) MaxComputeUnits(chain.Rules) uint64 {
+	return OpenIVCComputeUnits
+}
+
+func (o *OpenIVC) Size() int {
+	return consts.IDLen + codec.BytesLen(o.InitialState)
+}
+
+func (o *OpenIVC) Marshal(p *codec.Packer) {
+	p.PackID(o.ImageID)
+	p.PackBytes(o.InitialState)
+}
+
+func UnmarshalOpenIVC(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var openIVC OpenIVC
+	p.UnpackID(true, &openIVC.ImageID)
+	p.UnpackBytes(storage.MaxIVCInitialStateLen, false, &openIVC.InitialState)
+	return &openIVC, nil
+}
+
+func (*OpenIVC) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+func (o *OpenIVC) Execute(
+	ctx context.Context,
+	_ chain.Rules,
+	mu state.Mutable,
+	_ int64,
+	actor codec.Address,
+	txID ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	if len(o.InitialState) > storage.MaxIVCInitialStateLen {
+		return false, OpenIVCComputeUnits, utils.ErrBytes(fmt.Errorf("%w: %d bytes", ErrIVCStateTooLarge, len(o.InitialState))), nil, nil
+	}
+	image, err := storage.GetImage(ctx, mu, o.ImageID)
+	if errors.Is(err, database.ErrNotFound) {
+		return false, OpenIVCComputeUnits, utils.ErrBytes(fmt.Errorf("%w: %s", ErrImageNotRegistered, o.ImageID)), nil, nil
+	}
+	if err != nil {
+		return false, OpenIVCComputeUnits, nil, nil, err
+	}
+	if image.ProvingSystem != uint64(mconsts.NovaID) {
+		return false, OpenIVCComputeUnits, utils.ErrBytes(fmt.Errorf("%w: %d, expected %d", ErrImageProvingSystem, image.ProvingSystem, mconsts.NovaID)), nil, nil
+	}
+	if err := storage.StoreIVC(ctx, mu, txID, &storage.IVC{
+		ImageID:      o.ImageID,
+		Owner:        actor,
+		InitialState: o.InitialState,
+	}); err != nil {
+		return false, OpenIVCComputeUnits, nil, nil, fmt.Errorf("%w: unable to store ivc", err)
+	}
+	return true, OpenIVCComputeUnits, nil, nil, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/consts"
 	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
 )
 
 var _ chain.Action = (*PLONKY2)(nil)
@@ -29,11 +30,18 @@ func (*PLONKY2) GetTypeID() uint8 {
 }
 
 func (s *PLONKY2) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
-	return state.Keys{string(storage.TimeOutKey(txID)): state.All}
+	return requestStateKeys(actor, txID, s.artifacts())
 }
 
-func (*PLONKY2) StateKeysMaxChunks() []uint16 {
-	return []uint16{storage.TimeOutChunks}
+func (s *PLONKY2) StateKeysMaxChunks() []uint16 {
+	return requestStateKeysMaxChunks(s.artifacts())
+}
+
+func (s *PLONKY2) artifacts() *requestArtifacts {
+	return &requestArtifacts{
+		imageID:  s.ImageID,
+		valTypes: []uint16{uint16(s.ProofValType), uint16(s.CommonDataValType), uint16(s.VerifierDataValType)},
+	}
 }
 
 func (*PLONKY2) OutputsWarpMessage() bool {
@@ -83,5 +91,8 @@ func (s *PLONKY2) Execute(
 	if err := storage.StoreTimeOut(ctx, mu, txID, s.TimeOutBlocks, ts); err != nil {
 		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store time out", err)
 	}
+	if err := openRequest(ctx, rules, mu, ts, actor, txID, s.GetTypeID(), s.TimeOutBlocks, s.artifacts()); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
 	return true, 8000, nil, nil, nil
 }
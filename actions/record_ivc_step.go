@@ -0,0 +1,120 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*RecordIVCStep)(nil)
+
+// RecordIVCStep records the step verified by [IVCStep] request [TxID] as
+// the latest step of IVC instance [InstanceID], with the state commitment
+// validators agreed on. Anyone may record a verified step, as long as it is
+// beyond the one recorded: steps verified out of order are never recorded.
+//
+// Steps aren't chained: every step is proven from the initial state of the
+// instance, so the recorded commitment attests the computation from there,
+// not that it continues from the commitment recorded before it. Computations
+// taking nondeterministic inputs may record steps of different runs.
+type RecordIVCStep struct {
+	InstanceID ids.ID `json:"instance_id"`
+	TxID       ids.ID `json:"tx_id"`
+}
+
+func (*RecordIVCStep) GetTypeID() uint8 {
+	return mconsts.RecordIVCStepID
+}
+
+func (r *RecordIVCStep) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{
+		string(storage.IVCKey(r.InstanceID)): state.All,
+		string(storage.IVCStepKey(r.TxID)):   state.Read,
+		string(storage.StatusKey(r.TxID)):    state.Read,
+	}
+}
+
+func (*RecordIVCStep) StateKeysMaxChunks() []uint16 {
+	return []uint16{storage.IVCChunks, storage.IVCStepChunks, storage.TimeOutChunks}
+}
+
+func (*RecordIVCStep) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*RecordIVCStep) MaxComputeUnits(chain.Rules) uint64 {
+	return RecordIVCStepComputeUnits
+}
+
+func (*RecordIVCStep) Size() int {
+	return consts.IDLen * 2
+}
+
+func (r *RecordIVCStep) Marshal(p *codec.Packer) {
+	p.PackID(r.InstanceID)
+	p.PackID(r.TxID)
+}
+
+func UnmarshalRecordIVCStep(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var record RecordIVCStep
+	p.UnpackID(true, &record.InstanceID)
+	p.UnpackID(true, &record.TxID)
+	return &record, nil
+}
+
+func (*RecordIVCStep) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+func (r *RecordIVCStep) Execute(
+	ctx context.Context,
+	_ chain.Rules,
+	mu state.Mutable,
+	_ int64,
+	_ codec.Address,
+	_ ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	instanceID, step, err := storage.GetIVCStep(ctx, mu, r.TxID)
+	if errors.Is(err, database.ErrNotFound) || (err == nil && instanceID != r.InstanceID) {
+		return false, RecordIVCStepComputeUnits, utils.ErrBytes(fmt.Errorf("%w: %s", ErrNotIVCStep, r.TxID)), nil, nil
+	}
+	if err != nil {
+		return false, RecordIVCStepComputeUnits, nil, nil, err
+	}
+	verified, stateCommitment, err := storage.GetOutputs(ctx, mu, r.TxID)
+	if err != nil {
+		return false, RecordIVCStepComputeUnits, nil, nil, err
+	}
+	if !verified {
+		return false, RecordIVCStepComputeUnits, utils.ErrBytes(fmt.Errorf("%w: %s", ErrIVCStepNotVerified, r.TxID)), nil, nil
+	}
+	if len(stateCommitment) == 0 {
+		return false, RecordIVCStepComputeUnits, utils.ErrBytes(ErrNoStateCommitment), nil, nil
+	}
+	ivc, err := storage.GetIVC(ctx, mu, r.InstanceID)
+	if err != nil {
+		return false, RecordIVCStepComputeUnits, nil, nil, err
+	}
+	if step <= ivc.Step {
+		return false, RecordIVCStepComputeUnits, utils.ErrBytes(fmt.Errorf("%w: %d, latest %d", ErrStaleIVCStep, step, ivc.Step)), nil, nil
+	}
+	ivc.Step = step
+	ivc.StateCommitment = stateCommitment
+	if err := storage.StoreIVC(ctx, mu, r.InstanceID, ivc); err != nil {
+		return false, RecordIVCStepComputeUnits, nil, nil, fmt.Errorf("%w: unable to store ivc", err)
+	}
+	return true, RecordIVCStepComputeUnits, nil, nil, nil
+}
@@ -2,32 +2,46 @@ package actions
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
 	"github.com/sausaging/hypersdk/chain"
 	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/consts"
 	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
 )
 
 var _ chain.Action = (*Register)(nil)
 
+// ProgramIDLen is the length of the program IDs pinned by [Register].
+const ProgramIDLen = 32
+
+// Register registers a new image, identified by the ID of the transaction.
 type Register struct {
 	ProovingSystem uint64 `json:"prooving_system"`
+	// ProgramID pins the program proofs for the image are generated for:
+	// the RISC Zero image ID of the guest, or the SP1 verifying key hash. It
+	// is required by those proving systems, and sent to their verifiers in
+	// place of anything a request carries.
+	ProgramID []byte `json:"program_id"`
 }
 
 func (*Register) GetTypeID() uint8 {
 	return mconsts.RegisterID
 }
 
-func (*Register) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
-	return state.Keys{}
+func (*Register) StateKeys(_ codec.Address, txID ids.ID) state.Keys {
+	return state.Keys{
+		string(storage.ImageKey(txID)): state.All,
+	}
 }
 
 func (*Register) StateKeysMaxChunks() []uint16 {
-	return []uint16{}
+	return []uint16{storage.ImageChunks}
 }
 
 func (*Register) OutputsWarpMessage() bool {
@@ -38,17 +52,19 @@ func (*Register) MaxComputeUnits(chain.Rules) uint64 {
 	return RegisterComputeUnits
 }
 
-func (*Register) Size() int {
-	return consts.Uint64Len
+func (r *Register) Size() int {
+	return consts.Uint64Len + codec.BytesLen(r.ProgramID)
 }
 
 func (r *Register) Marshal(p *codec.Packer) {
 	p.PackUint64(r.ProovingSystem)
+	p.PackBytes(r.ProgramID)
 }
 
 func UnmarshalRegister(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
 	var register Register
 	register.ProovingSystem = p.UnpackUint64(true)
+	p.UnpackBytes(ProgramIDLen, false, &register.ProgramID)
 	return &register, nil
 }
 
@@ -64,10 +80,39 @@ func (r *Register) Execute(
 	txID ids.ID,
 	_ bool,
 ) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
-	// @todo should we store what type of proof system is meant to be used by the imageID(i.e. txID) generated??
+	if err := checkProgramID(r.ProovingSystem, r.ProgramID); err != nil {
+		return false, RegisterComputeUnits, utils.ErrBytes(err), nil, nil
+	}
+	if err := storage.StoreImage(ctx, mu, txID, &storage.Image{
+		ProvingSystem: r.ProovingSystem,
+		ProgramID:     r.ProgramID,
+	}); err != nil {
+		return false, RegisterComputeUnits, nil, nil, fmt.Errorf("%w: unable to store image", err)
+	}
 	return true, RegisterComputeUnits, nil, nil, nil
 }
 
+// pinsProgram returns true if images of [provingSystem] pin their program.
+func pinsProgram(provingSystem uint64) bool {
+	switch provingSystem {
+	case uint64(mconsts.SP1ID), uint64(mconsts.RiscZeroID), uint64(mconsts.CairoID):
+		return true
+	default:
+		return false
+	}
+}
+
+func checkProgramID(provingSystem uint64, programID []byte) error {
+	switch {
+	case pinsProgram(provingSystem) && len(programID) != ProgramIDLen:
+		return fmt.Errorf("%w: expected %d bytes, found %d", ErrProgramIDLength, ProgramIDLen, len(programID))
+	case !pinsProgram(provingSystem) && len(programID) > 0:
+		return fmt.Errorf("%w: proving system %d doesn't pin programs", ErrProgramIDLength, provingSystem)
+	default:
+		return nil
+	}
+}
+
 type Broadcast struct {
 	ImageID ids.ID `json:"image_id"`
 	ValType uint64 `json:"val_type"`
@@ -1,24 +1,39 @@
 package actions
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/sausaging/hyper-pvzk/commitment"
 	mconsts "github.com/sausaging/hyper-pvzk/consts"
 	"github.com/sausaging/hyper-pvzk/storage"
 	"github.com/sausaging/hypersdk/chain"
 	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/consts"
 	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
 )
 
 var _ chain.Action = (*RegisterImage)(nil)
 
 type RegisterImage struct {
-	ImageID  ids.ID `json:"image_id"`
-	ValType  uint64 `json:"val_type"`  // 1 for elf, 1 + for proofs
-	RootHash string `json:"root_hash"` // root hash of the image --> which hash function should be used?
+	ImageID ids.ID `json:"image_id"`
+	ValType uint64 `json:"val_type"` // 1 for elf, 1 + for proofs
+	// Algorithm and Digest commit to the artifact, see [commitment.Algorithm]
+	// for the supported algorithms.
+	Algorithm uint8  `json:"algorithm"`
+	Digest    []byte `json:"digest"`
+	// ArtifactSize is the size in bytes of the artifact. The registrant pays
+	// storage fees for it, and nodes refuse chunks beyond it. Only the
+	// registrant can register the artifact again, with the same commitment,
+	// to pay for a larger size.
+	ArtifactSize uint64 `json:"artifact_size"`
 }
 
 func (*RegisterImage) GetTypeID() uint8 {
@@ -28,11 +43,12 @@ func (*RegisterImage) GetTypeID() uint8 {
 func (r *RegisterImage) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
 	return state.Keys{
 		string(storage.HashKey(r.ImageID, uint16(r.ValType))): state.All,
+		string(storage.BalanceKey(actor)):                     state.All,
 	}
 }
 
 func (*RegisterImage) StateKeysMaxChunks() []uint16 {
-	return []uint16{storage.HashChunksMax}
+	return []uint16{storage.HashChunksMax, storage.BalanceChunks}
 }
 
 func (*RegisterImage) OutputsWarpMessage() bool {
@@ -44,21 +60,25 @@ func (*RegisterImage) MaxComputeUnits(chain.Rules) uint64 {
 }
 
 func (r *RegisterImage) Size() int {
-	return consts.IDLen + consts.Uint64Len + len(r.RootHash)
+	return consts.IDLen + consts.Uint64Len + consts.ByteLen + codec.BytesLen(r.Digest) + consts.Uint64Len
 }
 
 func (r *RegisterImage) Marshal(p *codec.Packer) {
 	p.PackID(r.ImageID)
 	p.PackUint64(r.ValType)
-	p.PackString(r.RootHash)
+	p.PackByte(r.Algorithm)
+	p.PackBytes(r.Digest)
+	p.PackUint64(r.ArtifactSize)
 }
 
 func UnmarshalRegisterImage(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
 	var registerImage RegisterImage
 	p.UnpackID(true, &registerImage.ImageID)
 	registerImage.ValType = p.UnpackUint64(true)
-	registerImage.RootHash = p.UnpackString(true)
-	return &registerImage, nil
+	registerImage.Algorithm = p.UnpackByte()
+	p.UnpackBytes(commitment.MaxDigestLen, true, &registerImage.Digest)
+	registerImage.ArtifactSize = p.UnpackUint64(true)
+	return &registerImage, p.Err()
 }
 
 func (*RegisterImage) ValidRange(chain.Rules) (int64, int64) {
@@ -68,7 +88,7 @@ func (*RegisterImage) ValidRange(chain.Rules) (int64, int64) {
 
 func (r *RegisterImage) Execute(
 	ctx context.Context,
-	_ chain.Rules,
+	rules chain.Rules,
 	mu state.Mutable,
 	_ int64,
 	actor codec.Address,
@@ -77,9 +97,51 @@ func (r *RegisterImage) Execute(
 ) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
 	imageID := r.ImageID
 	valType := uint16(r.ValType)
-	rootHash := r.RootHash
-	if err := storage.StoreHashKeyType(ctx, mu, imageID, valType, []byte(rootHash)); err != nil {
+	c, err := commitment.New(commitment.Algorithm(r.Algorithm), r.Digest)
+	if err != nil {
+		return false, RegisterImageComputeUnits, utils.ErrBytes(err), nil, nil
+	}
+	// registrations are never overwritten, so that open requests keep
+	// referring to the artifact they were made for
+	size := r.ArtifactSize
+	registered, paid, err := storage.GetHashKeyType(ctx, mu, imageID, valType)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		paid = 0
+	case err != nil:
+		return false, RegisterImageComputeUnits, nil, nil, err
+	default:
+		registrant, err := storage.GetArtifactRegistrant(ctx, mu, imageID, valType)
+		if err != nil {
+			return false, RegisterImageComputeUnits, nil, nil, err
+		}
+		if registrant != actor {
+			return false, RegisterImageComputeUnits, utils.ErrBytes(ErrNotArtifactRegistrant), nil, nil
+		}
+		if !bytes.Equal(registered.Bytes(), c.Bytes()) {
+			return false, RegisterImageComputeUnits, utils.ErrBytes(fmt.Errorf("%w: %s", ErrArtifactRegistered, registered)), nil, nil
+		}
+		size = max(size, paid)
+	}
+	fee, err := smath.Mul64(size-paid, storageFeePerByte(rules))
+	if err != nil {
+		return false, RegisterImageComputeUnits, utils.ErrBytes(err), nil, nil
+	}
+	// every validator stores the artifact, so the fee is burned rather than
+	// paid to any of them
+	if err := storage.SubBalance(ctx, mu, actor, fee); err != nil {
+		return false, RegisterImageComputeUnits, utils.ErrBytes(fmt.Errorf("%w: unable to pay storage fee", err)), nil, nil
+	}
+	if err := storage.StoreHashKeyType(ctx, mu, imageID, valType, actor, size, c); err != nil {
 		return false, 0, nil, nil, err
 	}
 	return true, RegisterImageComputeUnits, nil, nil, nil
 }
+
+func storageFeePerByte(rules chain.Rules) uint64 {
+	v, ok := rules.FetchCustom(mconsts.StorageFeePerByteKey)
+	if !ok {
+		return 0
+	}
+	return v.(uint64)
+}
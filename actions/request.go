@@ -0,0 +1,223 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/state"
+)
+
+// requestArtifacts are the inputs a verification request is made against.
+type requestArtifacts struct {
+	imageID  ids.ID
+	valTypes []uint16 // registered with [RegisterImage]
+	// segments are the val types of the segments following the proof of a
+	// segmented proof, also found in [valTypes]
+	segments []uint16
+	inline   [][]byte // carried by the request itself
+	// pinned is the proving system the image must be registered for, with
+	// the program the request is verified against, 0 if it pins none.
+	pinned uint8
+	// programID is the program the request claims, checked against the
+	// pinned one if set
+	programID []byte
+}
+
+// RequestedArtifacts are the registered artifacts a verification request is
+// made against.
+type RequestedArtifacts struct {
+	ImageID ids.ID
+	Proof   uint16
+	// Segments follow [Proof], in execution order, for proofs split in
+	// segments.
+	Segments []uint16
+	// Program are the artifacts of the image other than the proof, such as
+	// ELFs and circuit data.
+	Program       []uint16
+	TimeOutBlocks uint64
+}
+
+// Requested returns the registered artifacts verification request [action]
+// is made against. It returns false for other actions.
+func Requested(action chain.Action) (*RequestedArtifacts, bool) {
+	var (
+		a             *requestArtifacts
+		proof         uint64
+		timeOutBlocks uint64
+	)
+	switch r := action.(type) {
+	case *SP1:
+		a, proof, timeOutBlocks = r.artifacts(), r.ProofValType, r.TimeOutBlocks
+	case *RiscZero:
+		a, proof, timeOutBlocks = r.artifacts(), r.ProofValType, r.TimeOutBlocks
+	case *Miden:
+		a, proof, timeOutBlocks = r.artifacts(), r.ProofValType, r.TimeOutBlocks
+	case *Jolt:
+		a, proof, timeOutBlocks = r.artifacts(), r.ProofValType, r.TimeOutBlocks
+	case *PLONKY2:
+		a, proof, timeOutBlocks = r.artifacts(), r.ProofValType, r.TimeOutBlocks
+	case *Halo2:
+		a, proof, timeOutBlocks = r.artifacts(), r.ProofValType, r.TimeOutBlocks
+	case *Noir:
+		a, proof, timeOutBlocks = r.artifacts(), r.ProofValType, r.TimeOutBlocks
+	case *Cairo:
+		a, proof, timeOutBlocks = r.artifacts(), r.ProofValType, r.TimeOutBlocks
+	case *IVCStep:
+		a, proof, timeOutBlocks = r.artifacts(), r.ProofValType, r.TimeOutBlocks
+	default:
+		return nil, false
+	}
+	requested := &RequestedArtifacts{
+		ImageID:       a.imageID,
+		Proof:         uint16(proof),
+		Segments:      a.segments,
+		TimeOutBlocks: timeOutBlocks,
+	}
+	for _, valType := range a.valTypes {
+		if valType != requested.Proof && !slices.Contains(a.segments, valType) {
+			requested.Program = append(requested.Program, valType)
+		}
+	}
+	return requested, true
+}
+
+// requestStateKeys are the keys touched by every verification request.
+func requestStateKeys(actor codec.Address, txID ids.ID, a *requestArtifacts) state.Keys {
+	keys := state.Keys{
+		string(storage.TimeOutKey(txID)):        state.All,
+		string(storage.BalanceKey(actor)):       state.All,
+		string(storage.RewardPoolKey(txID)):     state.All,
+		string(storage.CommitDeadlineKey(txID)): state.All,
+		string(storage.RequestKey(txID)):        state.All,
+	}
+	for _, valType := range a.valTypes {
+		keys.Add(string(storage.HashKey(a.imageID, valType)), state.Read)
+	}
+	if a.pinned != 0 {
+		keys.Add(string(storage.ImageKey(a.imageID)), state.Read)
+	}
+	return keys
+}
+
+func requestStateKeysMaxChunks(a *requestArtifacts) []uint16 {
+	chunks := []uint16{storage.TimeOutChunks, storage.BalanceChunks, storage.RewardPoolChunks, storage.TimeOutChunks, storage.RequestChunks}
+	for range a.valTypes {
+		chunks = append(chunks, storage.HashChunksMax)
+	}
+	if a.pinned != 0 {
+		chunks = append(chunks, storage.ImageChunks)
+	}
+	return chunks
+}
+
+// pinnedProgramID returns the program pinned for the image of [a], checking
+// that the image was registered for the proving system of the request and
+// that the request claims no other program.
+func pinnedProgramID(ctx context.Context, im state.Immutable, a *requestArtifacts) ([]byte, error) {
+	image, err := storage.GetImage(ctx, im, a.imageID)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrImageNotRegistered, a.imageID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if image.ProvingSystem != uint64(a.pinned) {
+		return nil, fmt.Errorf("%w: %d, expected %d", ErrImageProvingSystem, image.ProvingSystem, a.pinned)
+	}
+	if len(a.programID) > 0 && !bytes.Equal(a.programID, image.ProgramID) {
+		return nil, fmt.Errorf("%w: %x, pinned %x", ErrProgramIDMismatch, a.programID, image.ProgramID)
+	}
+	return image.ProgramID, nil
+}
+
+// ParseProgramID decodes a hex program ID, with or without a 0x prefix.
+func ParseProgramID(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+// ParseCairoProgramHash decodes a hex Cairo program hash, a field element
+// usually printed without leading zeros, to [ProgramIDLen] big endian bytes.
+func ParseCairoProgramHash(s string) ([]byte, error) {
+	s = strings.TrimPrefix(s, "0x")
+	if len(s) == 0 || len(s) > 2*ProgramIDLen {
+		return nil, fmt.Errorf("%w: cairo program hash %q", ErrProgramIDLength, s)
+	}
+	return hex.DecodeString(strings.Repeat("0", 2*ProgramIDLen-len(s)) + s)
+}
+
+func verificationReward(rules chain.Rules) uint64 {
+	v, ok := rules.FetchCustom(mconsts.VerificationRewardKey)
+	if !ok {
+		return 0
+	}
+	return v.(uint64)
+}
+
+func commitBlocks(rules chain.Rules, provingSystem uint8) uint64 {
+	v, ok := rules.FetchCustom(mconsts.CommitRevealKey)
+	if !ok {
+		return 0
+	}
+	return v.(func(uint8) uint64)(provingSystem)
+}
+
+// openRequest records what a new verification request is verified against
+// and funds the reward pool paid out to the validators that agree with its
+// outcome. Proving systems configured for commit-reveal voting also get
+// their commit deadline stored.
+func openRequest(
+	ctx context.Context,
+	rules chain.Rules,
+	mu state.Mutable,
+	ts int64,
+	actor codec.Address,
+	txID ids.ID,
+	provingSystem uint8,
+	timeOutBlocks uint64,
+	a *requestArtifacts,
+) error {
+	roots := make([][]byte, 0, len(a.valTypes)+len(a.inline))
+	for _, valType := range a.valTypes {
+		c, _, err := storage.GetHashKeyType(ctx, mu, a.imageID, valType)
+		if errors.Is(err, database.ErrNotFound) {
+			return fmt.Errorf("%w: val type %d", ErrArtifactNotRegistered, valType)
+		}
+		if err != nil {
+			return err
+		}
+		roots = append(roots, c.Bytes())
+	}
+	for _, data := range a.inline {
+		root := sha256.Sum256(data)
+		roots = append(roots, root[:])
+	}
+	if a.pinned != 0 {
+		programID, err := pinnedProgramID(ctx, mu, a)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, programID)
+	}
+	if err := storage.StoreRequest(ctx, mu, txID, provingSystem, a.imageID, ArtifactsDigest(roots)); err != nil {
+		return fmt.Errorf("%w: unable to store request", err)
+	}
+	if blocks := commitBlocks(rules, provingSystem); blocks > 0 {
+		deadline := storage.CommitDeadline(ts, timeOutBlocks, blocks)
+		if err := storage.StoreCommitDeadline(ctx, mu, txID, deadline); err != nil {
+			return fmt.Errorf("%w: unable to store commit deadline", err)
+		}
+	}
+	return storage.StoreRewardPool(ctx, mu, actor, txID, verificationReward(rules))
+}
@@ -0,0 +1,202 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var _ chain.Action = (*RevealCanary)(nil)
+
+const MaxCanaryVoters = 16
+
+// RevealCanary discloses, once voting is over, that a request committed to
+// with [CommitCanary] carried a known-invalid proof. Every listed voter that
+// voted valid on it is penalized, the penalty is withheld from its next
+// rewards. Large validator sets can be penalized over several reveals of the
+// same request, all within the reveal window.
+type RevealCanary struct {
+	TxID   ids.ID          `json:"tx_id"`
+	Salt   []byte          `json:"salt"`
+	Voters []codec.Address `json:"voters"`
+}
+
+func (*RevealCanary) GetTypeID() uint8 {
+	return mconsts.RevealCanaryID
+}
+
+func (r *RevealCanary) StateKeys(codec.Address, ids.ID) state.Keys {
+	keys := state.Keys{
+		string(storage.TimeOutKey(r.TxID)):                                state.Read,
+		string(storage.CanaryKey(r.TxID)):                                 state.All,
+		string(storage.CanaryCommitKey(CanaryCommitment(r.TxID, r.Salt))): state.Read,
+		string(storage.CanaryStatsKey()):                                  state.All,
+	}
+	for _, voter := range r.Voters {
+		keys.Add(string(storage.VoteKey(r.TxID, voter)), state.All)
+		keys.Add(string(storage.ParticipationKey(voter)), state.All)
+	}
+	return keys
+}
+
+func (r *RevealCanary) StateKeysMaxChunks() []uint16 {
+	chunks := []uint16{storage.TimeOutChunks, storage.CanaryChunks, storage.CanaryChunks, storage.CanaryChunks}
+	for range r.Voters {
+		chunks = append(chunks, storage.TimeOutChunks, storage.ParticipationChunks)
+	}
+	return chunks
+}
+
+func (*RevealCanary) OutputsWarpMessage() bool {
+	return false
+}
+
+func (*RevealCanary) MaxComputeUnits(chain.Rules) uint64 {
+	return RevealCanaryComputeUnits
+}
+
+func (r *RevealCanary) Size() int {
+	return consts.IDLen + codec.BytesLen(r.Salt) + consts.IntLen + len(r.Voters)*codec.AddressLen
+}
+
+func (r *RevealCanary) Marshal(p *codec.Packer) {
+	p.PackID(r.TxID)
+	p.PackBytes(r.Salt)
+	p.PackInt(len(r.Voters))
+	for _, voter := range r.Voters {
+		p.PackAddress(voter)
+	}
+}
+
+func UnmarshalRevealCanary(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var reveal RevealCanary
+	p.UnpackID(true, &reveal.TxID)
+	p.UnpackBytes(MaxSaltLen, false, &reveal.Salt)
+	count := p.UnpackInt(false)
+	if count > MaxCanaryVoters {
+		return nil, fmt.Errorf("%w: %d voters", ErrTooManyCanaryVoters, count)
+	}
+	reveal.Voters = make([]codec.Address, count)
+	for i := range reveal.Voters {
+		p.UnpackAddress(&reveal.Voters[i])
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &reveal, nil
+}
+
+func (*RevealCanary) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+func (r *RevealCanary) Execute(
+	ctx context.Context,
+	rules chain.Rules,
+	mu state.Mutable,
+	ts int64,
+	actor codec.Address,
+	_ ids.ID,
+	_ bool,
+) (bool, uint64, []byte, *warp.UnsignedMessage, error) {
+	if err := checkCanaryAuthority(rules, actor); err != nil {
+		return false, 500, utils.ErrBytes(err), nil, nil
+	}
+	if len(r.Voters) > MaxCanaryVoters {
+		return false, 500, utils.ErrBytes(ErrTooManyCanaryVoters), nil, nil
+	}
+	timeOut, err := storage.GetTimeOut(ctx, mu, r.TxID)
+	if err != nil {
+		return false, 1000, utils.ErrBytes(fmt.Errorf("%s: cant get timeout from storage", err)), nil, nil
+	}
+	// the canary must stay hidden until the request is finalized
+	if ts <= timeOut {
+		return false, 1000, utils.ErrBytes(ErrVotingOpen), nil, nil
+	}
+	// rewards are only held back for the reveal window
+	if end, _ := canaryRevealEnd(rules, timeOut); ts > end {
+		return false, 1000, utils.ErrBytes(ErrCanaryRevealOver), nil, nil
+	}
+	stats, err := storage.GetCanaryStats(ctx, mu)
+	if err != nil {
+		return false, 1000, nil, nil, fmt.Errorf("%w: unable to get canary stats", err)
+	}
+	revealed, err := storage.IsCanary(ctx, mu, r.TxID)
+	if err != nil {
+		return false, 1000, nil, nil, fmt.Errorf("%w: unable to get canary", err)
+	}
+	if !revealed {
+		// only requests designated before they were made can be canaries
+		committedAt, exists, err := storage.GetCanaryCommitment(ctx, mu, CanaryCommitment(r.TxID, r.Salt))
+		if err != nil {
+			return false, 1000, nil, nil, fmt.Errorf("%w: unable to get canary commitment", err)
+		}
+		openedAt, err := storage.GetOpenedAt(ctx, mu, r.TxID)
+		if err != nil {
+			return false, 1000, nil, nil, fmt.Errorf("%w: unable to get request timestamp", err)
+		}
+		if !exists || committedAt >= openedAt {
+			return false, 1000, utils.ErrBytes(ErrNoCanaryCommitment), nil, nil
+		}
+		if err := storage.MarkCanary(ctx, mu, r.TxID); err != nil {
+			return false, 1000, nil, nil, fmt.Errorf("%w: unable to mark canary", err)
+		}
+		stats.Revealed++
+	}
+	v, _ := rules.FetchCustom(mconsts.CanaryPenaltyKey)
+	penalty := v.(uint64)
+	for _, voter := range r.Voters {
+		voted, outcome, _, err := storage.GetVoteRecord(ctx, mu, r.TxID, voter)
+		if err != nil {
+			return false, 2000, nil, nil, fmt.Errorf("%w: unable to get vote", err)
+		}
+		if !voted || outcome != mconsts.OutcomeValid {
+			continue
+		}
+		penalized, err := storage.IsVotePenalized(ctx, mu, r.TxID, voter)
+		if err != nil {
+			return false, 2000, nil, nil, fmt.Errorf("%w: unable to get vote", err)
+		}
+		if penalized {
+			continue
+		}
+		p, _, err := storage.GetParticipation(ctx, mu, voter)
+		if err != nil {
+			return false, 2000, nil, nil, fmt.Errorf("%w: unable to get participation", err)
+		}
+		p.CanariesFailed++
+		p.Penalties, err = smath.Add64(p.Penalties, penalty)
+		if err != nil {
+			return false, 2000, utils.ErrBytes(err), nil, nil
+		}
+		p.PenaltiesOwed, err = smath.Add64(p.PenaltiesOwed, penalty)
+		if err != nil {
+			return false, 2000, utils.ErrBytes(err), nil, nil
+		}
+		if err := storage.SetParticipation(ctx, mu, voter, p); err != nil {
+			return false, 2000, nil, nil, fmt.Errorf("%w: unable to store participation", err)
+		}
+		if err := storage.PenalizeVote(ctx, mu, r.TxID, voter); err != nil {
+			return false, 2000, nil, nil, fmt.Errorf("%w: unable to penalize vote", err)
+		}
+		stats.LazyVotes++
+		stats.Penalties, err = smath.Add64(stats.Penalties, penalty)
+		if err != nil {
+			return false, 2000, utils.ErrBytes(err), nil, nil
+		}
+	}
+	if err := storage.SetCanaryStats(ctx, mu, stats); err != nil {
+		return false, 3000, nil, nil, fmt.Errorf("%w: unable to store canary stats", err)
+	}
+	return true, RevealCanaryComputeUnits, nil, nil, nil
+}
@@ -12,24 +12,23 @@ import (
 	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/consts"
 	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
 )
 
 var _ chain.Action = (*RiscZero)(nil)
 
 type RiscZero struct {
-	ImageID         ids.ID `json:"image_id"`
-	ProofValType    uint64 `json:"proof_val_type"`
+	ImageID      ids.ID `json:"image_id"`
+	ProofValType uint64 `json:"proof_val_type"`
+	// RiscZeroImageID is optional. Proofs are verified against the RISC Zero
+	// image ID pinned when [ImageID] was registered, and requests naming
+	// another one, hex encoded, are rejected.
 	RiscZeroImageID string `json:"risc_zero_image_id"`
-	TimeOutBlocks   uint64 `json:"time_out_blocks"`
-}
-
-type RiscZeroArgs struct {
-	RiscZeroImageID string `json:"risc_zero_image_id"`
-	ProofFilePath   string `json:"proof_file_path"`
-}
-
-type RiscZeroReplyArgs struct {
-	IsSubmitted bool `json:"is_submitted"`
+	// SegmentValTypes are the segment receipts following the one of
+	// [ProofValType], in execution order, for executions split in more than
+	// one segment.
+	SegmentValTypes []uint16 `json:"segment_val_types,omitempty"`
+	TimeOutBlocks   uint64   `json:"time_out_blocks"`
 }
 
 func (*RiscZero) GetTypeID() uint8 {
@@ -37,37 +36,59 @@ func (*RiscZero) GetTypeID() uint8 {
 }
 
 func (r *RiscZero) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
-	return state.Keys{string(storage.TimeOutKey(txID)): state.All}
+	return requestStateKeys(actor, txID, r.artifacts())
+}
+
+func (r *RiscZero) StateKeysMaxChunks() []uint16 {
+	return requestStateKeysMaxChunks(r.artifacts())
 }
 
-func (*RiscZero) StateKeysMaxChunks() []uint16 {
-	return []uint16{storage.TimeOutChunks}
+func (r *RiscZero) artifacts() *requestArtifacts {
+	programID, err := ParseProgramID(r.RiscZeroImageID)
+	if err != nil {
+		// never matches the pinned image ID
+		programID = []byte(r.RiscZeroImageID)
+	}
+	return &requestArtifacts{
+		imageID:   r.ImageID,
+		valTypes:  append([]uint16{uint16(r.ProofValType)}, r.SegmentValTypes...),
+		segments:  r.SegmentValTypes,
+		pinned:    mconsts.RiscZeroID,
+		programID: programID,
+	}
 }
 
 func (*RiscZero) OutputsWarpMessage() bool {
 	return false
 }
 
-func (*RiscZero) MaxComputeUnits(chain.Rules) uint64 {
-	return RiscZeroComputeUnits
+func (r *RiscZero) MaxComputeUnits(chain.Rules) uint64 {
+	return RiscZeroComputeUnits + segmentComputeUnits(r.SegmentValTypes)
 }
 
 func (r *RiscZero) Size() int {
-	return consts.IDLen + consts.Uint64Len*2 + len(r.RiscZeroImageID)
+	return consts.IDLen + consts.Uint64Len*2 + codec.StringLen(r.RiscZeroImageID) + segmentsSize(r.SegmentValTypes)
 }
 
 func (r *RiscZero) Marshal(p *codec.Packer) {
 	p.PackID(r.ImageID)
 	p.PackUint64(r.ProofValType)
 	p.PackString(r.RiscZeroImageID)
+	packSegments(p, r.SegmentValTypes)
 	p.PackUint64(r.TimeOutBlocks)
 }
 
 func UnmarshalRiscZero(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
-	var riscZero RiscZero
+	var (
+		riscZero RiscZero
+		err      error
+	)
 	p.UnpackID(true, &riscZero.ImageID)
 	riscZero.ProofValType = p.UnpackUint64(true)
-	riscZero.RiscZeroImageID = p.UnpackString(true)
+	riscZero.RiscZeroImageID = p.UnpackString(false)
+	if riscZero.SegmentValTypes, err = unpackSegments(p); err != nil {
+		return nil, err
+	}
 	riscZero.TimeOutBlocks = p.UnpackUint64(true)
 	return &riscZero, nil
 }
@@ -89,5 +110,11 @@ func (r *RiscZero) Execute(
 	if err := storage.StoreTimeOut(ctx, mu, txID, r.TimeOutBlocks, ts); err != nil {
 		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store time out", err)
 	}
-	return true, 6000, nil, nil, nil
+	if err := checkSegments(uint16(r.ProofValType), r.SegmentValTypes); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	if err := openRequest(ctx, rules, mu, ts, actor, txID, r.GetTypeID(), r.TimeOutBlocks, r.artifacts()); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	return true, 6000 + segmentComputeUnits(r.SegmentValTypes), nil, nil, nil
 }
@@ -0,0 +1,69 @@
+package actions
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+)
+
+// MaxSegments is the most segments a segmented proof may be split in.
+const MaxSegments = 64
+
+// segmentsSize is the size of [segments] as packed by [packSegments]. Val
+// types are packed in 2 bytes each, so that long executions don't weigh on
+// the transaction size.
+func segmentsSize(segments []uint16) int {
+	return consts.IntLen + len(segments)*consts.Uint16Len
+}
+
+func packSegments(p *codec.Packer, segments []uint16) {
+	p.PackInt(len(segments))
+	b := make([]byte, 0, len(segments)*consts.Uint16Len)
+	for _, valType := range segments {
+		b = binary.BigEndian.AppendUint16(b, valType)
+	}
+	p.PackFixedBytes(b)
+}
+
+func unpackSegments(p *codec.Packer) ([]uint16, error) {
+	count := p.UnpackInt(false)
+	if count > MaxSegments-1 {
+		return nil, fmt.Errorf("%w: %d segments", ErrTooManySegments, count+1)
+	}
+	if count == 0 {
+		return nil, p.Err()
+	}
+	b := make([]byte, count*consts.Uint16Len)
+	p.UnpackFixedBytes(len(b), &b)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	segments := make([]uint16, count)
+	for i := range segments {
+		segments[i] = binary.BigEndian.Uint16(b[i*consts.Uint16Len:])
+	}
+	return segments, nil
+}
+
+// checkSegments checks that the segments following [proof] are distinct
+// artifacts, none of which is one of the [program] artifacts.
+func checkSegments(proof uint16, segments []uint16, program ...uint16) error {
+	seen := make(map[uint16]struct{}, len(segments)+len(program)+1)
+	seen[proof] = struct{}{}
+	for _, valType := range program {
+		seen[valType] = struct{}{}
+	}
+	for i, valType := range segments {
+		if _, ok := seen[valType]; ok {
+			return fmt.Errorf("%w: segment %d, val type %d", ErrDuplicateSegment, i+1, valType)
+		}
+		seen[valType] = struct{}{}
+	}
+	return nil
+}
+
+func segmentComputeUnits(segments []uint16) uint64 {
+	return SegmentComputeUnits * uint64(len(segments))
+}
@@ -12,14 +12,18 @@ import (
 	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/consts"
 	"github.com/sausaging/hypersdk/state"
+	"github.com/sausaging/hypersdk/utils"
 )
 
 var _ chain.Action = (*SP1)(nil)
 
 type SP1 struct {
-	ImageID       ids.ID `json:"image_id"`
-	ProofValType  uint64 `json:"proof_val_type"`
-	TimeOutBlocks uint64 `json:"time_out_blocks"`
+	ImageID      ids.ID `json:"image_id"`
+	ProofValType uint64 `json:"proof_val_type"`
+	// SegmentValTypes are the shards following the one of [ProofValType],
+	// in execution order, for executions proven in more than one shard.
+	SegmentValTypes []uint16 `json:"segment_val_types,omitempty"`
+	TimeOutBlocks   uint64   `json:"time_out_blocks"`
 }
 
 func (*SP1) GetTypeID() uint8 {
@@ -27,35 +31,51 @@ func (*SP1) GetTypeID() uint8 {
 }
 
 func (s *SP1) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
-	return state.Keys{string(storage.TimeOutKey(txID)): state.All}
+	return requestStateKeys(actor, txID, s.artifacts())
 }
 
-func (*SP1) StateKeysMaxChunks() []uint16 {
-	return []uint16{storage.TimeOutChunks}
+func (s *SP1) StateKeysMaxChunks() []uint16 {
+	return requestStateKeysMaxChunks(s.artifacts())
+}
+
+func (s *SP1) artifacts() *requestArtifacts {
+	return &requestArtifacts{
+		imageID:  s.ImageID,
+		valTypes: append([]uint16{ELFValType, uint16(s.ProofValType)}, s.SegmentValTypes...),
+		segments: s.SegmentValTypes,
+		pinned:   mconsts.SP1ID,
+	}
 }
 
 func (*SP1) OutputsWarpMessage() bool {
 	return false
 }
 
-func (*SP1) MaxComputeUnits(chain.Rules) uint64 {
-	return SP1ComputeUnits
+func (s *SP1) MaxComputeUnits(chain.Rules) uint64 {
+	return SP1ComputeUnits + segmentComputeUnits(s.SegmentValTypes)
 }
 
 func (s SP1) Size() int {
-	return consts.IDLen + consts.Uint64Len*2
+	return consts.IDLen + consts.Uint64Len*2 + segmentsSize(s.SegmentValTypes)
 }
 
 func (s *SP1) Marshal(p *codec.Packer) {
 	p.PackID(s.ImageID)
 	p.PackUint64(s.ProofValType)
+	packSegments(p, s.SegmentValTypes)
 	p.PackUint64(s.TimeOutBlocks)
 }
 
 func UnmarshalSP1(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
-	var sp1 SP1
+	var (
+		sp1 SP1
+		err error
+	)
 	p.UnpackID(true, &sp1.ImageID)
 	sp1.ProofValType = p.UnpackUint64(true)
+	if sp1.SegmentValTypes, err = unpackSegments(p); err != nil {
+		return nil, err
+	}
 	sp1.TimeOutBlocks = p.UnpackUint64(true)
 	return &sp1, nil
 }
@@ -77,5 +97,11 @@ func (s *SP1) Execute(
 	if err := storage.StoreTimeOut(ctx, mu, txID, s.TimeOutBlocks, ts); err != nil {
 		return false, 4000, nil, nil, fmt.Errorf("%w: unable to store time out", err)
 	}
-	return true, 8000, nil, nil, nil
+	if err := checkSegments(uint16(s.ProofValType), s.SegmentValTypes, ELFValType); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	if err := openRequest(ctx, rules, mu, ts, actor, txID, s.GetTypeID(), s.TimeOutBlocks, s.artifacts()); err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	return true, 8000 + segmentComputeUnits(s.SegmentValTypes), nil, nil, nil
 }
@@ -1,12 +1,12 @@
 package actions
 
 import (
+	"crypto/sha256"
 	"io"
 	"os"
 
 	"github.com/ava-labs/avalanchego/ids"
 	rpc "github.com/gorilla/rpc/v2/json2"
-	"github.com/sausaging/hypersdk/consts"
 )
 
 func WriteFile(filePath string, data []byte) error {
@@ -29,13 +29,11 @@ func DecodeClientResponse(r io.Reader, reply interface{}) (interface{}, error) {
 	return reply, err
 }
 
-func GetMessage(txID ids.ID, vote bool) (msg []byte) {
-	msg = make([]byte, consts.IDLen+consts.BoolLen)
-	copy(msg[:], txID[:])
-	if vote {
-		copy(msg[consts.IDLen:], []byte{1})
-	} else {
-		copy(msg[consts.IDLen:], []byte{0})
-	}
-	return msg
+// CanaryCommitment hides that [txID] is a canary request until it is revealed
+// with [salt].
+func CanaryCommitment(txID ids.ID, salt []byte) []byte {
+	h := sha256.New()
+	h.Write(txID[:])
+	h.Write(salt)
+	return h.Sum(nil)
 }
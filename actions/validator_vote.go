@@ -1,7 +1,10 @@
 package actions
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -22,10 +25,29 @@ import (
 var _ chain.Action = (*ValidatorVote)(nil)
 
 type ValidatorVote struct {
-	TxID      ids.ID `json:"tx_id"`
-	Vote      bool   `json:"vote"`
-	Signature []byte `json:"signature"`
-	PublicKey []byte `json:"public_key"`
+	TxID ids.ID `json:"tx_id"`
+	// RewardAddress is credited with the vote and paid its rewards. It is
+	// signed with the vote, so a vote relayed by anyone else still pays the
+	// validator.
+	RewardAddress codec.Address `json:"reward_address"`
+	// Outcome is the verdict on the proof, or why the validator couldn't
+	// reach one. Only verdicts count toward quorum.
+	Outcome mconsts.Outcome `json:"outcome"`
+	// ProvingSystem, ImageID, ArtifactsDigest and Deadline are the inputs
+	// the validator verified. They must match the request in state.
+	ProvingSystem   uint8  `json:"proving_system"`
+	ImageID         ids.ID `json:"image_id"`
+	ArtifactsDigest []byte `json:"artifacts_digest"`
+	Deadline        int64  `json:"deadline"`
+	Signature       []byte `json:"signature"`
+	PublicKey       []byte `json:"public_key"`
+	// Salt reveals the vote committed with [CommitVote]. It is only
+	// required by proving systems using commit-reveal voting.
+	Salt []byte `json:"salt"`
+	// OutputsDigest is the digest of the public outputs the verifier
+	// reported for a valid proof. Valid votes only count toward quorum with
+	// the votes reporting the same outputs.
+	OutputsDigest []byte `json:"outputs_digest"`
 }
 
 func (*ValidatorVote) GetTypeID() uint8 {
@@ -34,15 +56,32 @@ func (*ValidatorVote) GetTypeID() uint8 {
 
 func (v *ValidatorVote) StateKeys(actor codec.Address, txID ids.ID) state.Keys {
 	return state.Keys{
-		string(storage.TimeOutKey(v.TxID)):     state.All,
-		string(storage.WeightKey(v.TxID)):      state.All,
-		string(storage.StatusKey(txID)):        state.All,
-		string(storage.VoteKey(v.TxID, actor)): state.All,
+		string(storage.TimeOutKey(v.TxID)):                 state.All,
+		string(storage.WeightKey(v.TxID, v.OutputsDigest)): state.All,
+		string(storage.StatusKey(v.TxID)):                  state.All,
+		string(storage.VoteKey(v.TxID, v.RewardAddress)):   state.All,
+		string(storage.VoterKey(v.TxID, v.PublicKey)):      state.All,
+		string(storage.TallyKey(v.TxID)):                   state.All,
+		string(storage.ParticipationKey(v.RewardAddress)):  state.All,
+		string(storage.CommitDeadlineKey(v.TxID)):          state.Read,
+		string(storage.CommitKey(v.TxID, v.PublicKey)):     state.Read,
+		string(storage.RequestKey(v.TxID)):                 state.Read,
 	}
 }
 
 func (*ValidatorVote) StateKeysMaxChunks() []uint16 {
-	return []uint16{storage.TimeOutChunks, storage.TimeOutChunks, storage.TimeOutChunks, storage.TimeOutChunks}
+	return []uint16{
+		storage.TimeOutChunks,
+		storage.TimeOutChunks,
+		storage.TimeOutChunks,
+		storage.TimeOutChunks,
+		storage.TimeOutChunks,
+		storage.TallyChunks,
+		storage.ParticipationChunks,
+		storage.TimeOutChunks,
+		storage.CommitChunks,
+		storage.RequestChunks,
+	}
 }
 
 func (*ValidatorVote) OutputsWarpMessage() bool {
@@ -54,22 +93,42 @@ func (*ValidatorVote) MaxComputeUnits(chain.Rules) uint64 {
 }
 
 func (v *ValidatorVote) Size() int {
-	return consts.IDLen + consts.BoolLen + len(v.Signature) + len(v.PublicKey)
+	return consts.IDLen*2 + codec.AddressLen + consts.ByteLen*2 + codec.BytesLen(v.ArtifactsDigest) + consts.Int64Len + codec.BytesLen(v.Signature) + codec.BytesLen(v.PublicKey) + codec.BytesLen(v.Salt) + codec.BytesLen(v.OutputsDigest)
 }
 
 func (v *ValidatorVote) Marshal(p *codec.Packer) {
 	p.PackID(v.TxID)
-	p.PackBool(v.Vote)
+	p.PackAddress(v.RewardAddress)
+	p.PackByte(byte(v.Outcome))
+	p.PackByte(v.ProvingSystem)
+	p.PackID(v.ImageID)
+	p.PackBytes(v.ArtifactsDigest)
+	p.PackInt64(v.Deadline)
 	p.PackBytes(v.Signature)
 	p.PackBytes(v.PublicKey)
+	p.PackBytes(v.Salt)
+	p.PackBytes(v.OutputsDigest)
 }
 
 func UnmarshalValidatorVote(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
 	var vv ValidatorVote
 	p.UnpackID(true, &vv.TxID)
-	vv.Vote = p.UnpackBool()
+	p.UnpackAddress(&vv.RewardAddress)
+	vv.Outcome = mconsts.Outcome(p.UnpackByte())
+	vv.ProvingSystem = p.UnpackByte()
+	p.UnpackID(true, &vv.ImageID)
+	p.UnpackBytes(sha256.Size, true, &vv.ArtifactsDigest)
+	vv.Deadline = p.UnpackInt64(true)
 	p.UnpackBytes(bls.SignatureLen, true, &vv.Signature)
 	p.UnpackBytes(bls.PublicKeyLen, true, &vv.PublicKey)
+	p.UnpackBytes(MaxSaltLen, false, &vv.Salt)
+	p.UnpackBytes(sha256.Size, false, &vv.OutputsDigest)
+	if !vv.Outcome.Known() {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownOutcome, vv.Outcome)
+	}
+	if err := checkOutputsDigest(vv.Outcome, vv.OutputsDigest); err != nil {
+		return nil, err
+	}
 	return &vv, nil
 }
 
@@ -100,52 +159,154 @@ func (v *ValidatorVote) Execute(
 	if ts > timeOut {
 		return false, 1000, utils.ErrBytes(fmt.Errorf("timeout: can't vote now. timestamp: %d, timeout: %d", ts, timeOut)), nil, nil
 	}
-	pubKey, err := bls.PublicKeyFromBytes(v.PublicKey)
+	request, err := storage.GetRequest(ctx, mu, vTXID)
+	if err != nil {
+		return false, 1000, utils.ErrBytes(fmt.Errorf("%s: cant get request from storage", err)), nil, nil
+	}
+	if err := v.checkRequest(request); err != nil {
+		return false, 1000, utils.ErrBytes(err), nil, nil
+	}
+	deadline, commitReveal, err := storage.GetCommitDeadline(ctx, mu, vTXID)
+	if err != nil {
+		return false, 1000, nil, nil, fmt.Errorf("%w: unable to get commit deadline", err)
+	}
+	if commitReveal {
+		// only votes matching an earlier commitment are counted
+		if ts <= deadline {
+			return false, 1000, utils.ErrBytes(ErrCommitPhaseOpen), nil, nil
+		}
+		commitment, err := storage.GetCommitment(ctx, mu, vTXID, v.PublicKey)
+		if err != nil {
+			return false, 1000, nil, nil, fmt.Errorf("%w: unable to get commitment", err)
+		}
+		if commitment == nil {
+			return false, 1000, utils.ErrBytes(ErrNoCommitment), nil, nil
+		}
+		if !bytes.Equal(commitment, VoteCommitment(v.message(), v.PublicKey, v.Salt)) {
+			return false, 1000, utils.ErrBytes(ErrCommitmentMismatch), nil, nil
+		}
+	}
+	weight, err := verifyValidatorSignature(ctx, rules, v.PublicKey, v.Signature, v.message().Bytes())
+	if err != nil {
+		return false, 4000, utils.ErrBytes(err), nil, nil
+	}
+	// validators vote once, whatever address they sign their vote for
+	if err := storage.GetVote(ctx, mu, vTXID, v.RewardAddress); err != nil {
+		return false, 5000, utils.ErrBytes(fmt.Errorf("%s: already voted", err)), nil, nil
+	}
+	if err := storage.StoreVoter(ctx, mu, vTXID, v.PublicKey); errors.Is(err, storage.ErrAlreadyVoted) {
+		return false, 5000, utils.ErrBytes(err), nil, nil
+	} else if err != nil {
+		return false, 5000, nil, nil, fmt.Errorf("%w: unable to store voter", err)
+	}
+	openedAt, err := storage.GetOpenedAt(ctx, mu, vTXID)
+	if err != nil {
+		return false, 5000, nil, nil, fmt.Errorf("%w: unable to get request time", err)
+	}
+	// outcomes other than valid and invalid are only reported
+	if v.Outcome == mconsts.OutcomeValid {
+		if err := storage.UpdateWeight(ctx, mu, vTXID, v.OutputsDigest, weight, weight); err != nil {
+			return false, 5000, nil, nil, fmt.Errorf("%w: unable to update weight", err)
+		}
+	}
+	if err := storage.StoreVote(ctx, mu, vTXID, v.RewardAddress, v.Outcome, v.OutputsDigest); err != nil {
+		return false, 5000, nil, nil, fmt.Errorf("%w: unable to store vote", err)
+	}
+	if err := storage.AddTally(ctx, mu, vTXID, v.Outcome); err != nil {
+		return false, 5000, nil, nil, fmt.Errorf("%w: unable to update tally", err)
+	}
+	if err := storage.RecordVoteCast(ctx, mu, v.RewardAddress, openedAt); err != nil {
+		return false, 5000, nil, nil, fmt.Errorf("%w: unable to record participation", err)
+	}
+	return true, ValidatorVoteComputeUnits, nil, nil, nil
+}
+
+func (v *ValidatorVote) message() *VoteMessage {
+	return &VoteMessage{
+		RewardAddress:   v.RewardAddress,
+		ProvingSystem:   v.ProvingSystem,
+		TxID:            v.TxID,
+		ImageID:         v.ImageID,
+		ArtifactsDigest: v.ArtifactsDigest,
+		Deadline:        v.Deadline,
+		Outcome:         v.Outcome,
+		OutputsDigest:   v.OutputsDigest,
+	}
+}
+
+// checkOutputsDigest makes sure only valid votes report public outputs, as a
+// sha256 digest.
+func checkOutputsDigest(outcome mconsts.Outcome, outputsDigest []byte) error {
+	switch {
+	case len(outputsDigest) == 0:
+		return nil
+	case outcome != mconsts.OutcomeValid:
+		return fmt.Errorf("%w: reported with outcome %s", ErrOutputsDigest, outcome)
+	case len(outputsDigest) != sha256.Size:
+		return fmt.Errorf("%w: expected %d bytes, found %d", ErrOutputsDigest, sha256.Size, len(outputsDigest))
+	default:
+		return nil
+	}
+}
+
+// checkRequest makes sure the vote was cast for the inputs of [r].
+func (v *ValidatorVote) checkRequest(r *storage.Request) error {
+	switch {
+	case v.ProvingSystem != r.ProvingSystem:
+		return fmt.Errorf("%w: proving system %d, expected %d", ErrVoteBindingMismatch, v.ProvingSystem, r.ProvingSystem)
+	case v.ImageID != r.ImageID:
+		return fmt.Errorf("%w: image id %s, expected %s", ErrVoteBindingMismatch, v.ImageID, r.ImageID)
+	case !bytes.Equal(v.ArtifactsDigest, r.ArtifactsDigest):
+		return fmt.Errorf("%w: artifacts digest", ErrVoteBindingMismatch)
+	case v.Deadline != r.TimeOut:
+		return fmt.Errorf("%w: deadline %d, expected %d", ErrVoteBindingMismatch, v.Deadline, r.TimeOut)
+	default:
+		return nil
+	}
+}
+
+// verifyValidatorSignature checks that [signature] over [msg] was produced by
+// a current validator and returns the weight of that validator.
+func verifyValidatorSignature(
+	ctx context.Context,
+	rules chain.Rules,
+	publicKey []byte,
+	signature []byte,
+	msg []byte,
+) (uint64, error) {
+	pubKey, err := bls.PublicKeyFromBytes(publicKey)
 	if err != nil {
-		return false, 2000, utils.ErrBytes(fmt.Errorf("%s: invalid public key", err)), nil, nil
+		return 0, fmt.Errorf("%w: invalid public key", err)
 	}
-	sig, err := bls.SignatureFromBytes(v.Signature)
+	sig, err := bls.SignatureFromBytes(signature)
 	if err != nil {
-		return false, 2500, utils.ErrBytes(fmt.Errorf("%s: cant get signature from bytes", err)), nil, nil
+		return 0, fmt.Errorf("%w: cant get signature from bytes", err)
 	}
-	f, _ := rules.FetchCustom("")
+	f, _ := rules.FetchCustom(mconsts.ValidatorsKey)
 	currentValidators := f.(func(ctx context.Context) (map[ids.NodeID]*validators.GetValidatorOutput, map[string]struct{}))
-	validators, publicKeys := currentValidators(ctx)
-	// proceed if pubkey exists in the list of validators
-	if _, ok := publicKeys[string(v.PublicKey)]; !ok {
-		return false, 3000, utils.ErrBytes(fmt.Errorf("not a validator")), nil, nil
-	}
-	var totalWeight uint64
-	for _, vdr := range validators {
-		if vdr.PublicKey == pubKey {
-			totalWeight, err = math.Add64(totalWeight, vdr.Weight)
-			if err != nil {
-				return false, 3500, utils.ErrBytes(fmt.Errorf("%s: weight overflow", err)), nil, nil
-			}
-			w := vdr.Weight
-			bls := mauth.BLS{
-				Signer:    pubKey,
-				Signature: sig,
-			}
-			msg := GetMessage(vTXID, v.Vote)
-			unSigMsg, err := warp.NewUnsignedMessage(rules.NetworkID(), rules.ChainID(), msg)
-			if err != nil {
-				return false, 4000, utils.ErrBytes(fmt.Errorf("%s: cant create unsigned message", err)), nil, nil
-			}
-			err = bls.Verify(ctx, unSigMsg.Bytes())
+	vdrs, publicKeys := currentValidators(ctx)
+	if _, ok := publicKeys[string(publicKey)]; !ok {
+		return 0, fmt.Errorf("not a validator")
+	}
+	unSigMsg, err := warp.NewUnsignedMessage(rules.NetworkID(), rules.ChainID(), msg)
+	if err != nil {
+		return 0, fmt.Errorf("%w: cant create unsigned message", err)
+	}
+	auth := mauth.BLS{
+		Signer:    pubKey,
+		Signature: sig,
+	}
+	if err := auth.Verify(ctx, unSigMsg.Bytes()); err != nil {
+		return 0, fmt.Errorf("%w: cant verify signature", err)
+	}
+	var weight uint64
+	for _, vdr := range vdrs {
+		if vdr.PublicKey != nil && bytes.Equal(bls.PublicKeyToBytes(vdr.PublicKey), publicKey) {
+			weight, err = math.Add64(weight, vdr.Weight)
 			if err != nil {
-				return false, 4000, utils.ErrBytes(fmt.Errorf("%s: cant verify signature", err)), nil, nil
-			}
-			// check if this validator voted earlier -> if so dont add
-			if err := storage.GetVote(ctx, mu, vTXID, actor); err != nil {
-				return false, 5000, utils.ErrBytes(fmt.Errorf("%s: already voted", err)), nil, nil
+				return 0, fmt.Errorf("%w: weight overflow", err)
 			}
-			if v.Vote {
-				storage.UpdateWeight(ctx, mu, vTXID, w, totalWeight)
-			}
-			storage.StoreVote(ctx, mu, vTXID, actor)
 		}
 	}
-
-	return true, ValidatorVoteComputeUnits, nil, nil, nil
+	return weight, nil
 }
@@ -0,0 +1,105 @@
+package actions
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/ava-labs/avalanchego/ids"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+)
+
+// VoteMessageVersion is bumped whenever the layout of signed vote payloads
+// changes, so that signatures never verify across layouts.
+const VoteMessageVersion byte = 3
+
+// Domain tags keep vote and commitment signatures apart, both are wrapped in
+// warp messages signed with the same validator key.
+var (
+	voteDomain   = []byte("pvzk/vote")
+	commitDomain = []byte("pvzk/commit")
+)
+
+// VoteMessage is the payload a validator signs for a vote. It binds the
+// outcome to the inputs the validator verified, and to the address its
+// rewards are paid to.
+type VoteMessage struct {
+	RewardAddress   codec.Address
+	ProvingSystem   uint8
+	TxID            ids.ID
+	ImageID         ids.ID
+	ArtifactsDigest []byte // see [ArtifactsDigest]
+	Deadline        int64  // request timeout, in ms
+	Outcome         mconsts.Outcome
+	// OutputsDigest commits to the public outputs of a valid proof, empty if
+	// the verifier reported none.
+	OutputsDigest []byte
+}
+
+// NewVoteMessage builds the message for voting [outcome] on request [txID],
+// with the digest of the public outputs the verifier reported. Rewards for
+// the vote are paid to [rewardAddress].
+func NewVoteMessage(rewardAddress codec.Address, txID ids.ID, r *storage.Request, outcome mconsts.Outcome, outputsDigest []byte) *VoteMessage {
+	return &VoteMessage{
+		RewardAddress:   rewardAddress,
+		ProvingSystem:   r.ProvingSystem,
+		TxID:            txID,
+		ImageID:         r.ImageID,
+		ArtifactsDigest: r.ArtifactsDigest,
+		Deadline:        r.TimeOut,
+		Outcome:         outcome,
+		OutputsDigest:   outputsDigest,
+	}
+}
+
+// [domain] + [version] + [rewardAddress] + [provingSystem] + [txID] +
+// [imageID] + [artifactsDigest] + [deadline] + [outcome] +
+// [len(outputsDigest)] + [outputsDigest]
+func (m *VoteMessage) Bytes() []byte {
+	msg := make([]byte, 0, len(voteDomain)+consts.ByteLen*4+codec.AddressLen+consts.IDLen*2+len(m.ArtifactsDigest)+consts.Uint64Len+len(m.OutputsDigest))
+	msg = append(msg, voteDomain...)
+	msg = append(msg, VoteMessageVersion)
+	msg = append(msg, m.RewardAddress[:]...)
+	msg = append(msg, m.ProvingSystem)
+	msg = append(msg, m.TxID[:]...)
+	msg = append(msg, m.ImageID[:]...)
+	msg = append(msg, m.ArtifactsDigest...)
+	msg = binary.BigEndian.AppendUint64(msg, uint64(m.Deadline))
+	msg = append(msg, byte(m.Outcome), byte(len(m.OutputsDigest)))
+	return append(msg, m.OutputsDigest...)
+}
+
+// VoteCommitment is the value the validator with [publicKey] commits to
+// before revealing [msg] with [salt]. The key, and the reward address signed
+// in [msg], are committed to so that a commitment copied by another
+// validator can't be revealed.
+func VoteCommitment(msg *VoteMessage, publicKey []byte, salt []byte) []byte {
+	h := sha256.New()
+	h.Write(msg.Bytes())
+	h.Write(publicKey)
+	h.Write(salt)
+	return h.Sum(nil)
+}
+
+// [domain] + [version] + [txID] + [commitment]
+func GetCommitMessage(txID ids.ID, commitment []byte) []byte {
+	msg := make([]byte, 0, len(commitDomain)+consts.ByteLen+consts.IDLen+len(commitment))
+	msg = append(msg, commitDomain...)
+	msg = append(msg, VoteMessageVersion)
+	msg = append(msg, txID[:]...)
+	return append(msg, commitment...)
+}
+
+// ArtifactsDigest commits to the registered commitments of the artifacts a
+// request is verified against, in order, followed by the sha256 of any
+// inline artifacts.
+func ArtifactsDigest(roots [][]byte) []byte {
+	h := sha256.New()
+	for _, root := range roots {
+		h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(root))))
+		h.Write(root)
+	}
+	return h.Sum(nil)
+}
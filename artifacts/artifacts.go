@@ -0,0 +1,133 @@
+// Package artifacts hands the artifacts stored by the node's fileDB to
+// verifiers, by file path, by content or through short-lived fetch URLs.
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hypersdk/filedb"
+
+	"github.com/sausaging/hyper-pvzk/protocol"
+	"github.com/sausaging/hyper-pvzk/storage"
+)
+
+// Transports artifacts are sent to verifiers with.
+const (
+	// TransportPath sends the path of the artifacts, for verifiers sharing
+	// the node's filesystem.
+	TransportPath = "path"
+	// TransportContent sends the artifacts along with the job.
+	TransportContent = "content"
+	// TransportURL sends URLs the verifier fetches the artifacts from.
+	TransportURL = "url"
+)
+
+const DefaultURLTTL = 10 * time.Minute
+
+var ErrUnknownTransport = errors.New("unknown artifact transport")
+
+func ValidTransport(transport string) error {
+	switch transport {
+	case TransportPath, TransportContent, TransportURL:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownTransport, transport)
+	}
+}
+
+// Store describes the artifacts of verification jobs.
+type Store struct {
+	db        *filedb.FileDB
+	readState storage.ReadState
+	transport string
+	signer    *Signer
+	ttl       time.Duration
+
+	l       sync.RWMutex
+	baseURL string
+}
+
+func NewStore(db *filedb.FileDB, readState storage.ReadState, transport string, signer *Signer, ttl time.Duration) *Store {
+	return &Store{
+		db:        db,
+		readState: readState,
+		transport: transport,
+		signer:    signer,
+		ttl:       ttl,
+	}
+}
+
+// SetBaseURL sets where verifiers reach the fetch handler of the node.
+func (s *Store) SetBaseURL(baseURL string) {
+	s.l.Lock()
+	s.baseURL = baseURL
+	s.l.Unlock()
+}
+
+func (s *Store) url(imageID ids.ID, valType uint16) string {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return s.signer.URL(s.baseURL, imageID, valType, time.Now().Add(s.ttl))
+}
+
+// Job is the artifacts of a job, as sent with a protocol version.
+type Job struct {
+	// Paths are only set for verifiers sharing the node's filesystem.
+	Paths     map[string]string
+	Artifacts map[string]*protocol.Artifact
+}
+
+// Path returns the file path of [name], empty if it isn't sent.
+func (j *Job) Path(name string) string {
+	return j.Paths[name]
+}
+
+// Describe describes the [valTypes] artifacts of [imageID], keyed by name, to
+// a verifier speaking [version]. Verifiers predating artifact descriptions
+// are sent file paths only. Artifacts the node doesn't have are left out, so
+// that the verifier reports them missing, as are artifacts the node refuses
+// for exceeding their paid size or not matching their commitment.
+func (s *Store) Describe(ctx context.Context, version uint32, imageID ids.ID, valTypes map[string]uint16) (*Job, error) {
+	transport := s.transport
+	if version < protocol.ArtifactsVersion {
+		transport = TransportPath
+	}
+	job := &Job{Paths: make(map[string]string, len(valTypes))}
+	if version >= protocol.ArtifactsVersion {
+		job.Artifacts = make(map[string]*protocol.Artifact, len(valTypes))
+	}
+	for name, valType := range valTypes {
+		data, _, err := Read(ctx, s.db, s.readState, imageID, valType)
+		switch {
+		case Refused(err):
+			continue
+		case errors.Is(err, database.ErrNotFound):
+			// verifiers reading paths report the artifact missing themselves
+			data = nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s artifact: %w", name, err)
+		}
+		if transport == TransportPath {
+			job.Paths[name] = filepath.Join(s.db.BaseDir(), storage.DeployKey(imageID, valType))
+		}
+		if version < protocol.ArtifactsVersion || data == nil {
+			continue
+		}
+		artifact := protocol.NewArtifact(data)
+		switch transport {
+		case TransportContent:
+			artifact.Data = data
+		case TransportURL:
+			artifact.URL = s.url(imageID, valType)
+		}
+		job.Artifacts[name] = artifact
+	}
+	return job, nil
+}
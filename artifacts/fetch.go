@@ -0,0 +1,128 @@
+package artifacts
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hypersdk/filedb"
+
+	"github.com/sausaging/hyper-pvzk/storage"
+)
+
+// FetchPath is where the fetch handler is mounted on the result listener.
+const FetchPath = "/artifacts/"
+
+var (
+	ErrURLExpired   = errors.New("artifact url expired")
+	ErrBadSignature = errors.New("invalid artifact url signature")
+)
+
+// Signer signs fetch URLs with a key only the node knows, so that only the
+// verifiers the node sent URLs to can fetch artifacts, and only until the
+// URLs expire.
+type Signer struct {
+	key [sha256.Size]byte
+}
+
+// NewSigner creates a signer with a random key. URLs don't outlive the
+// node, which is fine as they only live for the time a verification takes.
+func NewSigner() (*Signer, error) {
+	s := &Signer{}
+	if _, err := rand.Read(s.key[:]); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Signer) sign(imageID ids.ID, valType uint16, expires int64) string {
+	mac := hmac.New(sha256.New, s.key[:])
+	mac.Write(imageID[:])
+	_ = binary.Write(mac, binary.BigEndian, valType)
+	_ = binary.Write(mac, binary.BigEndian, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// URL returns a URL under [baseURL] to fetch the [valType] artifact of
+// [imageID] from until [expires].
+func (s *Signer) URL(baseURL string, imageID ids.ID, valType uint16, expires time.Time) string {
+	q := url.Values{
+		"expires": {strconv.FormatInt(expires.Unix(), 10)},
+		"sig":     {s.sign(imageID, valType, expires.Unix())},
+	}
+	return fmt.Sprintf("%s%s%s/%d?%s", strings.TrimSuffix(baseURL, "/"), FetchPath, imageID, valType, q.Encode())
+}
+
+func (s *Signer) verify(imageID ids.ID, valType uint16, q url.Values) error {
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		return ErrBadSignature
+	}
+	if !hmac.Equal([]byte(q.Get("sig")), []byte(s.sign(imageID, valType, expires))) {
+		return ErrBadSignature
+	}
+	if time.Now().Unix() > expires {
+		return ErrURLExpired
+	}
+	return nil
+}
+
+// parsePath parses the imageID and valType out of a path ending in
+// <imageID>/<valType>.
+func parsePath(path string) (ids.ID, uint16, error) {
+	rawImageID, rawValType, ok := strings.Cut(strings.Trim(path, "/"), "/")
+	if !ok {
+		return ids.Empty, 0, fmt.Errorf("expected <imageID>/<valType>, found %q", path)
+	}
+	imageID, err := ids.FromString(rawImageID)
+	if err != nil {
+		return ids.Empty, 0, err
+	}
+	valType, err := strconv.ParseUint(rawValType, 10, 16)
+	if err != nil {
+		return ids.Empty, 0, err
+	}
+	return imageID, uint16(valType), nil
+}
+
+// FetchHandler serves artifacts to the holders of URLs signed by [signer].
+func FetchHandler(db *filedb.FileDB, signer *Signer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		imageID, valType, err := parsePath(strings.TrimPrefix(r.URL.Path, FetchPath))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := signer.verify(imageID, valType, r.URL.Query()); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		data, err := db.Get(storage.DeployKey(imageID, valType))
+		if errors.Is(err, database.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		_, _ = w.Write(data)
+	})
+}
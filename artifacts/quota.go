@@ -0,0 +1,70 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hypersdk/filedb"
+
+	"github.com/sausaging/hyper-pvzk/commitment"
+	"github.com/sausaging/hyper-pvzk/storage"
+)
+
+var (
+	ErrNotRegistered   = errors.New("artifact not registered")
+	ErrExceedsPaidSize = errors.New("artifact exceeds its paid size")
+)
+
+// Read reads the [valType] artifact of [imageID] and returns it with its
+// registered commitment. It refuses artifacts grown beyond the size paid for
+// at registration, and artifacts that don't match their commitment. It
+// returns [database.ErrNotFound] if the artifact isn't registered or
+// uploaded.
+func Read(ctx context.Context, db *filedb.FileDB, readState storage.ReadState, imageID ids.ID, valType uint16) ([]byte, *commitment.Commitment, error) {
+	c, size, err := storage.GetHashKeyTypeFromState(ctx, readState, imageID, valType)
+	if err != nil {
+		return nil, nil, err
+	}
+	// fileDB checks the stored checksum on read
+	data, err := db.Get(storage.DeployKey(imageID, valType))
+	if err != nil {
+		return nil, nil, err
+	}
+	if uint64(len(data)) > size {
+		return nil, nil, fmt.Errorf("%w: %d > %d bytes", ErrExceedsPaidSize, len(data), size)
+	}
+	if err := c.Check(data); err != nil {
+		return nil, nil, err
+	}
+	return data, c, nil
+}
+
+// Refused returns whether [err] refuses an artifact the node stores.
+func Refused(err error) bool {
+	return errors.Is(err, ErrExceedsPaidSize) || errors.Is(err, commitment.ErrMismatch)
+}
+
+// CheckChunk checks that appending [n] bytes to the [valType] artifact of
+// [imageID] keeps it within the size paid for at registration, and returns
+// how many paid bytes are left after them.
+func CheckChunk(ctx context.Context, db *filedb.FileDB, readState storage.ReadState, imageID ids.ID, valType uint16, n int) (uint64, error) {
+	_, size, err := storage.GetHashKeyTypeFromState(ctx, readState, imageID, valType)
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, fmt.Errorf("%w: image %s val type %d", ErrNotRegistered, imageID, valType)
+	}
+	if err != nil {
+		return 0, err
+	}
+	data, err := db.Get(storage.DeployKey(imageID, valType))
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return 0, err
+	}
+	total := uint64(len(data)) + uint64(n)
+	if total > size {
+		return 0, fmt.Errorf("%w: %d > %d bytes", ErrExceedsPaidSize, total, size)
+	}
+	return size - total, nil
+}
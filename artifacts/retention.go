@@ -0,0 +1,207 @@
+package artifacts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hypersdk/filedb"
+
+	"github.com/sausaging/hyper-pvzk/storage"
+)
+
+// blockDuration converts retention periods in blocks to milliseconds, like
+// request timeouts.
+const blockDuration = 1000
+
+type RetentionConfig struct {
+	// ProofBlocks is how many blocks proofs are kept after the voting
+	// window of the last request they were submitted with closed.
+	ProofBlocks uint64
+	// ImageBlocks is how many blocks the artifacts of an image are kept
+	// after it was last registered to or requested against. 0 keeps them as
+	// long as the node runs.
+	ImageBlocks uint64
+	// Archival keeps every artifact.
+	Archival bool
+}
+
+// Usage is the disk usage of the artifacts stored by the node.
+type Usage struct {
+	Files          int
+	Bytes          uint64
+	TrackedProofs  int
+	TrackedImages  int
+	CollectedFiles uint64
+	CollectedBytes uint64
+	Archival       bool
+}
+
+// Collector tracks what the artifacts stored by the node are used for, and
+// removes them once the retention policy no longer requires them. Tracking
+// is local to the node and kept in [db].
+type Collector struct {
+	db     database.Database
+	files  *filedb.FileDB
+	config *RetentionConfig
+
+	l              sync.Mutex
+	collectedFiles uint64
+	collectedBytes uint64
+}
+
+func NewCollector(db database.Database, files *filedb.FileDB, config *RetentionConfig) *Collector {
+	return &Collector{
+		db:     db,
+		files:  files,
+		config: config,
+	}
+}
+
+// retain records that the [valType] artifact of [imageID] is of [kind] and
+// in use until [t], keeping the later use if it was already tracked.
+func (c *Collector) retain(imageID ids.ID, valType uint16, kind byte, t int64) error {
+	prevKind, prev, ok, err := storage.GetRetention(c.db, imageID, valType)
+	if err != nil {
+		return err
+	}
+	if ok && prevKind == kind && prev > t {
+		t = prev
+	}
+	return storage.StoreRetention(c.db, imageID, valType, kind, t)
+}
+
+// Registered tracks an artifact registered at [ts] as part of its image,
+// unless requests already submitted it as a proof.
+func (c *Collector) Registered(imageID ids.ID, valType uint16, ts int64) error {
+	if c.config.Archival {
+		return nil
+	}
+	c.l.Lock()
+	defer c.l.Unlock()
+	kind, _, ok, err := storage.GetRetention(c.db, imageID, valType)
+	if err != nil {
+		return err
+	}
+	if ok && kind == storage.RetainProof {
+		return nil
+	}
+	return c.retain(imageID, valType, storage.RetainImage, ts)
+}
+
+// Requested tracks the [proofs] and [program] artifacts of [imageID] a
+// request accepted at [ts] is made against, the voting window of which closes
+// at [closesAt]. Proofs split in segments are tracked segment by segment.
+func (c *Collector) Requested(imageID ids.ID, proofs []uint16, program []uint16, ts int64, closesAt int64) error {
+	if c.config.Archival {
+		return nil
+	}
+	c.l.Lock()
+	defer c.l.Unlock()
+	for _, valType := range program {
+		if err := c.retain(imageID, valType, storage.RetainImage, ts); err != nil {
+			return err
+		}
+	}
+	for _, valType := range proofs {
+		if err := c.retain(imageID, valType, storage.RetainProof, closesAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Collector) expired(kind byte, t int64, now int64) bool {
+	switch kind {
+	case storage.RetainProof:
+		return now >= t+int64(c.config.ProofBlocks)*blockDuration
+	case storage.RetainImage:
+		return c.config.ImageBlocks > 0 && now >= t+int64(c.config.ImageBlocks)*blockDuration
+	default:
+		return false
+	}
+}
+
+// Collect removes the artifacts no longer retained at [now], and returns how
+// many files and bytes were removed.
+func (c *Collector) Collect(now int64) (uint64, uint64, error) {
+	if c.config.Archival {
+		return 0, 0, nil
+	}
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	it := c.db.NewIteratorWithPrefix(storage.RetentionPrefix())
+	defer it.Release()
+	var (
+		expired [][]byte
+		files   uint64
+		size    uint64
+	)
+	for it.Next() {
+		kind, t := storage.ParseRetention(it.Value())
+		if c.expired(kind, t, now) {
+			expired = append(expired, append([]byte(nil), it.Key()...))
+		}
+	}
+	if err := it.Error(); err != nil {
+		return 0, 0, err
+	}
+	for _, k := range expired {
+		imageID, valType := storage.ParseRetentionKey(k)
+		key := storage.DeployKey(imageID, valType)
+		if info, err := os.Stat(filepath.Join(c.files.BaseDir(), key)); err == nil {
+			if err := c.files.Remove(key); err != nil {
+				return files, size, err
+			}
+			files++
+			size += uint64(info.Size())
+		}
+		if err := c.db.Delete(k); err != nil {
+			return files, size, err
+		}
+	}
+	c.collectedFiles += files
+	c.collectedBytes += size
+	return files, size, nil
+}
+
+// Usage reports the disk usage of the artifacts.
+func (c *Collector) Usage() (*Usage, error) {
+	usage := &Usage{Archival: c.config.Archival}
+	entries, err := os.ReadDir(c.files.BaseDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// removed since listed
+			continue
+		}
+		usage.Files++
+		usage.Bytes += uint64(info.Size())
+	}
+
+	c.l.Lock()
+	defer c.l.Unlock()
+	usage.CollectedFiles = c.collectedFiles
+	usage.CollectedBytes = c.collectedBytes
+	it := c.db.NewIteratorWithPrefix(storage.RetentionPrefix())
+	defer it.Release()
+	for it.Next() {
+		switch kind, _ := storage.ParseRetention(it.Value()); kind {
+		case storage.RetainProof:
+			usage.TrackedProofs++
+		case storage.RetainImage:
+			usage.TrackedImages++
+		}
+	}
+	return usage, it.Error()
+}
@@ -0,0 +1,150 @@
+package artifacts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/filedb"
+
+	"github.com/sausaging/hyper-pvzk/storage"
+)
+
+// ServeEndpoint is where [ServeHandler] is registered in the node API.
+const ServeEndpoint = "/artifacts"
+
+func parseQuery(r *http.Request) (ids.ID, uint16, error) {
+	imageID, err := ids.FromString(r.URL.Query().Get("image_id"))
+	if err != nil {
+		return ids.Empty, 0, fmt.Errorf("invalid image_id: %w", err)
+	}
+	valType, err := strconv.ParseUint(r.URL.Query().Get("val_type"), 10, 16)
+	if err != nil {
+		return ids.Empty, 0, fmt.Errorf("invalid val_type: %w", err)
+	}
+	return imageID, uint16(valType), nil
+}
+
+// ServeHandler serves the artifacts uploaded to the node, by image_id and
+// val_type query parameters, so that anyone can fetch exactly what the
+// validators verified. Only registered artifacts matching their commitment
+// and paid size are served, with their commitment as ETag. Range and
+// conditional requests are supported.
+//
+// Artifacts are streamed from their file. The commitment of a file is checked
+// the first time it is served and again only once the file changed, so HEAD,
+// range and conditional requests don't rehash the artifact.
+func ServeHandler(db *filedb.FileDB, readState storage.ReadState) http.Handler {
+	return &server{
+		db:        db,
+		readState: readState,
+		verified:  make(map[string]verifiedFile),
+	}
+}
+
+type server struct {
+	db        *filedb.FileDB
+	readState storage.ReadState
+
+	l        sync.Mutex
+	verified map[string]verifiedFile
+}
+
+// verifiedFile identifies the version of a file that was checked against
+// [commitment].
+type verifiedFile struct {
+	commitment string
+	size       int64
+	modTime    time.Time
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	imageID, valType, err := parseQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, paid, err := storage.GetHashKeyTypeFromState(r.Context(), s.readState, imageID, valType)
+	if errors.Is(err, database.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	key := storage.DeployKey(imageID, valType)
+	path := filepath.Join(s.db.BaseDir(), key)
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.forget(path)
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// fileDB prefixes the artifact with its checksum
+	size := stat.Size() - consts.IDLen
+	if size < 0 {
+		http.Error(w, filedb.ErrCorrupt.Error(), http.StatusInternalServerError)
+		return
+	}
+	if uint64(size) > paid {
+		http.Error(w, fmt.Sprintf("%s: %d > %d bytes", ErrExceedsPaidSize, size, paid), http.StatusNotFound)
+		return
+	}
+	file := verifiedFile{commitment: c.String(), size: size, modTime: stat.ModTime()}
+	if !s.isVerified(path, file) {
+		if _, _, err := Read(r.Context(), s.db, s.readState, imageID, valType); err != nil {
+			switch {
+			case errors.Is(err, database.ErrNotFound):
+				http.NotFound(w, r)
+			case Refused(err):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		s.l.Lock()
+		s.verified[path] = file
+		s.l.Unlock()
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("ETag", strconv.Quote(file.commitment))
+	http.ServeContent(w, r, "", time.Time{}, io.NewSectionReader(f, consts.IDLen, size))
+}
+
+func (s *server) isVerified(path string, file verifiedFile) bool {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.verified[path] == file
+}
+
+func (s *server) forget(path string) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	delete(s.verified, path)
+}
@@ -74,6 +74,26 @@ func handleTx(tx *chain.Transaction, result *chain.Result) {
 			summaryStr = fmt.Sprintf("successfully verified miden proof of image id: %s", action.ImageID.String())
 		case *actions.PLONKY2:
 			summaryStr = fmt.Sprintf("successfully verified plonky2 proof of image id: %s", action.ImageID.String())
+		case *actions.Halo2:
+			summaryStr = fmt.Sprintf("successfully verified halo2 proof of image id: %s", action.ImageID.String())
+		case *actions.Groth16:
+			summaryStr = "successfully verified groth16 proof"
+		case *actions.OpenIVC:
+			summaryStr = fmt.Sprintf("opened ivc instance over image id: %s", action.ImageID.String())
+		case *actions.IVCStep:
+			summaryStr = fmt.Sprintf("requested verification of step %d of ivc instance: %s", action.Step, action.InstanceID.String())
+		case *actions.RecordIVCStep:
+			summaryStr = fmt.Sprintf("recorded step verified by %s in ivc instance: %s", action.TxID.String(), action.InstanceID.String())
+		case *actions.Cairo:
+			summaryStr = fmt.Sprintf("successfully verified cairo proof of image id: %s", action.ImageID.String())
+		case *actions.Noir:
+			summaryStr = fmt.Sprintf("successfully verified noir %s proof of image id: %s", consts.NoirFlavor(action.Flavor), action.ImageID.String())
+		case *actions.ClaimRewards:
+			summaryStr = fmt.Sprintf("settled %d requests, rewards -> %s", len(action.TxIDs), codec.MustAddressBech32(consts.HRP, action.To))
+		case *actions.CommitVote:
+			summaryStr = fmt.Sprintf("committed vote on request: %s", action.TxID.String())
+		case *actions.RevealCanary:
+			summaryStr = fmt.Sprintf("revealed canary request: %s", action.TxID.String())
 			// case *actions.Gnark:
 			// 	var ps string
 			// 	if action.ProvingSystem {
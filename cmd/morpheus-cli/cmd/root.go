@@ -151,7 +151,19 @@ func init() {
 		registerImageCmd,
 		broadcastCmd,
 		verifyCmd,
+		verifyGroth16Cmd,
 		verifyStatusCmd,
+		openIVCCmd,
+		ivcStepCmd,
+		recordIVCStepCmd,
+		ivcStatusCmd,
+		claimRewardsCmd,
+		participationCmd,
+		commitCanaryCmd,
+		revealCanaryCmd,
+		canaryStatsCmd,
+		verifierStatusCmd,
+		diskUsageCmd,
 	)
 	// spam
 	runSpamCmd.PersistentFlags().BoolVar(
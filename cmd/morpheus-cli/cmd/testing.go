@@ -2,11 +2,20 @@ package cmd
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/sausaging/hyper-pvzk/actions"
+	"github.com/sausaging/hyper-pvzk/commitment"
+	mconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/storage"
 	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/consts"
 	"github.com/sausaging/hypersdk/utils"
 	"github.com/spf13/cobra"
@@ -28,16 +37,38 @@ var registerCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		ps, err := handler.Root().PromptInt("proving sytem: sp1 -> 1, miden -> 2, risc0 -> 3, jolt -> 4, plonky2 -> 5 , gnark -> 6 ", consts.MaxInt)
+		ps, err := handler.Root().PromptInt("proving sytem: sp1 -> 4, miden -> 5, risc0 -> 6, gnark -> 7, jolt -> 8, plonky2 -> 9, halo2 -> 14, noir -> 15, cairo -> 17, nova -> 18", consts.MaxInt)
 		if err != nil {
 			return err
 		}
+		var programID []byte
+		switch uint8(ps) {
+		case mconsts.SP1ID, mconsts.RiscZeroID:
+			id, err := handler.Root().PromptString("program id (hex risc0 image id or sp1 vkey hash)", 1, consts.MaxInt)
+			if err != nil {
+				return err
+			}
+			programID, err = actions.ParseProgramID(id)
+			if err != nil {
+				return err
+			}
+		case mconsts.CairoID:
+			hash, err := handler.Root().PromptString("cairo program hash (hex)", 1, consts.MaxInt)
+			if err != nil {
+				return err
+			}
+			programID, err = actions.ParseCairoProgramHash(hash)
+			if err != nil {
+				return err
+			}
+		}
 		cont, err := handler.Root().PromptContinue()
 		if !cont || err != nil {
 			return err
 		}
 		_, _, err = sendAndWait(ctx, nil, &actions.Register{
 			ProovingSystem: uint64(ps),
+			ProgramID:      programID,
 		}, cli, bcli, ws, factory, true)
 		return err
 	},
@@ -59,18 +90,37 @@ var registerImageCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		rootHash, err := handler.Root().PromptString("root hash", 1, consts.MaxInt)
+		fileName, err := handler.Root().PromptString("artifact file name", 1, consts.MaxInt)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(fileName)
 		if err != nil {
 			return err
 		}
+		algorithms := commitment.Algorithms()
+		for i, algorithm := range algorithms {
+			utils.Outf("{{yellow}}%d:{{/}} %s\n", i, algorithm)
+		}
+		choice, err := handler.Root().PromptChoice("commitment algorithm", len(algorithms))
+		if err != nil {
+			return err
+		}
+		c, err := commitment.Compute(algorithms[choice], data)
+		if err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}commitment:{{/}} %s {{yellow}}size:{{/}} %d bytes\n", c, len(data))
 		cont, err := handler.Root().PromptContinue()
 		if !cont || err != nil {
 			return err
 		}
 		_, _, err = sendAndWait(ctx, nil, &actions.RegisterImage{
-			ImageID:  imageID,
-			ValType:  uint64(valType),
-			RootHash: rootHash,
+			ImageID:      imageID,
+			ValType:      uint64(valType),
+			Algorithm:    uint8(c.Algorithm),
+			Digest:       c.Digest,
+			ArtifactSize: uint64(len(data)),
 		}, cli, bcli, ws, factory, true)
 		return err
 	},
@@ -80,7 +130,7 @@ var broadcastCmd = &cobra.Command{
 	Use: "broadcast",
 	RunE: func(*cobra.Command, []string) error {
 		ctx := context.Background()
-		_, _, _, cli, _, _, err := handler.DefaultActor()
+		_, _, _, _, bcli, _, err := handler.DefaultActor()
 		if err != nil {
 			return err
 		}
@@ -112,7 +162,11 @@ var broadcastCmd = &cobra.Command{
 		}
 
 		// @todo we are naive broadcasting full file. change this to 100kib blob based broadcast
-		cli.SubmitChunks(ctx, imageID, uint16(valType), uint16(chunkIndex), data)
+		remaining, err := bcli.SubmitChunk(ctx, imageID, uint16(valType), uint16(chunkIndex), data)
+		if err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}paid bytes remaining:{{/}} %d\n", remaining)
 		return nil
 	},
 }
@@ -134,7 +188,7 @@ var verifyCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		verifyType, err := handler.Root().PromptInt("verification type: 1 -> SP1, 2 -> Miden, 3 -> Risc0, 4 -> Jolt, 5 -> Plonky2, 6 -> Gnark", 10)
+		verifyType, err := handler.Root().PromptInt("verification type: 1 -> SP1, 2 -> Miden, 3 -> Risc0, 4 -> Jolt, 5 -> Plonky2, 6 -> Gnark, 7 -> Halo2, 8 -> Noir, 9 -> Cairo", 10)
 		if err != nil {
 			return err
 		}
@@ -144,35 +198,47 @@ var verifyCmd = &cobra.Command{
 		}
 		var action chain.Action
 		if verifyType == 1 {
+			segments, err := promptSegments("shards")
+			if err != nil {
+				return err
+			}
 			action = &actions.SP1{
-				ImageID:       imageID,
-				ProofValType:  uint64(valType),
-				TimeOutBlocks: uint64(timeOutBlocks),
+				ImageID:         imageID,
+				ProofValType:    uint64(valType),
+				SegmentValTypes: segments,
+				TimeOutBlocks:   uint64(timeOutBlocks),
 			}
 		} else if verifyType == 2 {
-			codeFrontEnd, err := handler.Root().PromptString("codeFrontEnd", 1, consts.MaxInt)
+			code, err := promptMidenSource("code")
 			if err != nil {
 				return err
 			}
-			inputsFrontEnd, err := handler.Root().PromptString("inputsFrontEnd", 1, consts.MaxInt)
+			inputs, err := promptMidenSource("inputs")
 			if err != nil {
 				return err
 			}
-			outputsFrontEnd, err := handler.Root().PromptString("outputsForntEnd", 1, consts.MaxInt)
+			outputs, err := promptMidenSource("outputs")
 			if err != nil {
 				return err
 			}
 			action = &actions.Miden{
 				ImageID:         imageID,
 				ProofValType:    uint64(valType),
-				CodeFrontEnd:    codeFrontEnd,
-				InputsFrontEnd:  inputsFrontEnd,
-				OutputsFrontEnd: outputsFrontEnd,
+				CodeValType:     code.ValType,
+				InputsValType:   inputs.ValType,
+				OutputsValType:  outputs.ValType,
+				CodeFrontEnd:    code.FrontEnd,
+				InputsFrontEnd:  inputs.FrontEnd,
+				OutputsFrontEnd: outputs.FrontEnd,
 				TimeOutBlocks:   uint64(timeOutBlocks),
 			}
 
 		} else if verifyType == 3 {
-			riscZeroImageID, err := handler.Root().PromptString("risc zero image id", 1, consts.MaxInt)
+			riscZeroImageID, err := handler.Root().PromptString("risc zero image id (optional, checked against the pinned one)", 0, consts.MaxInt)
+			if err != nil {
+				return err
+			}
+			segments, err := promptSegments("segments")
 			if err != nil {
 				return err
 			}
@@ -181,6 +247,7 @@ var verifyCmd = &cobra.Command{
 				ImageID:         imageID,
 				ProofValType:    uint64(valType),
 				RiscZeroImageID: riscZeroImageID,
+				SegmentValTypes: segments,
 				TimeOutBlocks:   uint64(timeOutBlocks),
 			}
 		} else if verifyType == 4 {
@@ -207,6 +274,54 @@ var verifyCmd = &cobra.Command{
 			}
 		} else if verifyType == 6 {
 
+		} else if verifyType == 7 {
+			verifyingKeyValType, err := handler.Root().PromptInt("verifying key val type", int(consts.MaxUint16))
+			if err != nil {
+				return err
+			}
+			instancesValType, err := handler.Root().PromptInt("instances val type", int(consts.MaxUint16))
+			if err != nil {
+				return err
+			}
+			action = &actions.Halo2{
+				ImageID:             imageID,
+				ProofValType:        uint64(valType),
+				VerifyingKeyValType: uint64(verifyingKeyValType),
+				InstancesValType:    uint64(instancesValType),
+				TimeOutBlocks:       uint64(timeOutBlocks),
+			}
+		} else if verifyType == 8 {
+			flavor, err := handler.Root().PromptInt("flavor: 1 -> UltraPlonk, 2 -> UltraHonk", int(mconsts.NoirUltraHonk))
+			if err != nil {
+				return err
+			}
+			verifyingKeyValType, err := handler.Root().PromptInt("verification key val type", int(consts.MaxUint16))
+			if err != nil {
+				return err
+			}
+			publicInputsValType, err := handler.Root().PromptInt("public inputs val type", int(consts.MaxUint16))
+			if err != nil {
+				return err
+			}
+			action = &actions.Noir{
+				ImageID:             imageID,
+				Flavor:              uint8(flavor),
+				ProofValType:        uint64(valType),
+				VerifyingKeyValType: uint64(verifyingKeyValType),
+				PublicInputsValType: uint64(publicInputsValType),
+				TimeOutBlocks:       uint64(timeOutBlocks),
+			}
+		} else if verifyType == 9 {
+			programHash, err := handler.Root().PromptString("cairo program hash (optional, checked against the pinned one)", 0, consts.MaxInt)
+			if err != nil {
+				return err
+			}
+			action = &actions.Cairo{
+				ImageID:       imageID,
+				ProofValType:  uint64(valType),
+				ProgramHash:   programHash,
+				TimeOutBlocks: uint64(timeOutBlocks),
+			}
 		} else {
 			return ErrInvalidVerificationType
 		}
@@ -220,6 +335,180 @@ var verifyCmd = &cobra.Command{
 	},
 }
 
+// verifyGroth16Cmd verifies a snarkjs Groth16 proof on chain, without any
+// verifier involved.
+var verifyGroth16Cmd = &cobra.Command{
+	Use: "verify-groth16",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, ws, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		var files [3]string
+		for i, name := range []string{"proof.json", "verification_key.json", "public.json"} {
+			fileName, err := handler.Root().PromptString(name+" file name", 1, consts.MaxInt)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(fileName)
+			if err != nil {
+				return err
+			}
+			files[i] = string(data)
+		}
+		cont, err := handler.Root().PromptContinue()
+		if !cont || err != nil {
+			return err
+		}
+		_, _, err = sendAndWait(ctx, nil, &actions.Groth16{
+			Proof:        files[0],
+			VerifyingKey: files[1],
+			PublicInputs: files[2],
+		}, cli, bcli, ws, factory, true)
+		return err
+	},
+}
+
+var openIVCCmd = &cobra.Command{
+	Use: "open-ivc",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, ws, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		imageID, err := handler.Root().PromptID("nova image id")
+		if err != nil {
+			return err
+		}
+		initialState, err := handler.Root().PromptString("initial state (hex)", 0, 2*storage.MaxIVCInitialStateLen)
+		if err != nil {
+			return err
+		}
+		z0, err := hex.DecodeString(strings.TrimPrefix(initialState, "0x"))
+		if err != nil {
+			return err
+		}
+		cont, err := handler.Root().PromptContinue()
+		if !cont || err != nil {
+			return err
+		}
+		_, txID, err := sendAndWait(ctx, nil, &actions.OpenIVC{
+			ImageID:      imageID,
+			InitialState: z0,
+		}, cli, bcli, ws, factory, true)
+		if err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}instance id:{{/}} %s\n", txID)
+		return nil
+	},
+}
+
+var ivcStepCmd = &cobra.Command{
+	Use: "ivc-step",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, ws, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		instanceID, err := handler.Root().PromptID("instance id")
+		if err != nil {
+			return err
+		}
+		ivc, err := bcli.IVCStatus(ctx, instanceID)
+		if err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}latest verified step:{{/}} %d\n", ivc.Step)
+		step, err := handler.Root().PromptInt("step", consts.MaxInt)
+		if err != nil {
+			return err
+		}
+		proofValType, err := handler.Root().PromptInt("proof val type", int(consts.MaxUint16))
+		if err != nil {
+			return err
+		}
+		verifyingKeyValType, err := handler.Root().PromptInt("verifying key val type", int(consts.MaxUint16))
+		if err != nil {
+			return err
+		}
+		timeOutBlocks, err := handler.Root().PromptInt("time out blocks", int(consts.MaxUint16))
+		if err != nil {
+			return err
+		}
+		cont, err := handler.Root().PromptContinue()
+		if !cont || err != nil {
+			return err
+		}
+		_, _, err = sendAndWait(ctx, nil, &actions.IVCStep{
+			InstanceID:          instanceID,
+			ImageID:             ivc.ImageID,
+			Step:                uint64(step),
+			ProofValType:        uint64(proofValType),
+			VerifyingKeyValType: uint64(verifyingKeyValType),
+			TimeOutBlocks:       uint64(timeOutBlocks),
+		}, cli, bcli, ws, factory, true)
+		return err
+	},
+}
+
+var recordIVCStepCmd = &cobra.Command{
+	Use: "record-ivc-step",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, ws, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		instanceID, err := handler.Root().PromptID("instance id")
+		if err != nil {
+			return err
+		}
+		txID, err := handler.Root().PromptID("tx id of ivc step")
+		if err != nil {
+			return err
+		}
+		cont, err := handler.Root().PromptContinue()
+		if !cont || err != nil {
+			return err
+		}
+		_, _, err = sendAndWait(ctx, nil, &actions.RecordIVCStep{
+			InstanceID: instanceID,
+			TxID:       txID,
+		}, cli, bcli, ws, factory, true)
+		return err
+	},
+}
+
+var ivcStatusCmd = &cobra.Command{
+	Use: "ivc-status",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, _, _, bcli, _, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		instanceID, err := handler.Root().PromptID("instance id")
+		if err != nil {
+			return err
+		}
+		ivc, err := bcli.IVCStatus(ctx, instanceID)
+		if err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}image id:{{/}} %s\n", ivc.ImageID)
+		utils.Outf("{{yellow}}owner:{{/}} %s\n", ivc.Owner)
+		utils.Outf("{{yellow}}step:{{/}} %d\n", ivc.Step)
+		if len(ivc.StateCommitment) > 0 {
+			utils.Outf("{{yellow}}state commitment:{{/}} %s\n", ivc.StateCommitment)
+		}
+		return nil
+	},
+}
+
 var verifyStatusCmd = &cobra.Command{
 	Use: "verify-status",
 	RunE: func(*cobra.Command, []string) error {
@@ -240,7 +529,289 @@ var verifyStatusCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		utils.Outf("status: %s", status)
+		utils.Outf("status: %t\n", status)
+		outputsDigest, err := bcli.VerifyOutputs(ctx, txID)
+		if err != nil {
+			return err
+		}
+		if len(outputsDigest) > 0 {
+			utils.Outf("{{yellow}}outputs digest:{{/}} %x\n", outputsDigest)
+		}
+		outcomes, err := bcli.VerifyOutcomes(ctx, txID)
+		if err != nil {
+			return err
+		}
+		for outcome, count := range outcomes {
+			if count > 0 {
+				utils.Outf("{{yellow}}%s:{{/}} %d\n", outcome, count)
+			}
+		}
+		return nil
+	},
+}
+
+var claimRewardsCmd = &cobra.Command{
+	Use: "claim-rewards",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, ws, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		recipient, err := handler.Root().PromptAddress("recipient")
+		if err != nil {
+			return err
+		}
+		count, err := handler.Root().PromptInt("number of requests to settle", actions.MaxClaimRequests)
+		if err != nil {
+			return err
+		}
+		txIDs := make([]ids.ID, count)
+		for i := range txIDs {
+			txIDs[i], err = handler.Root().PromptID("tx id of verify")
+			if err != nil {
+				return err
+			}
+		}
+		cont, err := handler.Root().PromptContinue()
+		if !cont || err != nil {
+			return err
+		}
+		_, _, err = sendAndWait(ctx, nil, &actions.ClaimRewards{
+			To:    recipient,
+			TxIDs: txIDs,
+		}, cli, bcli, ws, factory, true)
+		return err
+	},
+}
+
+var participationCmd = &cobra.Command{
+	Use: "participation",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, priv, _, _, bcli, _, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		p, err := bcli.Participation(ctx, codec.MustAddressBech32(mconsts.HRP, priv.Address))
+		if err != nil {
+			return err
+		}
+		utils.Outf(
+			"{{yellow}}requests eligible:{{/}} %d {{yellow}}votes cast:{{/}} %d {{yellow}}votes agreeing:{{/}} %d\n",
+			p.RequestsEligible,
+			p.VotesCast,
+			p.VotesAgreeing,
+		)
+		utils.Outf(
+			"{{yellow}}rewards:{{/}} %s %s {{yellow}}claimed:{{/}} %s %s\n",
+			utils.FormatBalance(p.Rewards, mconsts.Decimals),
+			mconsts.Symbol,
+			utils.FormatBalance(p.Claimed, mconsts.Decimals),
+			mconsts.Symbol,
+		)
+		utils.Outf(
+			"{{yellow}}canaries failed:{{/}} %d {{yellow}}penalties:{{/}} %s %s {{yellow}}owed:{{/}} %s %s\n",
+			p.CanariesFailed,
+			utils.FormatBalance(p.Penalties, mconsts.Decimals),
+			mconsts.Symbol,
+			utils.FormatBalance(p.PenaltiesOwed, mconsts.Decimals),
+			mconsts.Symbol,
+		)
+		return nil
+	},
+}
+
+var commitCanaryCmd = &cobra.Command{
+	Use: "commit-canary",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, ws, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		// the canary request must be signed, but not yet issued
+		txID, err := handler.Root().PromptID("tx id of canary request")
+		if err != nil {
+			return err
+		}
+		salt := make([]byte, actions.MaxSaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		cont, err := handler.Root().PromptContinue()
+		if !cont || err != nil {
+			return err
+		}
+		_, _, err = sendAndWait(ctx, nil, &actions.CommitCanary{
+			Commitment: actions.CanaryCommitment(txID, salt),
+		}, cli, bcli, ws, factory, true)
+		if err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}salt (keep until reveal):{{/}} %s\n", hex.EncodeToString(salt))
 		return nil
 	},
 }
+
+var revealCanaryCmd = &cobra.Command{
+	Use: "reveal-canary",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, ws, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		txID, err := handler.Root().PromptID("tx id of canary request")
+		if err != nil {
+			return err
+		}
+		saltHex, err := handler.Root().PromptString("salt", 1, 2*actions.MaxSaltLen)
+		if err != nil {
+			return err
+		}
+		salt, err := hex.DecodeString(saltHex)
+		if err != nil {
+			return err
+		}
+		count, err := handler.Root().PromptInt("number of voters to check", actions.MaxCanaryVoters)
+		if err != nil {
+			return err
+		}
+		voters := make([]codec.Address, count)
+		for i := range voters {
+			voters[i], err = handler.Root().PromptAddress("voter")
+			if err != nil {
+				return err
+			}
+		}
+		cont, err := handler.Root().PromptContinue()
+		if !cont || err != nil {
+			return err
+		}
+		_, _, err = sendAndWait(ctx, nil, &actions.RevealCanary{
+			TxID:   txID,
+			Salt:   salt,
+			Voters: voters,
+		}, cli, bcli, ws, factory, true)
+		return err
+	},
+}
+
+var canaryStatsCmd = &cobra.Command{
+	Use: "canary-stats",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, _, _, bcli, _, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		stats, err := bcli.CanaryStats(ctx)
+		if err != nil {
+			return err
+		}
+		utils.Outf(
+			"{{yellow}}canaries revealed:{{/}} %d {{yellow}}lazy votes:{{/}} %d {{yellow}}penalties:{{/}} %s %s\n",
+			stats.Revealed,
+			stats.LazyVotes,
+			utils.FormatBalance(stats.Penalties, mconsts.Decimals),
+			mconsts.Symbol,
+		)
+		return nil
+	},
+}
+
+var verifierStatusCmd = &cobra.Command{
+	Use: "verifier-status",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, _, _, bcli, _, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		status, err := bcli.VerifierStatus(ctx)
+		if err != nil {
+			return err
+		}
+		utils.Outf(
+			"{{yellow}}verifying:{{/}} %t {{yellow}}hub discovered:{{/}} %t {{yellow}}healthy endpoints:{{/}} %d/%d {{yellow}}protocol version:{{/}} %d {{yellow}}unverified:{{/}} %d\n",
+			status.Verifying,
+			status.Discovered,
+			status.HealthyEndpoints,
+			status.Endpoints,
+			status.ProtocolVersion,
+			status.Unverified,
+		)
+		return nil
+	},
+}
+
+var diskUsageCmd = &cobra.Command{
+	Use: "disk-usage",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, _, _, bcli, _, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+		usage, err := bcli.DiskUsage(ctx)
+		if err != nil {
+			return err
+		}
+		utils.Outf(
+			"{{yellow}}artifacts:{{/}} %d files, %d bytes {{yellow}}tracked:{{/}} %d proofs, %d image artifacts {{yellow}}collected:{{/}} %d files, %d bytes {{yellow}}archival:{{/}} %t\n",
+			usage.Files,
+			usage.Bytes,
+			usage.TrackedProofs,
+			usage.TrackedImages,
+			usage.CollectedFiles,
+			usage.CollectedBytes,
+			usage.Archival,
+		)
+		return nil
+	},
+}
+
+type midenSource struct {
+	ValType  uint64
+	FrontEnd string
+}
+
+// promptMidenSource prompts for the front end of [name] when it is sent
+// inline, or for the val type it is registered under.
+func promptMidenSource(name string) (*midenSource, error) {
+	inline, err := handler.Root().PromptBool("send " + name + " inline")
+	if err != nil {
+		return nil, err
+	}
+	if inline {
+		frontEnd, err := handler.Root().PromptString(name+" front end", 1, consts.MaxInt)
+		if err != nil {
+			return nil, err
+		}
+		return &midenSource{FrontEnd: frontEnd}, nil
+	}
+	valType, err := handler.Root().PromptInt(name+" val type", int(consts.MaxUint16))
+	if err != nil {
+		return nil, err
+	}
+	return &midenSource{ValType: uint64(valType)}, nil
+}
+
+// promptSegments prompts for the val types of the [name] following the proof
+// val type, in execution order, for proofs split in more than one.
+func promptSegments(name string) ([]uint16, error) {
+	count, err := handler.Root().PromptInt("number of "+name+" following the proof (0 if not split)", actions.MaxSegments-1)
+	if err != nil {
+		return nil, err
+	}
+	var segments []uint16
+	for i := 1; i <= count; i++ {
+		valType, err := handler.Root().PromptInt(fmt.Sprintf("val type of %s %d", name, i), int(consts.MaxUint16))
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, uint16(valType))
+	}
+	return segments, nil
+}
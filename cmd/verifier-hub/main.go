@@ -0,0 +1,126 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// verifier-hub serves the reference verifier hub, so that a node can be run
+// without the Rust hub and verifiers.
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/hub"
+	"github.com/spf13/cobra"
+)
+
+var (
+	port         string
+	listenerPort string
+	nodeURI      string
+	policy       string
+	delay        time.Duration
+	proofs       []string
+	versions     []uint
+
+	errVerifierDown = errors.New("verifier is down")
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "verifier-hub",
+	Short: "Reference verifier hub with scriptable verdicts",
+	RunE:  runFunc,
+}
+
+func init() {
+	rootCmd.Flags().StringVar(&port, "port", "9000", "port to serve the hub on")
+	rootCmd.Flags().StringVar(&listenerPort, "listener-port", "9001", "port the node listens for results on")
+	rootCmd.Flags().StringVar(&nodeURI, "node-uri", "", "uri to submit results to, defaults to the local listener port")
+	rootCmd.Flags().StringVar(&policy, "policy", "valid", "verdict for every proof: valid, invalid, error or any outcome name")
+	rootCmd.Flags().DurationVar(&delay, "delay", 0, "delay before submitting results")
+	rootCmd.Flags().StringSliceVar(&proofs, "proof", nil, "sha256:outcome of a proof to answer for, overriding the policy")
+	rootCmd.Flags().UintSliceVar(&versions, "protocol-versions", nil, "protocol versions to advertise, defaults to every supported version")
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "verifier-hub failed %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func parsePolicy() (hub.Policy, error) {
+	var p hub.Policy
+	switch policy {
+	case "valid":
+		p = hub.AlwaysValid()
+	case "invalid":
+		p = hub.AlwaysInvalid()
+	case "error":
+		p = hub.Error(errVerifierDown)
+	default:
+		o, err := consts.ParseOutcome(policy)
+		if err != nil {
+			return nil, err
+		}
+		p = hub.Outcome(o)
+	}
+	if len(proofs) > 0 {
+		outcomes := make(map[[sha256.Size]byte]consts.Outcome, len(proofs))
+		for _, proof := range proofs {
+			hexHash, name, ok := strings.Cut(proof, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid proof %q, expected sha256:outcome", proof)
+			}
+			var digest [sha256.Size]byte
+			b, err := hex.DecodeString(hexHash)
+			if err != nil || len(b) != sha256.Size {
+				return nil, fmt.Errorf("invalid proof hash %q", hexHash)
+			}
+			copy(digest[:], b)
+			o, err := consts.ParseOutcome(name)
+			if err != nil {
+				return nil, err
+			}
+			outcomes[digest] = o
+		}
+		p = hub.HashBased(outcomes, p)
+	}
+	if delay > 0 {
+		p = hub.Delay(delay, p)
+	}
+	return p, nil
+}
+
+func runFunc(*cobra.Command, []string) error {
+	p, err := parsePolicy()
+	if err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	config := &hub.Config{
+		ListenerPort: listenerPort,
+		NodeURI:      nodeURI,
+		Policy:       p,
+	}
+	for _, v := range versions {
+		config.Versions = append(config.Versions, uint32(v))
+	}
+	fmt.Printf("serving verifier hub on %s, submitting results to port %s\n", l.Addr(), listenerPort)
+	return hub.New(config).Serve(ctx, l)
+}
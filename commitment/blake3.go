@@ -0,0 +1,190 @@
+package commitment
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// blake3Sum256 is a port of the BLAKE3 reference implementation, restricted
+// to unkeyed hashing with a 32 byte output.
+
+const (
+	blake3BlockLen = 64
+	blake3ChunkLen = 1024
+
+	blake3ChunkStart = 1 << 0
+	blake3ChunkEnd   = 1 << 1
+	blake3Parent     = 1 << 2
+	blake3Root       = 1 << 3
+)
+
+var blake3IV = [8]uint32{
+	0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A,
+	0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19,
+}
+
+var blake3Permutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+func blake3G(s *[16]uint32, a, b, c, d int, mx, my uint32) {
+	s[a] += s[b] + mx
+	s[d] = bits.RotateLeft32(s[d]^s[a], -16)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -12)
+	s[a] += s[b] + my
+	s[d] = bits.RotateLeft32(s[d]^s[a], -8)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -7)
+}
+
+func blake3Round(s *[16]uint32, m *[16]uint32) {
+	// columns
+	blake3G(s, 0, 4, 8, 12, m[0], m[1])
+	blake3G(s, 1, 5, 9, 13, m[2], m[3])
+	blake3G(s, 2, 6, 10, 14, m[4], m[5])
+	blake3G(s, 3, 7, 11, 15, m[6], m[7])
+	// diagonals
+	blake3G(s, 0, 5, 10, 15, m[8], m[9])
+	blake3G(s, 1, 6, 11, 12, m[10], m[11])
+	blake3G(s, 2, 7, 8, 13, m[12], m[13])
+	blake3G(s, 3, 4, 9, 14, m[14], m[15])
+}
+
+func blake3Compress(cv *[8]uint32, block *[16]uint32, counter uint64, blockLen uint32, flags uint32) [16]uint32 {
+	s := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		blake3IV[0], blake3IV[1], blake3IV[2], blake3IV[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+	m := *block
+	for r := 0; r < 7; r++ {
+		blake3Round(&s, &m)
+		if r < 6 {
+			var permuted [16]uint32
+			for i, j := range blake3Permutation {
+				permuted[i] = m[j]
+			}
+			m = permuted
+		}
+	}
+	for i := 0; i < 8; i++ {
+		s[i] ^= s[i+8]
+		s[i+8] ^= cv[i]
+	}
+	return s
+}
+
+func blake3Words(block []byte) (w [16]uint32) {
+	var b [blake3BlockLen]byte
+	copy(b[:], block)
+	for i := range w {
+		w[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+	return w
+}
+
+func blake3First8(s [16]uint32) (cv [8]uint32) {
+	copy(cv[:], s[:8])
+	return cv
+}
+
+// blake3Output is a compression not yet run, so that it can either be
+// chained or turned into root output.
+type blake3Output struct {
+	cv       [8]uint32
+	block    [16]uint32
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+func (o *blake3Output) chainingValue() [8]uint32 {
+	return blake3First8(blake3Compress(&o.cv, &o.block, o.counter, o.blockLen, o.flags))
+}
+
+func (o *blake3Output) root() (out [32]byte) {
+	s := blake3Compress(&o.cv, &o.block, 0, o.blockLen, o.flags|blake3Root)
+	for i := 0; i < 8; i++ {
+		binary.LittleEndian.PutUint32(out[4*i:], s[i])
+	}
+	return out
+}
+
+type blake3ChunkState struct {
+	cv               [8]uint32
+	counter          uint64
+	block            [blake3BlockLen]byte
+	blockLen         int
+	blocksCompressed int
+}
+
+func (c *blake3ChunkState) len() int {
+	return blake3BlockLen*c.blocksCompressed + c.blockLen
+}
+
+func (c *blake3ChunkState) startFlag() uint32 {
+	if c.blocksCompressed == 0 {
+		return blake3ChunkStart
+	}
+	return 0
+}
+
+func (c *blake3ChunkState) update(input []byte) {
+	for len(input) > 0 {
+		// only compress a full block once more input follows, the last one
+		// is compressed by [output]
+		if c.blockLen == blake3BlockLen {
+			w := blake3Words(c.block[:])
+			c.cv = blake3First8(blake3Compress(&c.cv, &w, c.counter, blake3BlockLen, c.startFlag()))
+			c.blocksCompressed++
+			c.block = [blake3BlockLen]byte{}
+			c.blockLen = 0
+		}
+		n := copy(c.block[c.blockLen:], input)
+		c.blockLen += n
+		input = input[n:]
+	}
+}
+
+func (c *blake3ChunkState) output() *blake3Output {
+	return &blake3Output{
+		cv:       c.cv,
+		block:    blake3Words(c.block[:c.blockLen]),
+		counter:  c.counter,
+		blockLen: uint32(c.blockLen),
+		flags:    c.startFlag() | blake3ChunkEnd,
+	}
+}
+
+func blake3ParentOutput(left, right [8]uint32) *blake3Output {
+	o := &blake3Output{cv: blake3IV, blockLen: blake3BlockLen, flags: blake3Parent}
+	copy(o.block[:8], left[:])
+	copy(o.block[8:], right[:])
+	return o
+}
+
+func blake3Sum256(data []byte) [32]byte {
+	chunk := &blake3ChunkState{cv: blake3IV}
+	var stack [][8]uint32
+	for len(data) > 0 {
+		if chunk.len() == blake3ChunkLen {
+			cv := chunk.output().chainingValue()
+			total := chunk.counter + 1
+			// merge the subtrees completed by this chunk
+			for total&1 == 0 {
+				cv = blake3ParentOutput(stack[len(stack)-1], cv).chainingValue()
+				stack = stack[:len(stack)-1]
+				total >>= 1
+			}
+			stack = append(stack, cv)
+			chunk = &blake3ChunkState{cv: blake3IV, counter: chunk.counter + 1}
+		}
+		n := min(blake3ChunkLen-chunk.len(), len(data))
+		chunk.update(data[:n])
+		data = data[n:]
+	}
+	out := chunk.output()
+	for i := len(stack) - 1; i >= 0; i-- {
+		out = blake3ParentOutput(stack[i], out.chainingValue())
+	}
+	return out.root()
+}
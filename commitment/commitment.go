@@ -0,0 +1,182 @@
+// Package commitment defines the algorithm-tagged digests artifacts are
+// registered with, so that nodes can recompute and check them against the
+// artifacts they store.
+package commitment
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Algorithm tags the hash function a digest is computed with.
+type Algorithm uint8
+
+const (
+	SHA256 Algorithm = iota + 1
+	Keccak256
+	BLAKE3
+	// MerkleSHA256 is the root of a binary SHA-256 Merkle tree over the
+	// [MerkleChunkSize] chunks of an artifact, see [MerkleRoot].
+	MerkleSHA256
+)
+
+// MaxDigestLen is the length of the longest digest of any algorithm.
+const MaxDigestLen = 32
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown commitment algorithm")
+	ErrDigestLength     = errors.New("invalid digest length")
+	ErrMismatch         = errors.New("artifact doesn't match commitment")
+)
+
+var names = map[Algorithm]string{
+	SHA256:       "sha256",
+	Keccak256:    "keccak256",
+	BLAKE3:       "blake3",
+	MerkleSHA256: "merkle-sha256",
+}
+
+// Algorithms returns the supported algorithms.
+func Algorithms() []Algorithm {
+	return []Algorithm{SHA256, Keccak256, BLAKE3, MerkleSHA256}
+}
+
+func (a Algorithm) String() string {
+	if name, ok := names[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("algorithm(%d)", uint8(a))
+}
+
+// ParseAlgorithm returns the algorithm named [name].
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for a, n := range names {
+		if n == name {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+}
+
+// DigestLen returns the length of the digests of [a], 0 if it is unknown.
+func (a Algorithm) DigestLen() int {
+	switch a {
+	case SHA256, Keccak256, BLAKE3, MerkleSHA256:
+		return 32
+	default:
+		return 0
+	}
+}
+
+// Sum computes the digest of [data] with [a].
+func (a Algorithm) Sum(data []byte) ([]byte, error) {
+	switch a {
+	case SHA256:
+		d := sha256.Sum256(data)
+		return d[:], nil
+	case Keccak256:
+		h := sha3.NewLegacyKeccak256()
+		h.Write(data)
+		return h.Sum(nil), nil
+	case BLAKE3:
+		d := blake3Sum256(data)
+		return d[:], nil
+	case MerkleSHA256:
+		d := MerkleRoot(data)
+		return d[:], nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownAlgorithm, a)
+	}
+}
+
+// Commitment is a digest of an artifact tagged with the algorithm it is
+// computed with.
+type Commitment struct {
+	Algorithm Algorithm
+	Digest    []byte
+}
+
+// New returns the commitment to [digest] computed with [algorithm], checking
+// that the algorithm is known and the digest has its length.
+func New(algorithm Algorithm, digest []byte) (*Commitment, error) {
+	c := &Commitment{Algorithm: algorithm, Digest: digest}
+	if err := c.Verify(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Compute commits to [data] with [algorithm].
+func Compute(algorithm Algorithm, data []byte) (*Commitment, error) {
+	digest, err := algorithm.Sum(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Commitment{Algorithm: algorithm, Digest: digest}, nil
+}
+
+// Verify checks that the algorithm of [c] is known and its digest has the
+// length of the algorithm.
+func (c *Commitment) Verify() error {
+	n := c.Algorithm.DigestLen()
+	if n == 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownAlgorithm, c.Algorithm)
+	}
+	if len(c.Digest) != n {
+		return fmt.Errorf("%w: %s digests are %d bytes, found %d", ErrDigestLength, c.Algorithm, n, len(c.Digest))
+	}
+	return nil
+}
+
+// Check recomputes the digest of [data] and compares it to [c].
+func (c *Commitment) Check(data []byte) error {
+	digest, err := c.Algorithm.Sum(data)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(digest, c.Digest) {
+		return fmt.Errorf("%w: %s %x, found %x", ErrMismatch, c.Algorithm, c.Digest, digest)
+	}
+	return nil
+}
+
+// Bytes encodes [c] as its algorithm tag followed by its digest.
+func (c *Commitment) Bytes() []byte {
+	return append([]byte{byte(c.Algorithm)}, c.Digest...)
+}
+
+// FromBytes decodes a commitment encoded with [Commitment.Bytes].
+func FromBytes(b []byte) (*Commitment, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("%w: empty commitment", ErrDigestLength)
+	}
+	return New(Algorithm(b[0]), b[1:])
+}
+
+// String formats [c] as <algorithm>:<hex digest>.
+func (c *Commitment) String() string {
+	return c.Algorithm.String() + ":" + hex.EncodeToString(c.Digest)
+}
+
+// Parse parses a commitment formatted with [Commitment.String].
+func Parse(s string) (*Commitment, error) {
+	name, digest, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("expected <algorithm>:<hex digest>, found %q", s)
+	}
+	algorithm, err := ParseAlgorithm(name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(digest)
+	if err != nil {
+		return nil, err
+	}
+	return New(algorithm, b)
+}
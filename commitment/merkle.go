@@ -0,0 +1,46 @@
+package commitment
+
+import "crypto/sha256"
+
+// MerkleChunkSize is the size of the chunks [MerkleRoot] hashes as leaves.
+const MerkleChunkSize = 64 * 1024
+
+// Domain separation of leaves and inner nodes, as in RFC 6962.
+const (
+	leafPrefix = 0x0
+	nodePrefix = 0x1
+)
+
+// MerkleRoot returns the root of the binary SHA-256 Merkle tree over the
+// [MerkleChunkSize] chunks of [data], the last of which may be shorter. The
+// tree is built as in RFC 6962: leaves are hashed as 0x00 || chunk, inner
+// nodes as 0x01 || left || right, and the left subtree of a node holding n
+// leaves holds the largest power of two smaller than n. Empty data has the
+// root of a single empty chunk.
+func MerkleRoot(data []byte) [sha256.Size]byte {
+	n := (len(data) + MerkleChunkSize - 1) / MerkleChunkSize
+	if n == 0 {
+		return sha256.Sum256([]byte{leafPrefix})
+	}
+	leaves := make([][sha256.Size]byte, n)
+	for i := range leaves {
+		chunk := data[i*MerkleChunkSize : min((i+1)*MerkleChunkSize, len(data))]
+		leaves[i] = sha256.Sum256(append([]byte{leafPrefix}, chunk...))
+	}
+	return merkleRoot(leaves)
+}
+
+func merkleRoot(leaves [][sha256.Size]byte) [sha256.Size]byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	left, right := merkleRoot(leaves[:k]), merkleRoot(leaves[k:])
+	node := make([]byte, 0, 1+2*sha256.Size)
+	node = append(node, nodePrefix)
+	node = append(node, left[:]...)
+	return sha256.Sum256(append(node, right[:]...))
+}
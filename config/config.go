@@ -5,6 +5,7 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -12,7 +13,9 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/profiler"
+	"github.com/sausaging/hyper-pvzk/artifacts"
 	"github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/protocol"
 	"github.com/sausaging/hyper-pvzk/requester"
 	"github.com/sausaging/hyper-pvzk/version"
 	"github.com/sausaging/hypersdk/codec"
@@ -23,10 +26,14 @@ import (
 
 var _ vm.Config = (*Config)(nil)
 
+var ErrHubUnavailable = errors.New("hub is not ready")
+
 const (
 	defaultContinuousProfilerFrequency = 1 * time.Minute
 	defaultContinuousProfilerMaxFiles  = 10
 	defaultStoreTransactions           = true
+	defaultProofRetentionBlocks        = 100
+	defaultGCInterval                  = time.Minute
 )
 
 type Config struct {
@@ -59,13 +66,37 @@ type Config struct {
 	LogLevel          logging.Level `json:"logLevel"`
 	HubPorturi        string        `json:"hubPorturi"`
 	ValPrivKey        string        `json:"valPrivKey"`
+	// VerifierEndpoints replace the verifier discovered from the hub
+	VerifierEndpoints []*requester.EndpointConfig `json:"verifierEndpoints"`
+	// ArtifactTransport is how artifacts are sent to verifiers: by "path" to
+	// verifiers sharing the node's filesystem, by "content" or by "url".
+	ArtifactTransport string `json:"artifactTransport"`
+	// ArtifactURLTTL is how long fetch URLs stay valid.
+	ArtifactURLTTL time.Duration `json:"artifactURLTTL"`
+	// ListenerURL is where verifiers reach the result listener, which also
+	// serves fetch URLs. It defaults to the local listener port.
+	ListenerURL string `json:"listenerURL"`
+	// Archival keeps every artifact uploaded to the node.
+	Archival bool `json:"archival"`
+	// ProofRetentionBlocks is how many blocks proofs are kept after the
+	// voting window of the last request they were submitted with closed.
+	ProofRetentionBlocks uint64 `json:"proofRetentionBlocks"`
+	// ImageRetentionBlocks is how many blocks ELFs and other artifacts of an
+	// image are kept after it was last registered to or requested against. 0
+	// keeps them.
+	ImageRetentionBlocks uint64 `json:"imageRetentionBlocks"`
+	// GCInterval is how often expired artifacts are collected.
+	GCInterval time.Duration `json:"gcInterval"`
+	// AdminAPI serves calls reporting on the node itself, such as its disk
+	// usage, on the JSON-RPC. They are off by default.
+	AdminAPI bool `json:"adminAPI"`
 	// State Sync
 	StateSyncServerDelay time.Duration `json:"stateSyncServerDelay"` // for testing
 
 	loaded               bool
 	nodeID               ids.NodeID
 	parsedExemptSponsors []codec.Address
-	Client               *requester.EndpointRequester
+	Verifiers            *requester.Pool
 	Port                 string // rust endpoint to send verify requests
 	ListenerPort         string // go endpoint to listen for verify results from rust endpoint
 }
@@ -94,25 +125,48 @@ func New(nodeID ids.NodeID, b []byte) (*Config, error) {
 	if len(c.HubPorturi) == 0 {
 		return nil, fmt.Errorf("hub port not provided")
 	}
-
-	success, rustPort, unintPort, err := requester.Ping(requester.New(c.HubPorturi))
-	if err != nil || !success /*lol*/ {
-		return nil, fmt.Errorf("%s: can't Ping Hub", err)
+	if err := artifacts.ValidTransport(c.ArtifactTransport); err != nil {
+		return nil, err
 	}
-
-	c.Client = requester.New("http://127.0.0.1:" + rustPort)
-
-	success, err = requester.PingSingle(c.Client)
-	if err != nil || !success {
-		return nil, fmt.Errorf("%s: can't Ping Server", err)
+	if c.GCInterval <= 0 {
+		return nil, fmt.Errorf("gc interval must be positive, found %s", c.GCInterval)
 	}
 
-	c.Port = rustPort
-	c.ListenerPort = unintPort
+	// the hub is only asked for the verifier once the node is up, so that a
+	// verifier outage doesn't keep the node from starting
+	c.Verifiers = requester.NewPool(c.VerifierEndpoints)
 
 	return c, nil
 }
 
+// Discover asks the hub for the verifier endpoint and the port verification
+// results are submitted to. Configured [VerifierEndpoints] take precedence
+// over the verifier the hub points to. Discovery fails if the hub doesn't
+// speak any protocol version the node speaks.
+func (c *Config) Discover() error {
+	reply, err := requester.Ping(requester.New(c.HubPorturi))
+	if err != nil {
+		return fmt.Errorf("%w: can't Ping Hub", err)
+	}
+	if !reply.Success {
+		return ErrHubUnavailable
+	}
+	version, err := protocol.Negotiate(reply.Versions)
+	if err != nil {
+		return err
+	}
+	if len(c.VerifierEndpoints) == 0 {
+		c.Verifiers.SetEndpoints([]*requester.EndpointConfig{{Uri: "http://127.0.0.1:" + reply.RustPort}})
+	}
+	c.Verifiers.SetVersion(version)
+	c.Port = reply.RustPort
+	c.ListenerPort = reply.UnintPort
+	if len(c.ListenerURL) == 0 {
+		c.ListenerURL = "http://127.0.0.1:" + c.ListenerPort
+	}
+	return nil
+}
+
 func (c *Config) setDefault() {
 	c.LogLevel = c.Config.GetLogLevel()
 	c.AuthVerificationCores = c.Config.GetAuthVerificationCores()
@@ -124,6 +178,10 @@ func (c *Config) setDefault() {
 	c.StreamingBacklogSize = c.Config.GetStreamingBacklogSize()
 	c.VerifyAuth = c.Config.GetVerifyAuth()
 	c.StoreTransactions = defaultStoreTransactions
+	c.ArtifactTransport = artifacts.TransportPath
+	c.ArtifactURLTTL = artifacts.DefaultURLTTL
+	c.ProofRetentionBlocks = defaultProofRetentionBlocks
+	c.GCInterval = defaultGCInterval
 }
 
 func (c *Config) GetLogLevel() logging.Level                { return c.LogLevel }
@@ -159,6 +217,13 @@ func (c *Config) GetContinuousProfilerConfig() *profiler.Config {
 		MaxNumFiles: defaultContinuousProfilerMaxFiles,
 	}
 }
+func (c *Config) GetRetentionConfig() *artifacts.RetentionConfig {
+	return &artifacts.RetentionConfig{
+		ProofBlocks: c.ProofRetentionBlocks,
+		ImageBlocks: c.ImageRetentionBlocks,
+		Archival:    c.Archival,
+	}
+}
 func (c *Config) GetVerifyAuth() bool        { return c.VerifyAuth }
 func (c *Config) GetStoreTransactions() bool { return c.StoreTransactions }
 func (c *Config) Loaded() bool               { return c.loaded }
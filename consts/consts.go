@@ -18,6 +18,17 @@ const (
 	Decimals = 9
 )
 
+// Keys resolved through [chain.Rules.FetchCustom].
+const (
+	ValidatorsKey         = ""
+	VerificationRewardKey = "verificationReward"
+	CommitRevealKey       = "commitReveal"
+	CanaryAuthorityKey    = "canaryAuthority"
+	CanaryPenaltyKey      = "canaryPenalty"
+	CanaryRevealKey       = "canaryReveal"
+	StorageFeePerByteKey  = "storageFeePerByte"
+)
+
 var ID ids.ID
 
 func init() {
@@ -0,0 +1,44 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package consts
+
+import "fmt"
+
+// NoirFlavor is the Barretenberg proving scheme a Noir proof was produced
+// with. Verifiers need it to pick the matching verifier for the proof and
+// verification key.
+type NoirFlavor uint8
+
+const (
+	NoirUltraPlonk NoirFlavor = iota + 1
+	NoirUltraHonk
+
+	maxNoirFlavor = NoirUltraHonk
+)
+
+var noirFlavorNames = [maxNoirFlavor + 1]string{
+	"",
+	"ultra_plonk",
+	"ultra_honk",
+}
+
+func (f NoirFlavor) Known() bool {
+	return f >= NoirUltraPlonk && f <= maxNoirFlavor
+}
+
+func (f NoirFlavor) String() string {
+	if !f.Known() {
+		return fmt.Sprintf("unknown(%d)", uint8(f))
+	}
+	return noirFlavorNames[f]
+}
+
+func ParseNoirFlavor(s string) (NoirFlavor, error) {
+	for i, name := range noirFlavorNames {
+		if len(name) > 0 && name == s {
+			return NoirFlavor(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown noir flavor: %q", s)
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package consts
+
+import "fmt"
+
+// Outcome is the result a validator reports for a verification request. Only
+// [OutcomeValid] and [OutcomeInvalid] are verdicts on the proof itself, the
+// others tell the submitter what to fix.
+type Outcome uint8
+
+const (
+	// OutcomeInvalid and OutcomeValid keep the byte values of the former
+	// boolean vote.
+	OutcomeInvalid Outcome = iota
+	OutcomeValid
+	OutcomeMalformedArtifact
+	OutcomeArtifactMissing
+	OutcomeUnsupported
+	OutcomeVerifierError
+
+	NumOutcomes = int(OutcomeVerifierError) + 1
+)
+
+var outcomeNames = [NumOutcomes]string{
+	"invalid",
+	"valid",
+	"malformedArtifact",
+	"artifactMissing",
+	"unsupported",
+	"verifierError",
+}
+
+// Counted returns true if [o] counts toward quorum.
+func (o Outcome) Counted() bool {
+	return o == OutcomeValid || o == OutcomeInvalid
+}
+
+func (o Outcome) Known() bool {
+	return int(o) < NumOutcomes
+}
+
+func (o Outcome) String() string {
+	if !o.Known() {
+		return fmt.Sprintf("unknown(%d)", uint8(o))
+	}
+	return outcomeNames[o]
+}
+
+func ParseOutcome(s string) (Outcome, error) {
+	for i, name := range outcomeNames {
+		if name == s {
+			return Outcome(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown outcome: %q", s)
+}
+
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *Outcome) UnmarshalText(b []byte) error {
+	parsed, err := ParseOutcome(string(b))
+	if err != nil {
+		return err
+	}
+	*o = parsed
+	return nil
+}
@@ -16,6 +16,22 @@ const (
 	GnarkID    uint8 = 7
 	JoltID     uint8 = 8
 	Plonky2ID  uint8 = 9
+	// validator incentive TypeIDs
+	ClaimRewardsID uint8 = 10
+	CommitVoteID   uint8 = 11
+	CommitCanaryID uint8 = 12
+	RevealCanaryID uint8 = 13
+	// proving systems TypeIDs, continued
+	Halo2ID uint8 = 14
+	NoirID  uint8 = 15
+	// verified in-process
+	Groth16ID uint8 = 16
+	CairoID   uint8 = 17
+	// incrementally verifiable computation TypeIDs, proofs of steps are
+	// verified with NovaID
+	NovaID          uint8 = 18
+	OpenIVCID       uint8 = 19
+	RecordIVCStepID uint8 = 20
 	// Auth TypeIDs
 	ED25519ID   uint8 = 0
 	SECP256R1ID uint8 = 1
@@ -7,20 +7,24 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	ametrics "github.com/ava-labs/avalanchego/api/metrics"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/snow"
 	handle "github.com/sausaging/hyper-pvzk/accept_handlers"
 	"github.com/sausaging/hyper-pvzk/actions"
+	"github.com/sausaging/hyper-pvzk/artifacts"
 	"github.com/sausaging/hyper-pvzk/auth"
 	"github.com/sausaging/hyper-pvzk/config"
 	"github.com/sausaging/hyper-pvzk/consts"
 	"github.com/sausaging/hyper-pvzk/genesis"
+	"github.com/sausaging/hyper-pvzk/requester"
 	"github.com/sausaging/hyper-pvzk/rpc"
 	"github.com/sausaging/hyper-pvzk/storage"
 	"github.com/sausaging/hyper-pvzk/trustless"
-	"github.com/sausaging/hyper-pvzk/version"
 	"github.com/sausaging/hypersdk/builder"
 	"github.com/sausaging/hypersdk/chain"
 	"github.com/sausaging/hypersdk/fees"
@@ -35,6 +39,9 @@ import (
 var _ vm.Controller = (*Controller)(nil)
 var _ chain.Rules = (&genesis.Rules{})
 
+// verifyTimeout bounds handing a request to the verifiers, failovers included
+const verifyTimeout = time.Minute
+
 type Controller struct {
 	inner *vm.VM
 
@@ -48,11 +55,18 @@ type Controller struct {
 	metaDB database.Database
 	fileDB *filedb.FileDB
 
+	artifacts *artifacts.Store
+	signer    *artifacts.Signer
+	collector *artifacts.Collector
+
 	trustless *trustless.Trustless
-}
 
-func New() *vm.VM {
-	return vm.New(&Controller{}, version.Version)
+	discovered   atomic.Bool
+	verifying    atomic.Bool
+	unverifiedL  sync.Mutex
+	unverified   []*unverifiedRequest // accepted while no verifier was reachable
+	lastAccepted atomic.Int64         // timestamp of the last accepted block
+	stop         context.CancelFunc
 }
 
 func (c *Controller) Initialize(
@@ -110,10 +124,21 @@ func (c *Controller) Initialize(
 	}
 	c.metaDB = metaDB
 	c.fileDB = fileDB
+	c.signer, err = artifacts.NewSigner()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+	c.artifacts = artifacts.NewStore(fileDB, c.inner.ReadState, c.config.ArtifactTransport, c.signer, c.config.ArtifactURLTTL)
+	c.collector = artifacts.NewCollector(metaDB, fileDB, c.config.GetRetentionConfig())
 
-	c.trustless = trustless.New(c.config.Port, c.config.ListenerPort, &snowCtx.WarpSigner, snowCtx.PublicKey, c.config.ValPrivKey, c.snowCtx.Log, c.UnitPrices, c.Submit, c.Rules)
+	c.trustless = trustless.New(&snowCtx.WarpSigner, snowCtx.PublicKey, c.config.ValPrivKey, c.snowCtx.Log, c.UnitPrices, c.Submit, c.Rules, c.inner.ReadState)
 
-	go c.trustless.ListenResults()
+	var verifierCtx context.Context
+	verifierCtx, c.stop = context.WithCancel(context.Background())
+	go c.connectVerifier(verifierCtx)
+	if !c.config.Archival {
+		go c.collectArtifacts(verifierCtx)
+	}
 	// Create handlers
 	//
 	// hypersdk handler are initiatlized automatically, you just need to
@@ -127,6 +152,7 @@ func (c *Controller) Initialize(
 		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 	apis[rpc.JSONRPCEndpoint] = jsonRPCHandler
+	apis[artifacts.ServeEndpoint] = artifacts.ServeHandler(fileDB, c.inner.ReadState)
 
 	// Create builder and gossiper
 	var (
@@ -150,56 +176,141 @@ func (c *Controller) Initialize(
 
 func (c *Controller) Rules(t int64) chain.Rules {
 	// TODO: extend with [UpgradeBytes]
-	return c.genesis.Rules(t, c.snowCtx.NetworkID, c.snowCtx.ChainID, c.config.Client, c.inner.CurrentValidators)
+	return c.genesis.Rules(t, c.snowCtx.NetworkID, c.snowCtx.ChainID, c.config.Verifiers, c.inner.CurrentValidators)
 }
 
 func (c *Controller) StateManager() chain.StateManager {
 	return c.stateManager
 }
 
+// commitDeadline mirrors the deadline stored when [action] was executed. It
+// is 0 for proving systems that vote without commit-reveal.
+func (c *Controller) commitDeadline(action chain.Action, ts int64, timeOutBlocks uint64) int64 {
+	blocks := c.genesis.CommitBlocks(action.GetTypeID())
+	if blocks == 0 {
+		return 0
+	}
+	return storage.CommitDeadline(ts, timeOutBlocks, blocks)
+}
+
+// verify hands [action], accepted at [timeStamp], to a verifier in the
+// background, so that a slow verifier never holds up block acceptance or other
+// proving systems.
+func (c *Controller) verify(action chain.Action, timeStamp int64, name string, f func(context.Context, *requester.EndpointRequester) error) {
+	requested, _ := actions.Requested(action)
+	c.dispatch(&unverifiedRequest{
+		provingSystem: action.GetTypeID(),
+		name:          name,
+		timeOut:       storage.TimeOutAt(timeStamp, requested.TimeOutBlocks),
+		f:             f,
+	})
+}
+
 func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) error {
 	batch := c.metaDB.NewBatch()
 	defer batch.Reset()
 
+	// requests that timed out no longer take votes
+	c.trustless.Expire(blk.GetTimestamp())
+
+	var requests uint64
 	results := blk.Results()
 	for i, tx := range blk.Txs {
 		result := results[i]
 
-		switch tx.Action.(type) {
+		// failed requests were never opened, there is nothing to verify
+		if result.Success {
+			switch tx.Action.(type) {
 
-		case *actions.SP1:
-			sp1 := tx.Action.(*actions.SP1)
-			c.trustless.ListenActions(tx.ID(), sp1.TimeOutBlocks)
-			if err := handle.HandleSP1(tx.ID(), sp1.ImageID, uint16(sp1.ProofValType), c.fileDB.BaseDir(), c.config.Client); err != nil {
-				c.inner.Logger().Info("error handling SP1", zap.Error(err))
-			}
+			case *actions.SP1:
+				sp1 := tx.Action.(*actions.SP1)
+				c.trustless.ListenActions(tx.ID(), storage.TimeOutAt(blk.GetTimestamp(), sp1.TimeOutBlocks), c.commitDeadline(tx.Action, blk.GetTimestamp(), sp1.TimeOutBlocks))
+				c.verify(tx.Action, blk.GetTimestamp(), "SP1", func(ctx context.Context, e *requester.EndpointRequester) error {
+					image, err := storage.GetImageFromState(ctx, c.inner.ReadState, sp1.ImageID)
+					if err != nil {
+						return err
+					}
+					return handle.HandleSP1(ctx, tx.ID(), sp1.ImageID, uint16(sp1.ProofValType), sp1.SegmentValTypes, image.ProgramID, c.artifacts, e)
+				})
 
-		case *actions.RiscZero:
-			risc0 := tx.Action.(*actions.RiscZero)
-			c.trustless.ListenActions(tx.ID(), risc0.TimeOutBlocks)
-			if err := handle.HandleRiscZero(tx.ID(), risc0.ImageID, uint16(risc0.ProofValType), risc0.RiscZeroImageID, c.fileDB.BaseDir(), c.config.Client); err != nil {
-				c.inner.Logger().Info("error handling RiscZero", zap.Error(err))
+			case *actions.RiscZero:
+				risc0 := tx.Action.(*actions.RiscZero)
+				c.trustless.ListenActions(tx.ID(), storage.TimeOutAt(blk.GetTimestamp(), risc0.TimeOutBlocks), c.commitDeadline(tx.Action, blk.GetTimestamp(), risc0.TimeOutBlocks))
+				c.verify(tx.Action, blk.GetTimestamp(), "RiscZero", func(ctx context.Context, e *requester.EndpointRequester) error {
+					// verified against the pinned image ID, the one in the
+					// request matched it
+					image, err := storage.GetImageFromState(ctx, c.inner.ReadState, risc0.ImageID)
+					if err != nil {
+						return err
+					}
+					return handle.HandleRiscZero(ctx, tx.ID(), risc0.ImageID, uint16(risc0.ProofValType), risc0.SegmentValTypes, image.ProgramID, c.artifacts, e)
+				})
+			case *actions.Miden:
+				miden := tx.Action.(*actions.Miden)
+				c.trustless.ListenActions(tx.ID(), storage.TimeOutAt(blk.GetTimestamp(), miden.TimeOutBlocks), c.commitDeadline(tx.Action, blk.GetTimestamp(), miden.TimeOutBlocks))
+				c.verify(tx.Action, blk.GetTimestamp(), "Miden", func(ctx context.Context, e *requester.EndpointRequester) error {
+					return handle.HandleMiden(ctx, tx.ID(), miden.ImageID, uint16(miden.ProofValType),
+						handle.MidenSource{ValType: uint16(miden.CodeValType), Inline: miden.CodeFrontEnd},
+						handle.MidenSource{ValType: uint16(miden.InputsValType), Inline: miden.InputsFrontEnd},
+						handle.MidenSource{ValType: uint16(miden.OutputsValType), Inline: miden.OutputsFrontEnd},
+						c.artifacts, e)
+				})
+			case *actions.Jolt:
+				jolt := tx.Action.(*actions.Jolt)
+				c.trustless.ListenActions(tx.ID(), storage.TimeOutAt(blk.GetTimestamp(), jolt.TimeOutBlocks), c.commitDeadline(tx.Action, blk.GetTimestamp(), jolt.TimeOutBlocks))
+				c.verify(tx.Action, blk.GetTimestamp(), "Jolt", func(ctx context.Context, e *requester.EndpointRequester) error {
+					return handle.HandleJolt(ctx, tx.ID(), jolt.ImageID, uint16(jolt.ProofValType), c.artifacts, e)
+				})
+			case *actions.PLONKY2:
+				plonky2 := tx.Action.(*actions.PLONKY2)
+				c.trustless.ListenActions(tx.ID(), storage.TimeOutAt(blk.GetTimestamp(), plonky2.TimeOutBlocks), c.commitDeadline(tx.Action, blk.GetTimestamp(), plonky2.TimeOutBlocks))
+				c.verify(tx.Action, blk.GetTimestamp(), "Plonky2", func(ctx context.Context, e *requester.EndpointRequester) error {
+					return handle.HandlePlonky2(ctx, tx.ID(), plonky2.ImageID, uint16(plonky2.ProofValType), uint16(plonky2.CommonDataValType), uint16(plonky2.VerifierDataValType), c.artifacts, e)
+				})
+			case *actions.Halo2:
+				halo2 := tx.Action.(*actions.Halo2)
+				c.trustless.ListenActions(tx.ID(), storage.TimeOutAt(blk.GetTimestamp(), halo2.TimeOutBlocks), c.commitDeadline(tx.Action, blk.GetTimestamp(), halo2.TimeOutBlocks))
+				c.verify(tx.Action, blk.GetTimestamp(), "Halo2", func(ctx context.Context, e *requester.EndpointRequester) error {
+					return handle.HandleHalo2(ctx, tx.ID(), halo2.ImageID, uint16(halo2.ProofValType), uint16(halo2.VerifyingKeyValType), uint16(halo2.InstancesValType), c.artifacts, e)
+				})
+			case *actions.Cairo:
+				cairo := tx.Action.(*actions.Cairo)
+				c.trustless.ListenActions(tx.ID(), storage.TimeOutAt(blk.GetTimestamp(), cairo.TimeOutBlocks), c.commitDeadline(tx.Action, blk.GetTimestamp(), cairo.TimeOutBlocks))
+				c.verify(tx.Action, blk.GetTimestamp(), "Cairo", func(ctx context.Context, e *requester.EndpointRequester) error {
+					// verified against the pinned program hash
+					image, err := storage.GetImageFromState(ctx, c.inner.ReadState, cairo.ImageID)
+					if err != nil {
+						return err
+					}
+					return handle.HandleCairo(ctx, tx.ID(), cairo.ImageID, uint16(cairo.ProofValType), image.ProgramID, c.artifacts, e)
+				})
+			case *actions.IVCStep:
+				step := tx.Action.(*actions.IVCStep)
+				c.trustless.ListenActions(tx.ID(), storage.TimeOutAt(blk.GetTimestamp(), step.TimeOutBlocks), c.commitDeadline(tx.Action, blk.GetTimestamp(), step.TimeOutBlocks))
+				c.verify(tx.Action, blk.GetTimestamp(), "Nova", func(ctx context.Context, e *requester.EndpointRequester) error {
+					ivc, err := storage.GetIVCFromState(ctx, c.inner.ReadState, step.InstanceID)
+					if err != nil {
+						return err
+					}
+					return handle.HandleNova(ctx, tx.ID(), step.ImageID, step.InstanceID, step.Step, ivc.InitialState, uint16(step.ProofValType), uint16(step.VerifyingKeyValType), c.artifacts, e)
+				})
+			case *actions.Noir:
+				noir := tx.Action.(*actions.Noir)
+				c.trustless.ListenActions(tx.ID(), storage.TimeOutAt(blk.GetTimestamp(), noir.TimeOutBlocks), c.commitDeadline(tx.Action, blk.GetTimestamp(), noir.TimeOutBlocks))
+				c.verify(tx.Action, blk.GetTimestamp(), "Noir", func(ctx context.Context, e *requester.EndpointRequester) error {
+					return handle.HandleNoir(ctx, tx.ID(), noir.ImageID, consts.NoirFlavor(noir.Flavor), uint16(noir.ProofValType), uint16(noir.VerifyingKeyValType), uint16(noir.PublicInputsValType), c.artifacts, e)
+				})
+				// case *actions.ValResultVote:
+				// 	//@todo keep track of spendings of validators
 			}
-		case *actions.Miden:
-			miden := tx.Action.(*actions.Miden)
-			c.trustless.ListenActions(tx.ID(), miden.TimeOutBlocks)
-			if err := handle.HandleMiden(tx.ID(), miden.ImageID, uint16(miden.ProofValType), miden.CodeFrontEnd, miden.InputsFrontEnd, miden.OutputsFrontEnd, c.fileDB.BaseDir(), c.config.Client); err != nil {
-				c.inner.Logger().Info("error handling Miden", zap.Error(err))
-			}
-		case *actions.Jolt:
-			jolt := tx.Action.(*actions.Jolt)
-			c.trustless.ListenActions(tx.ID(), jolt.TimeOutBlocks)
-			if err := handle.HandleJolt(tx.ID(), jolt.ImageID, uint16(jolt.ProofValType), c.fileDB.BaseDir(), c.config.Client); err != nil {
-				c.inner.Logger().Info("error handling Jolt", zap.Error(err))
+		}
+		if result.Success {
+			if _, ok := actions.Requested(tx.Action); ok {
+				requests++
 			}
-		case *actions.PLONKY2:
-			plonky2 := tx.Action.(*actions.PLONKY2)
-			c.trustless.ListenActions(tx.ID(), plonky2.TimeOutBlocks)
-			if err := handle.HandlePlonky2(tx.ID(), plonky2.ImageID, uint16(plonky2.ProofValType), uint16(plonky2.CommonDataValType), uint16(plonky2.VerifierDataValType), c.fileDB.BaseDir(), c.config.Client); err != nil {
-				c.inner.Logger().Info("error handling Plonky2", zap.Error(err))
+			if err := c.retain(tx.Action, blk.GetTimestamp()); err != nil {
+				return err
 			}
-			// case *actions.ValResultVote:
-			// 	//@todo keep track of spendings of validators
 		}
 		if c.config.GetStoreTransactions() {
 			err := storage.StoreTransaction(
@@ -222,6 +333,16 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 			}
 		}
 	}
+	if requests > 0 {
+		total, err := storage.GetRequestTotal(c.metaDB)
+		if err != nil {
+			return err
+		}
+		if err := storage.StoreRequests(batch, blk.GetTimestamp(), total+requests, requests); err != nil {
+			return err
+		}
+	}
+	c.lastAccepted.Store(blk.GetTimestamp())
 	return batch.Write()
 }
 
@@ -229,7 +350,10 @@ func (*Controller) Rejected(context.Context, *chain.StatelessBlock) error {
 	return nil
 }
 
-func (*Controller) Shutdown(context.Context) error {
+func (c *Controller) Shutdown(context.Context) error {
+	if c.stop != nil {
+		c.stop()
+	}
 	// Do not close any databases provided during initialization. The VM will
 	// close any databases your provided.
 	return nil
@@ -8,10 +8,22 @@ import (
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/requester"
 )
 
 type metrics struct {
 	transfer prometheus.Counter
+
+	verifying  prometheus.Gauge
+	unverified prometheus.Counter
+}
+
+func (m *metrics) setVerifying(verifying bool) {
+	if verifying {
+		m.verifying.Set(1)
+	} else {
+		m.verifying.Set(0)
+	}
 }
 
 func newMetrics(gatherer ametrics.MultiGatherer) (*metrics, error) {
@@ -21,12 +33,28 @@ func newMetrics(gatherer ametrics.MultiGatherer) (*metrics, error) {
 			Name:      "transfer",
 			Help:      "number of transfer actions",
 		}),
+		verifying: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "verifier",
+			Name:      "verifying",
+			Help:      "1 if the node hands verification requests to a healthy verifier",
+		}),
+		unverified: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "verifier",
+			Name:      "unverified",
+			Help:      "number of requests that timed out before a verifier could be reached",
+		}),
 	}
 	r := prometheus.NewRegistry()
 	errs := wrappers.Errs{}
 	errs.Add(
 		r.Register(m.transfer),
-
+		r.Register(m.verifying),
+		r.Register(m.unverified),
+	)
+	for _, c := range requester.Collectors() {
+		errs.Add(r.Register(c))
+	}
+	errs.Add(
 		gatherer.Register(consts.Name, r),
 	)
 	return m, errs.Err
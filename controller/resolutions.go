@@ -47,3 +47,45 @@ func (c *Controller) GetVerifyStatusFromState(
 ) (bool, error) {
 	return storage.GetVerifyStatusFromState(ctx, c.inner.ReadState, txID)
 }
+
+func (c *Controller) GetOutputsFromState(
+	ctx context.Context,
+	txID ids.ID,
+) (bool, []byte, error) {
+	return storage.GetOutputsFromState(ctx, c.inner.ReadState, txID)
+}
+
+func (c *Controller) GetIVCFromState(
+	ctx context.Context,
+	instanceID ids.ID,
+) (*storage.IVC, error) {
+	return storage.GetIVCFromState(ctx, c.inner.ReadState, instanceID)
+}
+
+func (c *Controller) GetTallyFromState(
+	ctx context.Context,
+	txID ids.ID,
+) (*storage.Tally, error) {
+	return storage.GetTallyFromState(ctx, c.inner.ReadState, txID)
+}
+
+// GetParticipationFromState returns the participation of [acct] and the
+// number of requests made since its first vote, as counted by the node.
+func (c *Controller) GetParticipationFromState(
+	ctx context.Context,
+	acct codec.Address,
+) (*storage.Participation, uint64, error) {
+	p, exists, err := storage.GetParticipationFromState(ctx, c.inner.ReadState, acct)
+	if err != nil || !exists {
+		return p, 0, err
+	}
+	eligible, err := storage.GetRequestsSince(c.metaDB, p.FirstRequestAt)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p, eligible, nil
+}
+
+func (c *Controller) GetCanaryStatsFromState(ctx context.Context) (*storage.CanaryStats, error) {
+	return storage.GetCanaryStatsFromState(ctx, c.inner.ReadState)
+}
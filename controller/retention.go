@@ -0,0 +1,69 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/sausaging/hypersdk/chain"
+	"go.uber.org/zap"
+
+	"github.com/sausaging/hyper-pvzk/actions"
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/rpc"
+	"github.com/sausaging/hyper-pvzk/storage"
+)
+
+// DiskUsage reports the disk usage of the artifacts stored by the node, if
+// the node serves the admin API.
+func (c *Controller) DiskUsage() (*artifacts.Usage, error) {
+	if !c.config.AdminAPI {
+		return nil, rpc.ErrAdminAPIDisabled
+	}
+	return c.collector.Usage()
+}
+
+// retain tracks the artifacts [action], accepted at [ts], uses so that they
+// are collected once they expire.
+func (c *Controller) retain(action chain.Action, ts int64) error {
+	if register, ok := action.(*actions.RegisterImage); ok {
+		return c.collector.Registered(register.ImageID, uint16(register.ValType), ts)
+	}
+	requested, ok := actions.Requested(action)
+	if !ok {
+		return nil
+	}
+	closesAt := storage.TimeOutAt(ts, requested.TimeOutBlocks)
+	proofs := append([]uint16{requested.Proof}, requested.Segments...)
+	return c.collector.Requested(requested.ImageID, proofs, requested.Program, ts, closesAt)
+}
+
+// collectArtifacts removes expired artifacts every [GCInterval] until [ctx]
+// is done. Expiry is measured against the last accepted block, so that a
+// node catching up doesn't remove artifacts early.
+func (c *Controller) collectArtifacts(ctx context.Context) {
+	log := c.inner.Logger()
+	t := time.NewTicker(c.config.GCInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+		now := c.lastAccepted.Load()
+		if now == 0 {
+			continue
+		}
+		files, size, err := c.collector.Collect(now)
+		if err != nil {
+			log.Warn("failed to collect artifacts", zap.Error(err))
+			continue
+		}
+		if files > 0 {
+			log.Info("collected artifacts", zap.Uint64("files", files), zap.Uint64("bytes", size))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/storage"
+)
+
+// SubmitChunk stores [data] as chunk [chunkIndex] of the [valType] artifact
+// of [imageID] and broadcasts it to the other validators, unless it takes the
+// artifact beyond the size paid for at registration. It returns how many
+// paid bytes are left. Once all of them are uploaded, the artifact is checked
+// against its commitment, and dropped if it doesn't match so that it can be
+// uploaded again.
+func (c *Controller) SubmitChunk(ctx context.Context, imageID ids.ID, valType uint16, chunkIndex uint16, data []byte) (uint64, error) {
+	remaining, err := artifacts.CheckChunk(ctx, c.fileDB, c.inner.ReadState, imageID, valType, len(data))
+	if err != nil {
+		return 0, err
+	}
+	c.inner.Broadcast(ctx, imageID, valType, chunkIndex, data)
+	if remaining > 0 {
+		return remaining, nil
+	}
+	if _, _, err := artifacts.Read(ctx, c.fileDB, c.inner.ReadState, imageID, valType); err != nil {
+		if rerr := c.fileDB.Remove(storage.DeployKey(imageID, valType)); rerr != nil {
+			return 0, errors.Join(err, rerr)
+		}
+		return 0, err
+	}
+	return 0, nil
+}
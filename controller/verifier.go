@@ -0,0 +1,143 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/requester"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// unverifiedRequest is a request handed to the verifiers, kept until its
+// [timeOut] if none of them could be reached.
+type unverifiedRequest struct {
+	provingSystem uint8
+	name          string
+	timeOut       int64
+	f             func(context.Context, *requester.EndpointRequester) error
+}
+
+// VerifierStatus reports whether the hub pointed the node to a verifier, and
+// how many of its endpoints are healthy, along with the protocol version
+// negotiated with it and the number of requests waiting for a verifier. A node
+// without a healthy verifier keeps producing and accepting blocks, but doesn't
+// vote until a verifier is back.
+func (c *Controller) VerifierStatus() (bool, int, int, uint32, int) {
+	c.unverifiedL.Lock()
+	unverified := len(c.unverified)
+	c.unverifiedL.Unlock()
+	return c.discovered.Load(), c.config.Verifiers.Len(), c.config.Verifiers.Healthy(), c.config.Verifiers.Version(), unverified
+}
+
+// dispatch hands [r] to the verifiers in the background. Requests no verifier
+// could be reached for are kept, and handed over again by [retryUnverified].
+func (c *Controller) dispatch(r *unverifiedRequest) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+		defer cancel()
+		err := c.config.Verifiers.Do(ctx, r.provingSystem, r.f)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, requester.ErrNoEndpoint) && !errors.Is(err, requester.ErrUnreachable) {
+			c.inner.Logger().Info("error handling "+r.name, zap.Error(err))
+			return
+		}
+		c.inner.Logger().Warn("no verifier reachable, verifying "+r.name+" once one is", zap.Error(err))
+		c.unverifiedL.Lock()
+		c.unverified = append(c.unverified, r)
+		c.unverifiedL.Unlock()
+	}()
+}
+
+// retryUnverified hands the requests no verifier could be reached for to the
+// verifiers again, dropping the ones that timed out in the meantime.
+func (c *Controller) retryUnverified() {
+	c.unverifiedL.Lock()
+	requests := c.unverified
+	c.unverified = nil
+	c.unverifiedL.Unlock()
+	now := c.lastAccepted.Load()
+	for _, r := range requests {
+		if r.timeOut <= now {
+			c.metrics.unverified.Inc()
+			continue
+		}
+		c.dispatch(r)
+	}
+}
+
+// connectVerifier asks the hub for the verifier until it answers, then serves
+// verification results and health checks the verifiers until [ctx] is done.
+// The node runs in a verifier-unavailable mode in the meantime.
+func (c *Controller) connectVerifier(ctx context.Context) {
+	log := c.inner.Logger()
+	delay := minReconnectDelay
+	for {
+		err := c.config.Discover()
+		if err == nil {
+			break
+		}
+		log.Warn("verifier hub unavailable, not verifying requests",
+			zap.String("hub", c.config.HubPorturi),
+			zap.Duration("retryIn", delay),
+			zap.Error(err),
+		)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(2*delay, maxReconnectDelay)
+	}
+	c.artifacts.SetBaseURL(c.config.ListenerURL)
+	c.discovered.Store(true)
+	log.Info("verifier hub discovered",
+		zap.Uint32("protocolVersion", c.config.Verifiers.Version()),
+		zap.String("artifactTransport", c.config.ArtifactTransport),
+	)
+	go func() {
+		handlers := map[string]http.Handler{
+			artifacts.FetchPath: artifacts.FetchHandler(c.fileDB, c.signer),
+		}
+		if err := c.trustless.ListenResults(c.config.ListenerPort, handlers); err != nil {
+			log.Error("stopped listening for verification results", zap.Error(err))
+		}
+	}()
+
+	t := time.NewTicker(c.config.Verifiers.HealthCheckInterval)
+	defer t.Stop()
+	for {
+		c.config.Verifiers.HealthCheck()
+		verifying := c.config.Verifiers.Healthy() > 0
+		if verifying != c.verifying.Load() {
+			if verifying {
+				log.Info("verifier available, verifying requests", zap.Int("endpoints", c.config.Verifiers.Healthy()))
+			} else {
+				log.Warn("no verifier endpoint is healthy, not verifying requests")
+			}
+		}
+		c.verifying.Store(verifying)
+		c.metrics.setVerifying(verifying)
+		if verifying {
+			c.retryUnverified()
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	hrpc "github.com/sausaging/hypersdk/rpc"
+	"github.com/sausaging/hypersdk/vm"
+	"go.uber.org/zap"
+
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/version"
+)
+
+var (
+	ErrSubmitChunksDisabled = errors.New("submitChunks is disabled, upload chunks with submitChunk")
+
+	errNoBroadcastHandler = errors.New("hypersdk VM registered no broadcast handler")
+)
+
+// VM is the hypersdk VM, with the artifact uploads it stores on its own held
+// to the size paid for at registration: chunks broadcast by other validators
+// are checked before they are stored, and the hypersdk submitChunks RPC, which
+// doesn't check them, is refused in favor of submitChunk.
+type VM struct {
+	*vm.VM
+
+	c *Controller
+
+	appSender common.AppSender
+	// broadcastHandler is the network handler the hypersdk VM receives chunks
+	// broadcast by other validators on
+	broadcastHandler uint8
+}
+
+func New() *VM {
+	c := &Controller{}
+	return &VM{VM: vm.New(c, version.Version), c: c}
+}
+
+func (v *VM) Initialize(
+	ctx context.Context,
+	snowCtx *snow.Context,
+	baseDB database.Database,
+	genesisBytes []byte,
+	upgradeBytes []byte,
+	configBytes []byte,
+	toEngine chan<- common.Message,
+	fxs []*common.Fx,
+	appSender common.AppSender,
+) error {
+	if err := v.VM.Initialize(ctx, snowCtx, baseDB, genesisBytes, upgradeBytes, configBytes, toEngine, fxs, appSender); err != nil {
+		return err
+	}
+	handler, err := findBroadcastHandler(v.VM)
+	if err != nil {
+		return err
+	}
+	v.appSender = appSender
+	v.broadcastHandler = handler
+	return nil
+}
+
+// findBroadcastHandler returns the network handler [inner] registered its
+// broadcast tree handler with. The hypersdk VM doesn't expose its network
+// manager, so it is looked up by reflection once all handlers are registered,
+// failing rather than letting broadcast chunks through unchecked if the
+// hypersdk VM stops registering it this way.
+func findBroadcastHandler(inner *vm.VM) (uint8, error) {
+	manager := reflect.ValueOf(inner).Elem().FieldByName("networkManager")
+	if manager.Kind() != reflect.Pointer || manager.IsNil() {
+		return 0, errNoBroadcastHandler
+	}
+	handlers := manager.Elem().FieldByName("handlers")
+	if handlers.Kind() != reflect.Map || handlers.Type().Key().Kind() != reflect.Uint8 {
+		return 0, errNoBroadcastHandler
+	}
+	want := reflect.TypeOf((*vm.BroadCastTreeHandler)(nil))
+	iter := handlers.MapRange()
+	for iter.Next() {
+		h := iter.Value()
+		if h.Kind() == reflect.Interface && !h.IsNil() && h.Elem().Type() == want {
+			return uint8(iter.Key().Uint()), nil
+		}
+	}
+	return 0, errNoBroadcastHandler
+}
+
+func (v *VM) CreateHandlers(ctx context.Context) (map[string]http.Handler, error) {
+	handlers, err := v.VM.CreateHandlers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wrapped := make(map[string]http.Handler, len(handlers))
+	for endpoint, h := range handlers {
+		wrapped[endpoint] = h
+	}
+	if h, ok := handlers[hrpc.JSONRPCEndpoint]; ok {
+		wrapped[hrpc.JSONRPCEndpoint] = refuseSubmitChunks(h)
+	}
+	return wrapped, nil
+}
+
+// refuseSubmitChunks refuses calls to the hypersdk submitChunks method of the
+// JSON-RPC served by [h].
+func refuseSubmitChunks(h http.Handler) http.Handler {
+	method := hrpc.Name + ".submitChunks"
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			Method string `json:"method"`
+		}
+		if json.Unmarshal(body, &req) == nil && strings.EqualFold(req.Method, method) {
+			http.Error(w, ErrSubmitChunksDisabled.Error(), http.StatusForbidden)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h.ServeHTTP(w, r)
+	})
+}
+
+// AppRequest refuses chunks broadcast beyond the size paid for at
+// registration, and chunks of artifacts that aren't registered, before the
+// hypersdk VM stores them. The broadcasting validator gets the error as
+// response.
+func (v *VM) AppRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, deadline time.Time, request []byte) error {
+	if len(request) > 0 && request[0] == v.broadcastHandler {
+		if err := v.c.checkBroadcastChunk(ctx, request[1:]); err != nil {
+			v.c.inner.Logger().Warn("refusing broadcast chunk", zap.Stringer("nodeID", nodeID), zap.Error(err))
+			return v.appSender.SendAppResponse(ctx, nodeID, requestID, []byte(err.Error()))
+		}
+	}
+	return v.VM.AppRequest(ctx, nodeID, requestID, deadline, request)
+}
+
+// checkBroadcastChunk checks the chunk in [msg], packed the way the hypersdk
+// VM broadcasts it, against the size paid for its artifact.
+func (c *Controller) checkBroadcastChunk(ctx context.Context, msg []byte) error {
+	r := codec.NewReader(msg, consts.MaxInt)
+	var imageID ids.ID
+	r.UnpackID(true, &imageID)
+	valType := r.UnpackUint64(false)
+	r.UnpackUint64(false) // chunk index
+	var data []byte
+	r.UnpackBytes(-1, true, &data)
+	if err := r.Err(); err != nil {
+		return err
+	}
+	if valType > uint64(consts.MaxUint16) {
+		return fmt.Errorf("%w: val type %d", artifacts.ErrNotRegistered, valType)
+	}
+	_, err := artifacts.CheckChunk(ctx, c.fileDB, c.inner.ReadState, imageID, uint16(valType), len(data))
+	return err
+}
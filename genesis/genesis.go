@@ -28,6 +28,14 @@ type CustomAllocation struct {
 	Balance uint64 `json:"balance"`
 }
 
+// CommitRevealConfig enables commit-reveal voting for a proving system. For
+// [CommitBlocks] after a request only vote commitments are accepted, votes
+// are revealed afterwards.
+type CommitRevealConfig struct {
+	ProvingSystem uint8  `json:"provingSystem"` // action TypeID of the proving system
+	CommitBlocks  uint64 `json:"commitBlocks"`
+}
+
 type Genesis struct {
 	// State Parameters
 	StateBranchFactor merkledb.BranchFactor `json:"stateBranchFactor"`
@@ -57,6 +65,16 @@ type Genesis struct {
 	StorageKeyWriteUnits      uint64 `json:"storageKeyWriteUnits"`
 	StorageValueWriteUnits    uint64 `json:"storageValueWriteUnits"` // per chunk
 
+	// Validator Incentive Parameters
+	VerificationReward uint64                `json:"verificationReward"` // paid by the requester, split among agreeing voters
+	CommitReveal       []*CommitRevealConfig `json:"commitReveal"`
+	CanaryAuthority    string                `json:"canaryAuthority"`    // bech32 address issuing canary requests, empty disables them
+	CanaryPenalty      uint64                `json:"canaryPenalty"`      // withheld from the rewards of validators voting valid on a canary
+	CanaryRevealBlocks uint64                `json:"canaryRevealBlocks"` // canaries are revealed within this many blocks of their timeout, rewards are held back until then
+
+	// Artifact Storage Parameters
+	StorageFeePerByte uint64 `json:"storageFeePerByte"` // burned from the registrant for every declared byte every validator stores
+
 	// Allocates
 	CustomAllocation []*CustomAllocation `json:"customAllocation"`
 }
@@ -94,6 +112,14 @@ func Default() *Genesis {
 		StorageValueAllocateUnits: 5,
 		StorageKeyWriteUnits:      10,
 		StorageValueWriteUnits:    3,
+
+		// Validator Incentive Parameters
+		VerificationReward: 1_000,
+		CanaryPenalty:      10_000,
+		CanaryRevealBlocks: 60,
+
+		// Artifact Storage Parameters
+		StorageFeePerByte: 1,
 	}
 }
 
@@ -115,6 +141,12 @@ func (g *Genesis) Load(ctx context.Context, tracer trace.Tracer, mu state.Mutabl
 		return err
 	}
 
+	if len(g.CanaryAuthority) > 0 {
+		if _, err := codec.ParseAddressBech32(consts.HRP, g.CanaryAuthority); err != nil {
+			return fmt.Errorf("%w: canary authority %s", err, g.CanaryAuthority)
+		}
+	}
+
 	supply := uint64(0)
 	for _, alloc := range g.CustomAllocation {
 		addr, err := codec.ParseAddressBech32(consts.HRP, alloc.Address)
@@ -132,6 +164,28 @@ func (g *Genesis) Load(ctx context.Context, tracer trace.Tracer, mu state.Mutabl
 	return nil
 }
 
+// CommitBlocks returns 0 if [provingSystem] doesn't use commit-reveal voting.
+func (g *Genesis) CommitBlocks(provingSystem uint8) uint64 {
+	for _, cfg := range g.CommitReveal {
+		if cfg.ProvingSystem == provingSystem {
+			return cfg.CommitBlocks
+		}
+	}
+	return 0
+}
+
+// CanaryAuthorityAddress returns false if canary requests are disabled.
+func (g *Genesis) CanaryAuthorityAddress() (codec.Address, bool) {
+	if len(g.CanaryAuthority) == 0 {
+		return codec.EmptyAddress, false
+	}
+	addr, err := codec.ParseAddressBech32(consts.HRP, g.CanaryAuthority)
+	if err != nil {
+		return codec.EmptyAddress, false
+	}
+	return addr, true
+}
+
 func (g *Genesis) GetStateBranchFactor() merkledb.BranchFactor {
 	return g.StateBranchFactor
 }
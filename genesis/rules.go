@@ -8,6 +8,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/sausaging/hyper-pvzk/consts"
 	"github.com/sausaging/hyper-pvzk/requester"
 	"github.com/sausaging/hyper-pvzk/storage"
 	"github.com/sausaging/hypersdk/chain"
@@ -21,7 +22,7 @@ type Rules struct {
 
 	networkID uint32
 	chainID   ids.ID
-	client    *requester.EndpointRequester
+	client    *requester.Pool
 	f         func(ctx context.Context) (map[ids.NodeID]*validators.GetValidatorOutput, map[string]struct{})
 }
 
@@ -30,7 +31,7 @@ func (g *Genesis) Rules(
 	_ int64,
 	networkID uint32,
 	chainID ids.ID,
-	client *requester.EndpointRequester,
+	client *requester.Pool,
 	f func(ctx context.Context) (map[ids.NodeID]*validators.GetValidatorOutput, map[string]struct{}),
 ) *Rules {
 	return &Rules{g, networkID, chainID, client, f}
@@ -120,6 +121,27 @@ func (r *Rules) GetWindowTargetUnits() fees.Dimensions {
 	return r.g.WindowTargetUnits
 }
 
-func (r *Rules) FetchCustom(string) (any, bool) {
-	return r.f, true
+func (r *Rules) GetVerificationReward() uint64 {
+	return r.g.VerificationReward
+}
+
+func (r *Rules) FetchCustom(key string) (any, bool) {
+	switch key {
+	case consts.ValidatorsKey:
+		return r.f, true
+	case consts.VerificationRewardKey:
+		return r.g.VerificationReward, true
+	case consts.CommitRevealKey:
+		return r.g.CommitBlocks, true
+	case consts.CanaryAuthorityKey:
+		return r.g.CanaryAuthorityAddress()
+	case consts.CanaryPenaltyKey:
+		return r.g.CanaryPenalty, true
+	case consts.CanaryRevealKey:
+		return r.g.CanaryRevealBlocks, true
+	case consts.StorageFeePerByteKey:
+		return r.g.StorageFeePerByte, true
+	default:
+		return nil, false
+	}
 }
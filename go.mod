@@ -15,6 +15,7 @@ require (
 	github.com/sausaging/hypersdk v0.0.1-name
 	github.com/spf13/cobra v1.7.0
 	go.uber.org/zap v1.26.0
+	golang.org/x/crypto v0.17.0
 )
 
 require (
@@ -128,7 +129,6 @@ require (
 	go.opentelemetry.io/proto/otlp v0.19.0 // indirect
 	go.uber.org/mock v0.4.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
-	golang.org/x/crypto v0.17.0 // indirect
 	golang.org/x/exp v0.0.0-20231127185646-65229373498e // indirect
 	golang.org/x/mod v0.14.0 // indirect
 	golang.org/x/net v0.19.0 // indirect
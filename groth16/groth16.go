@@ -0,0 +1,80 @@
+// Package groth16 verifies Groth16 proofs over BN254 as produced by snarkjs
+// for circom circuits. Verification is deterministic and runs in-process, so
+// that actions can check proofs during execution.
+package groth16
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+)
+
+// MaxPublicInputs is the largest number of public inputs a proof may have.
+const MaxPublicInputs = 256
+
+var (
+	ErrMalformed       = errors.New("malformed groth16 json")
+	ErrUnsupported     = errors.New("unsupported protocol or curve")
+	ErrPublicInputs    = errors.New("public inputs don't match verifying key")
+	ErrTooManyInputs   = errors.New("too many public inputs")
+	ErrInputOutOfField = errors.New("public input not in scalar field")
+	ErrInvalidProof    = errors.New("invalid groth16 proof")
+)
+
+// VerifyingKey is the verifying key of a circuit.
+type VerifyingKey struct {
+	Alpha *bn256.G1
+	Beta  *bn256.G2
+	Gamma *bn256.G2
+	Delta *bn256.G2
+	// IC has one point more than the circuit has public inputs.
+	IC []*bn256.G1
+}
+
+// NumPublic is the number of public inputs of the circuit.
+func (vk *VerifyingKey) NumPublic() int {
+	return len(vk.IC) - 1
+}
+
+type Proof struct {
+	A *bn256.G1
+	B *bn256.G2
+	C *bn256.G1
+}
+
+// Verify checks [proof] for [inputs] against [vk].
+func Verify(vk *VerifyingKey, proof *Proof, inputs []*big.Int) error {
+	if len(inputs) != vk.NumPublic() {
+		return fmt.Errorf("%w: got %d inputs, expected %d", ErrPublicInputs, len(inputs), vk.NumPublic())
+	}
+	// vk_x = IC[0] + sum(inputs[i] * IC[i+1])
+	x := new(bn256.G1).Set(vk.IC[0])
+	for i, input := range inputs {
+		if input.Sign() < 0 || input.Cmp(bn256.Order) >= 0 {
+			return fmt.Errorf("%w: input %d", ErrInputOutOfField, i)
+		}
+		x.Add(x, new(bn256.G1).ScalarMult(vk.IC[i+1], input))
+	}
+	// e(-A, B) * e(alpha, beta) * e(vk_x, gamma) * e(C, delta) == 1
+	if !bn256.PairingCheck(
+		[]*bn256.G1{new(bn256.G1).Neg(proof.A), vk.Alpha, x, proof.C},
+		[]*bn256.G2{proof.B, vk.Beta, vk.Gamma, vk.Delta},
+	) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// Digest is the sha256 digest of [inputs] as concatenated 32 byte big endian
+// field elements, the public outputs of a verified proof.
+func Digest(inputs []*big.Int) []byte {
+	h := sha256.New()
+	var b [32]byte
+	for _, input := range inputs {
+		h.Write(input.FillBytes(b[:]))
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,229 @@
+package groth16
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+)
+
+const (
+	snarkjsProtocol = "groth16"
+	snarkjsCurve    = "bn128"
+
+	fieldLen = 32
+)
+
+// snarkjs writes points in projective coordinates as decimal strings: G1
+// points as [x, y, z] and G2 points as [[x0, x1], [y0, y1], [z0, z1]], where
+// an element of Fp2 is c0 + c1*u.
+type (
+	g1JSON [3]string
+	g2JSON [3][2]string
+)
+
+type proofJSON struct {
+	A        g1JSON `json:"pi_a"`
+	B        g2JSON `json:"pi_b"`
+	C        g1JSON `json:"pi_c"`
+	Protocol string `json:"protocol"`
+	Curve    string `json:"curve"`
+}
+
+type verifyingKeyJSON struct {
+	Protocol string   `json:"protocol"`
+	Curve    string   `json:"curve"`
+	NPublic  int      `json:"nPublic"`
+	Alpha    g1JSON   `json:"vk_alpha_1"`
+	Beta     g2JSON   `json:"vk_beta_2"`
+	Gamma    g2JSON   `json:"vk_gamma_2"`
+	Delta    g2JSON   `json:"vk_delta_2"`
+	IC       []g1JSON `json:"IC"`
+}
+
+// ParseProof parses a snarkjs proof.json.
+func ParseProof(b []byte) (*Proof, error) {
+	var p proofJSON
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("%w: proof: %w", ErrMalformed, err)
+	}
+	if err := checkProtocol(p.Protocol, p.Curve); err != nil {
+		return nil, err
+	}
+	var (
+		proof Proof
+		err   error
+	)
+	if proof.A, err = p.A.point(); err != nil {
+		return nil, fmt.Errorf("%w: pi_a: %w", ErrMalformed, err)
+	}
+	if proof.B, err = p.B.point(); err != nil {
+		return nil, fmt.Errorf("%w: pi_b: %w", ErrMalformed, err)
+	}
+	if proof.C, err = p.C.point(); err != nil {
+		return nil, fmt.Errorf("%w: pi_c: %w", ErrMalformed, err)
+	}
+	return &proof, nil
+}
+
+// ParseVerifyingKey parses a snarkjs verification_key.json.
+func ParseVerifyingKey(b []byte) (*VerifyingKey, error) {
+	var v verifyingKeyJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("%w: verifying key: %w", ErrMalformed, err)
+	}
+	if err := checkProtocol(v.Protocol, v.Curve); err != nil {
+		return nil, err
+	}
+	if v.NPublic > MaxPublicInputs {
+		return nil, fmt.Errorf("%w: %d", ErrTooManyInputs, v.NPublic)
+	}
+	if v.NPublic < 0 || len(v.IC) != v.NPublic+1 {
+		return nil, fmt.Errorf("%w: %d IC points for %d public inputs", ErrMalformed, len(v.IC), v.NPublic)
+	}
+	var (
+		vk  VerifyingKey
+		err error
+	)
+	if vk.Alpha, err = v.Alpha.point(); err != nil {
+		return nil, fmt.Errorf("%w: vk_alpha_1: %w", ErrMalformed, err)
+	}
+	if vk.Beta, err = v.Beta.point(); err != nil {
+		return nil, fmt.Errorf("%w: vk_beta_2: %w", ErrMalformed, err)
+	}
+	if vk.Gamma, err = v.Gamma.point(); err != nil {
+		return nil, fmt.Errorf("%w: vk_gamma_2: %w", ErrMalformed, err)
+	}
+	if vk.Delta, err = v.Delta.point(); err != nil {
+		return nil, fmt.Errorf("%w: vk_delta_2: %w", ErrMalformed, err)
+	}
+	vk.IC = make([]*bn256.G1, len(v.IC))
+	for i, ic := range v.IC {
+		if vk.IC[i], err = ic.point(); err != nil {
+			return nil, fmt.Errorf("%w: IC[%d]: %w", ErrMalformed, i, err)
+		}
+	}
+	return &vk, nil
+}
+
+// ParsePublicInputs parses a snarkjs public.json, the public inputs of a
+// proof as decimal strings.
+func ParsePublicInputs(b []byte) ([]*big.Int, error) {
+	var s []string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%w: public inputs: %w", ErrMalformed, err)
+	}
+	if len(s) > MaxPublicInputs {
+		return nil, fmt.Errorf("%w: %d", ErrTooManyInputs, len(s))
+	}
+	inputs := make([]*big.Int, len(s))
+	for i, v := range s {
+		input, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return nil, fmt.Errorf("%w: public input %d: %q", ErrMalformed, i, v)
+		}
+		inputs[i] = input
+	}
+	return inputs, nil
+}
+
+// CountPublicInputs returns the number of public inputs in a snarkjs
+// public.json without parsing them, 0 if it isn't a JSON array.
+func CountPublicInputs(b []byte) int {
+	var s []json.RawMessage
+	if err := json.Unmarshal(b, &s); err != nil {
+		return 0
+	}
+	return len(s)
+}
+
+func checkProtocol(protocol string, curve string) error {
+	if protocol != snarkjsProtocol || curve != snarkjsCurve {
+		return fmt.Errorf("%w: %s over %s", ErrUnsupported, protocol, curve)
+	}
+	return nil
+}
+
+// point returns the affine point of [g], rejecting points at infinity and
+// points off the curve.
+func (g g1JSON) point() (*bn256.G1, error) {
+	if err := checkAffine(g[2], "1"); err != nil {
+		return nil, err
+	}
+	b := make([]byte, 2*fieldLen)
+	for i, c := range g[:2] {
+		if err := putField(b[i*fieldLen:], c); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkFinite(b); err != nil {
+		return nil, err
+	}
+	p := new(bn256.G1)
+	if _, err := p.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// point returns the affine point of [g], rejecting points at infinity and
+// points off the twist. Unlike G1, the twist has points outside of the
+// subgroup of prime order: bn256 rejects them when unmarshaling, by checking
+// that multiplying them by [bn256.Order] gives the point at infinity.
+func (g g2JSON) point() (*bn256.G2, error) {
+	if err := checkAffine(g[2][0], "1"); err != nil {
+		return nil, err
+	}
+	if err := checkAffine(g[2][1], "0"); err != nil {
+		return nil, err
+	}
+	// bn256 marshals elements of Fp2 as c1 || c0
+	b := make([]byte, 4*fieldLen)
+	for i, c := range [4]string{g[0][1], g[0][0], g[1][1], g[1][0]} {
+		if err := putField(b[i*fieldLen:], c); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkFinite(b); err != nil {
+		return nil, err
+	}
+	p := new(bn256.G2)
+	if _, err := p.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// checkAffine checks the z coordinate of a point: snarkjs exports affine points,
+// with z = 1.
+func checkAffine(z string, want string) error {
+	if z != want {
+		return fmt.Errorf("point not affine: z = %q", z)
+	}
+	return nil
+}
+
+// checkFinite rejects the affine coordinates (0, 0), which bn256 unmarshals
+// as the point at infinity.
+func checkFinite(b []byte) error {
+	for _, c := range b {
+		if c != 0 {
+			return nil
+		}
+	}
+	return errors.New("point at infinity")
+}
+
+// putField writes the decimal field element [s] to [b] as 32 bytes big
+// endian. Elements beyond the base field are rejected when unmarshaling the
+// point.
+func putField(b []byte, s string) error {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Sign() < 0 || v.BitLen() > 8*fieldLen {
+		return fmt.Errorf("invalid field element %q", s)
+	}
+	v.FillBytes(b[:fieldLen])
+	return nil
+}
@@ -0,0 +1,621 @@
+// Package hub is a reference implementation of the verifier hub protocol the
+// node talks to. It serves the discovery, submission and verify endpoints of
+// the Rust hub and calls the node back with the verdicts of a scriptable
+// [Policy], so that the full verification pipeline can run offline. Messages
+// follow the [protocol] package.
+package hub
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/protocol"
+	hconsts "github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/utils"
+)
+
+var (
+	ErrUnknownRequest  = errors.New("request was not submitted")
+	ErrPolicyNotSet    = errors.New("policy not set")
+	ErrCorruptArtifact = errors.New("corrupt artifact")
+)
+
+type system struct {
+	name      string
+	artifacts []string
+	// inline maps artifacts jobs may carry inline instead to the field they
+	// are carried in. They are only received when that field is empty.
+	inline map[string]string
+	// segmented systems receive the segments following the proof of jobs
+	// proven in more than one segment
+	segmented bool
+}
+
+// systems maps the submission endpoints to the proving system they serve.
+var systems = map[string]*system{
+	protocol.SP1ENDPOINT:      {name: "sp1", artifacts: []string{protocol.ArtifactELF, protocol.ArtifactProof}, segmented: true},
+	protocol.RISCZEROENDPOINT: {name: "risc0", artifacts: []string{protocol.ArtifactProof}, segmented: true},
+	protocol.MIDENENDPOINT: {name: "miden", artifacts: []string{protocol.ArtifactProof}, inline: map[string]string{
+		protocol.ArtifactCode:    "code_front_end",
+		protocol.ArtifactInputs:  "inputs_front_end",
+		protocol.ArtifactOutputs: "outputs_front_end",
+	}},
+	protocol.JOLTENDPOINT: {name: "jolt", artifacts: []string{protocol.ArtifactELF, protocol.ArtifactProof}},
+	protocol.PLONKY2ENDPOINT: {name: "plonky2", artifacts: []string{
+		protocol.ArtifactProof,
+		protocol.ArtifactCommonData,
+		protocol.ArtifactVerifierData,
+	}},
+	protocol.HALO2ENDPOINT: {name: "halo2", artifacts: []string{
+		protocol.ArtifactProof,
+		protocol.ArtifactVerifyingKey,
+		protocol.ArtifactInstances,
+	}},
+	protocol.NOIRENDPOINT: {name: "noir", artifacts: []string{
+		protocol.ArtifactProof,
+		protocol.ArtifactVerifyingKey,
+		protocol.ArtifactPublicInputs,
+	}},
+	protocol.CAIROENDPOINT: {name: "cairo", artifacts: []string{protocol.ArtifactProof}},
+	protocol.NOVAENDPOINT:  {name: "nova", artifacts: []string{protocol.ArtifactProof, protocol.ArtifactVerifyingKey}},
+}
+
+// required are the artifacts of a job submitted with [args], leaving out those
+// carried inline.
+func (s *system) required(args map[string]any) []string {
+	required := slices.Clone(s.artifacts)
+	for name, field := range s.inline {
+		if inline, _ := args[field].(string); len(inline) == 0 {
+			required = append(required, name)
+		}
+	}
+	for i := 1; i < s.segments(args); i++ {
+		required = append(required, protocol.SegmentArtifact(i))
+	}
+	return required
+}
+
+// segments is the number of segments of a job submitted with [args], 0 for
+// systems that don't split proofs in segments.
+func (s *system) segments(args map[string]any) int {
+	if !s.segmented {
+		return 0
+	}
+	segments, _ := args["segments"].(float64)
+	return max(int(segments), 1)
+}
+
+// Request is a verification request as submitted by the node.
+type Request struct {
+	TxID          string
+	ProvingSystem string
+	VerifyType    uint32
+	// Version is the protocol version the node submitted the request with,
+	// 0 if it predates the handshake.
+	Version uint32
+	// Args are the fields of the submission, file paths included.
+	Args map[string]any
+	// Artifacts are the artifact descriptions of the submission, sent from
+	// version 3 on.
+	Artifacts map[string]*protocol.Artifact
+	// Segments is the number of segments the proof is split in, 0 for
+	// proving systems that don't split proofs.
+	Segments int
+
+	required []string
+	data     map[string][]byte
+}
+
+// ProofFilePath is the path the node stored the proof at.
+func (r *Request) ProofFilePath() string {
+	return r.filePath(protocol.ArtifactProof)
+}
+
+func (r *Request) filePath(name string) string {
+	if i := r.segmentOf(name); i != nil && *i > 0 {
+		paths, _ := r.Args["segment_file_paths"].([]any)
+		if *i > len(paths) {
+			return ""
+		}
+		path, _ := paths[*i-1].(string)
+		return path
+	}
+	path, _ := r.Args[name+"_file_path"].(string)
+	return path
+}
+
+// segmentOf returns the segment artifact [name] is, nil if it isn't one or
+// the proof isn't split in segments.
+func (r *Request) segmentOf(name string) *int {
+	for i := 0; i < r.Segments && r.Segments > 1; i++ {
+		if name == protocol.SegmentArtifact(i) {
+			return &i
+		}
+	}
+	return nil
+}
+
+// Artifact returns the content of artifact [name], as received by the hub.
+// Artifacts are received before the policy is evaluated.
+func (r *Request) Artifact(name string) []byte {
+	return r.data[name]
+}
+
+// Segment returns the content of segment [i] of the proof, the first one
+// being the proof itself.
+func (r *Request) Segment(i int) []byte {
+	return r.Artifact(protocol.SegmentArtifact(i))
+}
+
+// status is the status of the job of [r] in [state].
+func (r *Request) status(state protocol.JobState) *protocol.JobStatus {
+	status := &protocol.JobStatus{TxID: r.TxID, State: state}
+	if r.Segments > 1 {
+		status.Segments = r.Segments
+	}
+	return status
+}
+
+// Result is an outcome submitted to the node.
+type Result struct {
+	TxID    string
+	Outcome consts.Outcome
+	Err     error // set if the node couldn't be reached
+}
+
+type Config struct {
+	// ListenerPort is the port the node listens for results on. It is
+	// handed to the node on /ping.
+	ListenerPort string
+	// NodeURI overrides where results are submitted to, which defaults to
+	// the local [ListenerPort].
+	NodeURI string
+	Policy  Policy
+	// Versions are the protocol versions advertised on /ping, which default
+	// to every version of the [protocol] package.
+	Versions []uint32
+}
+
+// Hub serves the verifier hub protocol. It is an [http.Handler], so it can be
+// mounted on a test server or served with [Hub.Serve].
+type Hub struct {
+	config *Config
+	mux    *http.ServeMux
+	client *http.Client
+
+	l        sync.Mutex
+	policy   Policy
+	pending  map[string]*Request
+	verified map[string]bool
+	jobs     map[string]*protocol.JobStatus
+	// changed is closed and replaced whenever a job changes state
+	changed chan struct{}
+	results []*Result
+	wg      sync.WaitGroup
+}
+
+func New(config *Config) *Hub {
+	h := &Hub{
+		config:   config,
+		mux:      http.NewServeMux(),
+		client:   &http.Client{Timeout: 8 * time.Second},
+		policy:   config.Policy,
+		pending:  make(map[string]*Request),
+		verified: make(map[string]bool),
+		jobs:     make(map[string]*protocol.JobStatus),
+		changed:  make(chan struct{}),
+	}
+	if config.Versions == nil {
+		config.Versions = protocol.Versions()
+	}
+	h.mux.HandleFunc(protocol.PINGENDPOINT, h.ping)
+	h.mux.HandleFunc(protocol.PINGSINGLEENDPOINT, h.pingSingle)
+	h.mux.HandleFunc(protocol.VERIFYENDPOINT, h.verify)
+	if slices.ContainsFunc(config.Versions, func(v uint32) bool { return v >= protocol.StatusVersion }) {
+		h.mux.HandleFunc(protocol.STATUSENDPOINT, h.status)
+	}
+	for endpoint, sys := range systems {
+		h.mux.HandleFunc(endpoint, h.submit(sys))
+	}
+	return h
+}
+
+// ServeHTTP refuses requests sent with a protocol version the hub doesn't
+// advertise.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if header := r.Header.Get(protocol.VersionHeader); len(header) > 0 {
+		v, err := strconv.ParseUint(header, 10, 32)
+		if err != nil || !slices.Contains(h.config.Versions, uint32(v)) {
+			http.Error(w, fmt.Sprintf("%s: %s", protocol.ErrUnsupportedVersion, header), http.StatusBadRequest)
+			return
+		}
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// requestVersion is the version [r] was sent with. Requests are checked
+// against the advertised versions before reaching the handlers.
+func requestVersion(r *http.Request) uint32 {
+	v, _ := strconv.ParseUint(r.Header.Get(protocol.VersionHeader), 10, 32)
+	return uint32(v)
+}
+
+// Serve serves the hub on [l] until [ctx] is done.
+func (h *Hub) Serve(ctx context.Context, l net.Listener) error {
+	srv := &http.Server{Handler: h}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	h.wg.Wait()
+	return nil
+}
+
+// SetPolicy replaces the policy for the requests verified from now on.
+func (h *Hub) SetPolicy(p Policy) {
+	h.l.Lock()
+	h.policy = p
+	h.l.Unlock()
+}
+
+// Requests returns the requests submitted so far, by tx ID.
+func (h *Hub) Requests() map[string]*Request {
+	h.l.Lock()
+	defer h.l.Unlock()
+	requests := make(map[string]*Request, len(h.pending))
+	for txID, r := range h.pending {
+		requests[txID] = r
+	}
+	return requests
+}
+
+// Results returns the outcomes submitted to the node so far, in order.
+func (h *Hub) Results() []*Result {
+	h.l.Lock()
+	defer h.l.Unlock()
+	return append([]*Result(nil), h.results...)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (h *Hub) ping(w http.ResponseWriter, r *http.Request) {
+	// the node reaches the verifier on the port it reached the hub on
+	_, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, &protocol.PingReply{
+		Success:   true,
+		RustPort:  port,
+		UnintPort: h.config.ListenerPort,
+		Versions:  h.config.Versions,
+	})
+}
+
+func (*Hub) pingSingle(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, &protocol.PingSingleReply{Success: true})
+}
+
+func (h *Hub) submit(sys *system) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var (
+			args map[string]any
+			job  struct {
+				Artifacts map[string]*protocol.Artifact `json:"artifacts"`
+			}
+		)
+		if err := json.Unmarshal(body, &args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(body, &job); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		txID, _ := args["tx_id"].(string)
+		if len(txID) == 0 {
+			http.Error(w, "tx_id not provided", http.StatusBadRequest)
+			return
+		}
+		req := &Request{
+			TxID:          txID,
+			ProvingSystem: sys.name,
+			Version:       requestVersion(r),
+			Args:          args,
+			Artifacts:     job.Artifacts,
+			Segments:      sys.segments(args),
+			required:      sys.required(args),
+		}
+		h.l.Lock()
+		h.pending[txID] = req
+		if _, ok := h.jobs[txID]; !ok {
+			h.setStatus(req.status(protocol.JobQueued))
+		}
+		h.l.Unlock()
+		writeJSON(w, &protocol.SubmitReply{IsSubmitted: true})
+	}
+}
+
+func (h *Hub) verify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var args protocol.VerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.l.Lock()
+	req, ok := h.pending[args.TxID]
+	policy := h.policy
+	// retried and failed over requests are verified once
+	duplicate := h.verified[args.TxID]
+	h.l.Unlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("%s: %s", ErrUnknownRequest, args.TxID), http.StatusNotFound)
+		return
+	}
+	if duplicate {
+		writeJSON(w, &protocol.SubmitReply{IsSubmitted: true})
+		return
+	}
+	if policy == nil {
+		http.Error(w, ErrPolicyNotSet.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	req.VerifyType = args.VerifyType
+	verdict := h.receive(req)
+	if verdict == nil {
+		verdict = policy(req)
+	}
+	if verdict.Err != nil {
+		http.Error(w, verdict.Err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	h.l.Lock()
+	h.verified[args.TxID] = true
+	h.setStatus(req.status(protocol.JobVerifying))
+	h.l.Unlock()
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		time.Sleep(verdict.After)
+		h.submitResult(req, verdict)
+	}()
+	writeJSON(w, &protocol.SubmitReply{IsSubmitted: true})
+}
+
+// setStatus records the status of a job and wakes up the status streams. The
+// caller holds [h.l].
+func (h *Hub) setStatus(status *protocol.JobStatus) {
+	h.jobs[status.TxID] = status
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+// progress reports the segments of [req] verified before reaching [verdict],
+// one at a time, so that status streams follow the verification of long
+// segmented proofs.
+func (h *Hub) progress(req *Request, verdict *Verdict) int {
+	verified := 0
+	switch {
+	case req.Segments <= 1:
+		return 0
+	case verdict.FailedSegment != nil:
+		verified = *verdict.FailedSegment
+	case verdict.Outcome == consts.OutcomeValid:
+		verified = req.Segments
+	}
+	for i := 1; i <= verified; i++ {
+		status := req.status(protocol.JobVerifying)
+		status.VerifiedSegments = i
+		h.l.Lock()
+		h.setStatus(status)
+		h.l.Unlock()
+	}
+	return verified
+}
+
+func (h *Hub) jobStatus(txID string) (*protocol.JobStatus, <-chan struct{}) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	return h.jobs[txID], h.changed
+}
+
+func (h *Hub) status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	txID := r.URL.Query().Get("tx_id")
+	status, changed := h.jobStatus(txID)
+	if status == nil {
+		http.Error(w, fmt.Sprintf("%s: %s", ErrUnknownRequest, txID), http.StatusNotFound)
+		return
+	}
+	if watch, _ := strconv.ParseBool(r.URL.Query().Get("watch")); !watch {
+		writeJSON(w, status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for {
+		if err := enc.Encode(status); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if status.State == protocol.JobDone {
+			return
+		}
+		// other jobs wake the stream up too
+		for {
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+			var next *protocol.JobStatus
+			next, changed = h.jobStatus(txID)
+			if next != status {
+				status = next
+				break
+			}
+		}
+	}
+}
+
+func (h *Hub) nodeURI() string {
+	if len(h.config.NodeURI) > 0 {
+		return h.config.NodeURI
+	}
+	return "http://127.0.0.1:" + h.config.ListenerPort
+}
+
+func (h *Hub) submitResult(req *Request, verdict *Verdict) {
+	outcome := verdict.Outcome
+	result := &Result{TxID: req.TxID, Outcome: outcome}
+	verified := h.progress(req, verdict)
+	defer func() {
+		status := req.status(protocol.JobDone)
+		status.Outcome = &outcome
+		status.VerifiedSegments = verified
+		if outcome != consts.OutcomeValid {
+			status.FailedSegment = verdict.FailedSegment
+		}
+		h.l.Lock()
+		h.results = append(h.results, result)
+		h.setStatus(status)
+		h.l.Unlock()
+	}()
+	body, err := json.Marshal(&protocol.SubmitResult{
+		Version: req.Version,
+		TxID:    req.TxID,
+		IsValid: outcome == consts.OutcomeValid,
+		Outcome: &outcome,
+		// hex of a nil digest is left out
+		OutputsDigest: hex.EncodeToString(verdict.OutputsDigest),
+	})
+	if err != nil {
+		result.Err = err
+		return
+	}
+	resp, err := h.client.Post(h.nodeURI()+protocol.SUBMITRESULTENDPOINT, "application/json", bytes.NewReader(body))
+	if err != nil {
+		result.Err = err
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		result.Err = fmt.Errorf("node replied %s", resp.Status)
+	}
+}
+
+// receive gathers the artifacts of [req] and checks them against their
+// descriptions. It returns the verdict to report instead of evaluating the
+// policy if an artifact is missing or isn't the one described, along with the
+// segment it is for segmented proofs.
+func (h *Hub) receive(req *Request) *Verdict {
+	data := make(map[string][]byte, len(req.required))
+	for _, name := range req.required {
+		var (
+			artifact = req.Artifacts[name]
+			path     = req.filePath(name)
+			b        []byte
+			err      error
+		)
+		fail := func(o consts.Outcome) *Verdict {
+			return &Verdict{Outcome: o, FailedSegment: req.segmentOf(name)}
+		}
+		switch {
+		case artifact != nil && len(artifact.Data) > 0:
+			b = artifact.Data
+		case artifact != nil && len(artifact.URL) > 0:
+			b, err = h.fetch(artifact.URL)
+		case len(path) > 0:
+			b, err = readArtifact(path)
+		default:
+			return fail(consts.OutcomeArtifactMissing)
+		}
+		switch {
+		case errors.Is(err, ErrCorruptArtifact):
+			return fail(consts.OutcomeMalformedArtifact)
+		case err != nil:
+			return fail(consts.OutcomeArtifactMissing)
+		}
+		if artifact != nil {
+			if err := artifact.Check(b); err != nil {
+				return fail(consts.OutcomeMalformedArtifact)
+			}
+		}
+		data[name] = b
+	}
+	h.l.Lock()
+	req.data = data
+	h.l.Unlock()
+	return nil
+}
+
+// fetch downloads an artifact from a fetch URL handed out by the node.
+func (h *Hub) fetch(url string) ([]byte, error) {
+	resp, err := h.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching artifact: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// readArtifact reads an artifact stored by the node's fileDB, which prefixes
+// the data with its checksum.
+func readArtifact(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < hconsts.IDLen {
+		return nil, fmt.Errorf("%w: %s", ErrCorruptArtifact, path)
+	}
+	data := b[hconsts.IDLen:]
+	if utils.ToID(data) != ids.ID(b[:hconsts.IDLen]) {
+		return nil, fmt.Errorf("%w: %s", ErrCorruptArtifact, path)
+	}
+	return data, nil
+}
@@ -0,0 +1,123 @@
+package hub
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/protocol"
+)
+
+// Verdict is what a [Policy] decides for a request.
+type Verdict struct {
+	Outcome consts.Outcome
+	// OutputsDigest is reported with valid outcomes as the digest of the
+	// public outputs of the proof.
+	OutputsDigest []byte
+	// FailedSegment is the segment of a segmented proof the outcome was
+	// reached on.
+	FailedSegment *int
+	// After delays submitting the outcome to the node.
+	After time.Duration
+	// Err makes the hub refuse to verify the request, as an overloaded or
+	// broken verifier would. The node sees the error on its /verify call.
+	Err error
+}
+
+// Policy decides the verdict on a verification request. Policies are
+// evaluated when the node asks for the request to be verified.
+type Policy func(*Request) *Verdict
+
+// Outcome always reports [o].
+func Outcome(o consts.Outcome) Policy {
+	return func(*Request) *Verdict {
+		return &Verdict{Outcome: o}
+	}
+}
+
+// AlwaysValid accepts every proof.
+func AlwaysValid() Policy {
+	return Outcome(consts.OutcomeValid)
+}
+
+// AlwaysInvalid rejects every proof.
+func AlwaysInvalid() Policy {
+	return Outcome(consts.OutcomeInvalid)
+}
+
+// Error refuses every request with [err].
+func Error(err error) Policy {
+	return func(*Request) *Verdict {
+		return &Verdict{Err: err}
+	}
+}
+
+// Delay submits the verdict of [p] [d] later.
+func Delay(d time.Duration, p Policy) Policy {
+	return func(r *Request) *Verdict {
+		v := p(r)
+		v.After += d
+		return v
+	}
+}
+
+// HashBased looks the sha256 of the proof up in [outcomes], and falls back to
+// [fallback] for proofs it doesn't know.
+func HashBased(outcomes map[[sha256.Size]byte]consts.Outcome, fallback Policy) Policy {
+	return func(r *Request) *Verdict {
+		if o, ok := outcomes[sha256.Sum256(r.Artifact(protocol.ArtifactProof))]; ok {
+			return &Verdict{Outcome: o}
+		}
+		return fallback(r)
+	}
+}
+
+// Outputs reports the digest [digest] returns for the requests [p] finds
+// valid.
+func Outputs(digest func(*Request) []byte, p Policy) Policy {
+	return func(r *Request) *Verdict {
+		v := p(r)
+		if v.Err == nil && v.Outcome == consts.OutcomeValid {
+			v.OutputsDigest = digest(r)
+		}
+		return v
+	}
+}
+
+// Segmented verifies the segments of a request in order with [verify], which
+// is handed the segment before each one, nil for the first, to check that the
+// execution continues where that one stopped. It reports the first segment
+// [verify] doesn't find valid.
+func Segmented(verify func(prev, segment []byte) consts.Outcome) Policy {
+	return func(r *Request) *Verdict {
+		var prev []byte
+		for i := 0; i < max(r.Segments, 1); i++ {
+			segment := r.Segment(i)
+			if o := verify(prev, segment); o != consts.OutcomeValid {
+				failed := i
+				return &Verdict{Outcome: o, FailedSegment: &failed}
+			}
+			prev = segment
+		}
+		return &Verdict{Outcome: consts.OutcomeValid}
+	}
+}
+
+// Sequence uses each of [policies] for one request in turn, and the last one
+// for every request after that.
+func Sequence(policies ...Policy) Policy {
+	var (
+		l    sync.Mutex
+		next int
+	)
+	return func(r *Request) *Verdict {
+		l.Lock()
+		p := policies[next]
+		if next < len(policies)-1 {
+			next++
+		}
+		l.Unlock()
+		return p(r)
+	}
+}
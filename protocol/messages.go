@@ -0,0 +1,282 @@
+package protocol
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/sausaging/hyper-pvzk/consts"
+)
+
+var (
+	ErrDigestMismatch       = errors.New("artifact digest mismatch")
+	ErrInvalidOutputsDigest = errors.New("invalid outputs digest")
+)
+
+// PingReply is the reply of the hub on /ping.
+type PingReply struct {
+	Success bool `json:"success"`
+	// RustPort is the port the verifier is served on.
+	RustPort string `json:"rust_port"`
+	// UnintPort is the port the node listens for results on.
+	UnintPort string `json:"uinit_port"`
+	// Versions are the protocol versions the hub speaks. Hubs predating the
+	// handshake leave it out.
+	Versions []uint32 `json:"versions,omitempty"`
+}
+
+type PingSingleReply struct {
+	Success bool `json:"success"`
+}
+
+// Names of the artifacts of a job. The file path of an artifact is sent as
+// <name>_file_path.
+const (
+	ArtifactELF          = "elf"
+	ArtifactProof        = "proof"
+	ArtifactCommonData   = "common_data"
+	ArtifactVerifierData = "verifier_data"
+	ArtifactVerifyingKey = "verifying_key"
+	ArtifactInstances    = "instances"
+	ArtifactPublicInputs = "public_inputs"
+	// Miden programs, inputs and outputs are sent as artifacts when they are
+	// registered rather than carried inline by the request.
+	ArtifactCode    = "code"
+	ArtifactInputs  = "inputs"
+	ArtifactOutputs = "outputs"
+)
+
+// SegmentArtifact names segment [i] of a proof split in segments, such as
+// the shards of an SP1 execution or the segment receipts of a RISC Zero one.
+// The first segment is the proof.
+func SegmentArtifact(i int) string {
+	if i == 0 {
+		return ArtifactProof
+	}
+	return "segment_" + strconv.Itoa(i)
+}
+
+// Artifact describes an artifact of a job. It is sent either with its Data,
+// with a URL it can be fetched from until the URL expires, or with neither
+// when the verifier reads it from the file path of the job. Verifiers check
+// the artifact against Digest on receipt, whichever way it was sent.
+type Artifact struct {
+	// Digest is the hex encoded sha256 of the artifact.
+	Digest string `json:"digest"`
+	Size   uint64 `json:"size"`
+	Data   []byte `json:"data,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+func NewArtifact(data []byte) *Artifact {
+	digest := sha256.Sum256(data)
+	return &Artifact{
+		Digest: hex.EncodeToString(digest[:]),
+		Size:   uint64(len(data)),
+	}
+}
+
+// Check returns [ErrDigestMismatch] if [data] isn't the artifact described.
+func (a *Artifact) Check(data []byte) error {
+	digest := sha256.Sum256(data)
+	if uint64(len(data)) != a.Size || hex.EncodeToString(digest[:]) != a.Digest {
+		return fmt.Errorf("%w: expected %s, found %x", ErrDigestMismatch, a.Digest, digest)
+	}
+	return nil
+}
+
+// Jobs are submitted to the endpoint of their proving system. File paths
+// point to artifacts stored by the node's fileDB, and are left out when the
+// node doesn't share its filesystem with the verifier. Artifacts are keyed by
+// name, and only sent from version 3 on.
+
+// SP1Job carries the hex encoded verifying key hash pinned for the image, so
+// that verifiers only accept proofs of that program.
+type SP1Job struct {
+	TxID          string `json:"tx_id"`
+	VKeyHash      string `json:"vkey_hash,omitempty"`
+	ELFFilePath   string `json:"elf_file_path,omitempty"`
+	ProofFilePath string `json:"proof_file_path,omitempty"`
+	Segmented
+	Artifacts map[string]*Artifact `json:"artifacts,omitempty"`
+}
+
+// RiscZeroJob carries the hex encoded RISC Zero image ID pinned for the
+// image.
+type RiscZeroJob struct {
+	TxID            string `json:"tx_id"`
+	RiscZeroImageID string `json:"risc_zero_image_id"`
+	ProofFilePath   string `json:"proof_file_path,omitempty"`
+	Segmented
+	Artifacts map[string]*Artifact `json:"artifacts,omitempty"`
+}
+
+// Segmented describes the segments of a job proven in more than one segment,
+// the first of which is the proof of the job. Verifiers check every segment,
+// in order, and that each one continues the execution where the one before
+// it stopped. Segment i is sent as artifact [SegmentArtifact] i, and its file
+// path is SegmentFilePaths[i-1]. Single segment jobs leave it out.
+type Segmented struct {
+	Segments         int      `json:"segments,omitempty"`
+	SegmentFilePaths []string `json:"segment_file_paths,omitempty"`
+}
+
+// MidenJob carries the program, inputs and outputs inline, or leaves them
+// empty and sends them as the code, inputs and outputs artifacts.
+type MidenJob struct {
+	TxID            string               `json:"tx_id"`
+	CodeFrontEnd    string               `json:"code_front_end"`
+	InputsFrontEnd  string               `json:"inputs_front_end"`
+	OutputsFrontEnd string               `json:"outputs_front_end"`
+	ProofFilePath   string               `json:"proof_file_path,omitempty"`
+	CodeFilePath    string               `json:"code_file_path,omitempty"`
+	InputsFilePath  string               `json:"inputs_file_path,omitempty"`
+	OutputsFilePath string               `json:"outputs_file_path,omitempty"`
+	Artifacts       map[string]*Artifact `json:"artifacts,omitempty"`
+}
+
+type JoltJob struct {
+	TxID          string               `json:"tx_id"`
+	ELFFilePath   string               `json:"elf_file_path,omitempty"`
+	ProofFilePath string               `json:"proof_file_path,omitempty"`
+	Artifacts     map[string]*Artifact `json:"artifacts,omitempty"`
+}
+
+type Plonky2Job struct {
+	TxID                 string               `json:"tx_id"`
+	ProofFilePath        string               `json:"proof_file_path,omitempty"`
+	CommonDataFilePath   string               `json:"common_data_file_path,omitempty"`
+	VerifierDataFilePath string               `json:"verifier_data_file_path,omitempty"`
+	Artifacts            map[string]*Artifact `json:"artifacts,omitempty"`
+}
+
+// Halo2Job is a Halo2 proof with the KZG commitment scheme, verified against
+// the verifying key and public instances of its circuit.
+type Halo2Job struct {
+	TxID                 string               `json:"tx_id"`
+	ProofFilePath        string               `json:"proof_file_path,omitempty"`
+	VerifyingKeyFilePath string               `json:"verifying_key_file_path,omitempty"`
+	InstancesFilePath    string               `json:"instances_file_path,omitempty"`
+	Artifacts            map[string]*Artifact `json:"artifacts,omitempty"`
+}
+
+// NoirJob is a Barretenberg proof of a Noir circuit, verified against the
+// verification key and public inputs of the circuit with the verifier of
+// [Flavor], "ultra_plonk" or "ultra_honk".
+type NoirJob struct {
+	TxID                 string               `json:"tx_id"`
+	Flavor               string               `json:"flavor"`
+	ProofFilePath        string               `json:"proof_file_path,omitempty"`
+	VerifyingKeyFilePath string               `json:"verifying_key_file_path,omitempty"`
+	PublicInputsFilePath string               `json:"public_inputs_file_path,omitempty"`
+	Artifacts            map[string]*Artifact `json:"artifacts,omitempty"`
+}
+
+// CairoJob is a Stone STARK proof of a Cairo program execution. Verifiers
+// check it is a proof of the program with the hex encoded [ProgramHash]
+// pinned for the image, and report the hash of the program output as the
+// outputs digest of the result.
+type CairoJob struct {
+	TxID          string               `json:"tx_id"`
+	ProgramHash   string               `json:"program_hash"`
+	ProofFilePath string               `json:"proof_file_path,omitempty"`
+	Artifacts     map[string]*Artifact `json:"artifacts,omitempty"`
+}
+
+// NovaJob is a Nova IVC proof that the computation of instance [InstanceID]
+// ran [Step] steps from the hex encoded [InitialState]. Verifiers report the
+// digest of the state it reached as the outputs digest of the result.
+type NovaJob struct {
+	TxID                 string               `json:"tx_id"`
+	InstanceID           string               `json:"instance_id"`
+	Step                 uint64               `json:"step"`
+	InitialState         string               `json:"initial_state"`
+	ProofFilePath        string               `json:"proof_file_path,omitempty"`
+	VerifyingKeyFilePath string               `json:"verifying_key_file_path,omitempty"`
+	Artifacts            map[string]*Artifact `json:"artifacts,omitempty"`
+}
+
+// SubmitReply acknowledges a job submission or a /verify request.
+type SubmitReply struct {
+	IsSubmitted bool `json:"is_submitted"`
+}
+
+// VerifyRequest asks the hub to verify a submitted job.
+type VerifyRequest struct {
+	TxID       string `json:"tx_id"`
+	VerifyType uint32 `json:"verify_type"`
+}
+
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobVerifying JobState = "verifying"
+	JobDone      JobState = "done"
+)
+
+// JobStatus is the reply on /status. With ?watch=true the hub streams one
+// JobStatus per line, each time the job changes state, until it is done.
+type JobStatus struct {
+	TxID  string   `json:"tx_id"`
+	State JobState `json:"state"`
+	// Outcome is set once the job is done.
+	Outcome *consts.Outcome `json:"outcome,omitempty"`
+	// OutputsDigest is the hex encoded sha256 of the public outputs of a
+	// valid proof, such as SP1 public values or a RISC Zero journal.
+	// Validators vote on it along with the outcome.
+	OutputsDigest string `json:"outputs_digest,omitempty"`
+	// Segments is the number of segments of a segmented job, and
+	// VerifiedSegments how many of them, in order, were verified so far.
+	Segments         int `json:"segments,omitempty"`
+	VerifiedSegments int `json:"verified_segments,omitempty"`
+	// FailedSegment is the segment that failed verification or doesn't
+	// continue the one before it, set once a segmented job is done with
+	// another outcome than valid.
+	FailedSegment *int `json:"failed_segment,omitempty"`
+}
+
+// SubmitResult is the result callback the hub posts to the node.
+type SubmitResult struct {
+	// Version is the negotiated version. Hubs predating the handshake leave
+	// it out.
+	Version uint32 `json:"version,omitempty"`
+	TxID    string `json:"tx_id"`
+	IsValid bool   `json:"is_valid"`
+	// Outcome takes precedence over IsValid when set, so that verifiers can
+	// report why they couldn't reach a verdict.
+	Outcome *consts.Outcome `json:"outcome,omitempty"`
+	// OutputsDigest is the hex encoded sha256 of the public outputs of a
+	// valid proof, such as SP1 public values or a RISC Zero journal.
+	// Validators vote on it along with the outcome.
+	OutputsDigest string `json:"outputs_digest,omitempty"`
+}
+
+func (s *SubmitResult) Result() consts.Outcome {
+	switch {
+	case s.Outcome != nil:
+		return *s.Outcome
+	case s.IsValid:
+		return consts.OutcomeValid
+	default:
+		return consts.OutcomeInvalid
+	}
+}
+
+// Outputs decodes the digest of the public outputs reported with the result.
+// It is nil unless the result is valid.
+func (s *SubmitResult) Outputs() ([]byte, error) {
+	if len(s.OutputsDigest) == 0 || s.Result() != consts.OutcomeValid {
+		return nil, nil
+	}
+	digest, err := hex.DecodeString(s.OutputsDigest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOutputsDigest, err)
+	}
+	if len(digest) != sha256.Size {
+		return nil, fmt.Errorf("%w: expected %d bytes, found %d", ErrInvalidOutputsDigest, sha256.Size, len(digest))
+	}
+	return digest, nil
+}
@@ -0,0 +1,110 @@
+// Package protocol defines the versioned JSON protocol between the node and
+// the verifier hub. The messages are described by schema.json, next to this
+// file, which verifier implementations in other languages are checked
+// against.
+//
+// The node pings the hub at startup and picks the highest version both sides
+// support (see [Negotiate]). Every request the node sends afterwards carries
+// the negotiated version in [VersionHeader], and results the hub submits back
+// carry it in [SubmitResult.Version].
+//
+// Version 1 is the unversioned protocol of the original Rust hub: a hub that
+// doesn't advertise any version on /ping speaks version 1. Version 2 adds the
+// version handshake and the /status endpoint. Version 3 describes the
+// artifacts of a job with an [Artifact], so that verifiers not sharing the
+// node's filesystem can be sent their content or a URL to fetch them from.
+package protocol
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// Version is the newest protocol version the node speaks.
+	Version uint32 = 3
+	// MinVersion is the oldest protocol version the node still speaks.
+	MinVersion uint32 = 1
+	// LegacyVersion is assumed for hubs that don't advertise their versions.
+	LegacyVersion uint32 = 1
+	// StatusVersion is the first version serving /status.
+	StatusVersion uint32 = 2
+	// ArtifactsVersion is the first version describing job artifacts.
+	ArtifactsVersion uint32 = 3
+)
+
+// VersionHeader carries the negotiated version on every request.
+const VersionHeader = "X-Pvzk-Protocol-Version"
+
+const (
+	PINGENDPOINT       = "/ping"
+	PINGSINGLEENDPOINT = "/ping-single"
+	SP1ENDPOINT        = "/sp1-verify"
+	RISCZEROENDPOINT   = "/risc0-verify"
+	MIDENENDPOINT      = "/miden-verify"
+	JOLTENDPOINT       = "/jolt-verify"
+	PLONKY2ENDPOINT    = "/plonky2-verify"
+	HALO2ENDPOINT      = "/halo2-verify"
+	NOIRENDPOINT       = "/noir-verify"
+	CAIROENDPOINT      = "/cairo-verify"
+	NOVAENDPOINT       = "/nova-verify"
+	VERIFYENDPOINT     = "/verify"
+	STATUSENDPOINT     = "/status"
+
+	// SUBMITRESULTENDPOINT is served by the node, on the listener port the
+	// hub hands out on /ping.
+	SUBMITRESULTENDPOINT = "/submit-result"
+)
+
+// Verify types identify the proving system of a /verify request.
+const (
+	SP1VERIFY      uint32 = 1
+	MIDENVERIFY    uint32 = 2
+	RISCZEROVERIFY uint32 = 3
+	JOLTVERIFY     uint32 = 4
+	PLONKY2VERIFY  uint32 = 5
+	HALO2VERIFY    uint32 = 6
+	NOIRVERIFY     uint32 = 7
+	CAIROVERIFY    uint32 = 8
+	NOVAVERIFY     uint32 = 9
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported protocol version")
+
+// Versions returns the versions the node speaks, newest first.
+func Versions() []uint32 {
+	versions := make([]uint32, 0, Version-MinVersion+1)
+	for v := Version; v >= MinVersion; v-- {
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// Supported returns true if the node speaks [version].
+func Supported(version uint32) bool {
+	return version >= MinVersion && version <= Version
+}
+
+// Negotiate picks the newest version out of [remote], the versions advertised
+// by the hub, that the node speaks.
+func Negotiate(remote []uint32) (uint32, error) {
+	if len(remote) == 0 {
+		remote = []uint32{LegacyVersion}
+	}
+	var negotiated uint32
+	for _, v := range remote {
+		if Supported(v) && v > negotiated {
+			negotiated = v
+		}
+	}
+	if negotiated == 0 {
+		return 0, fmt.Errorf("%w: hub speaks %v, node speaks %v", ErrUnsupportedVersion, remote, Versions())
+	}
+	return negotiated, nil
+}
+
+// Accepts returns true if [version], as found on a message, is one the
+// receiver speaks. Messages without a version predate the handshake.
+func Accepts(version uint32) bool {
+	return version == 0 || Supported(version)
+}
@@ -30,8 +30,19 @@ func init() {
 		consts.ActionRegistry.Register((&actions.Miden{}).GetTypeID(), actions.UnmarshalMiden, false),
 		consts.ActionRegistry.Register((&actions.Jolt{}).GetTypeID(), actions.UnmarshalJolt, false),
 		consts.ActionRegistry.Register((&actions.PLONKY2{}).GetTypeID(), actions.UnmarshalPLONKY2, false),
+		consts.ActionRegistry.Register((&actions.Halo2{}).GetTypeID(), actions.UnmarshalHalo2, false),
+		consts.ActionRegistry.Register((&actions.Noir{}).GetTypeID(), actions.UnmarshalNoir, false),
+		consts.ActionRegistry.Register((&actions.Groth16{}).GetTypeID(), actions.UnmarshalGroth16, false),
+		consts.ActionRegistry.Register((&actions.Cairo{}).GetTypeID(), actions.UnmarshalCairo, false),
+		consts.ActionRegistry.Register((&actions.IVCStep{}).GetTypeID(), actions.UnmarshalIVCStep, false),
+		consts.ActionRegistry.Register((&actions.OpenIVC{}).GetTypeID(), actions.UnmarshalOpenIVC, false),
+		consts.ActionRegistry.Register((&actions.RecordIVCStep{}).GetTypeID(), actions.UnmarshalRecordIVCStep, false),
 		consts.ActionRegistry.Register((&actions.ValidatorVote{}).GetTypeID(), actions.UnmarshalValidatorVote, false),
 		// consts.ActionRegistry.Register((&actions.Gnark{}).GetTypeID(), actions.UnmarshalGnark, false),
+		consts.ActionRegistry.Register((&actions.ClaimRewards{}).GetTypeID(), actions.UnmarshalClaimRewards, false),
+		consts.ActionRegistry.Register((&actions.CommitVote{}).GetTypeID(), actions.UnmarshalCommitVote, false),
+		consts.ActionRegistry.Register((&actions.CommitCanary{}).GetTypeID(), actions.UnmarshalCommitCanary, false),
+		consts.ActionRegistry.Register((&actions.RevealCanary{}).GetTypeID(), actions.UnmarshalRevealCanary, false),
 		// When registering new auth, ALWAYS make sure to append at the end.
 		consts.AuthRegistry.Register((&auth.ED25519{}).GetTypeID(), auth.UnmarshalED25519, false),
 		consts.AuthRegistry.Register((&auth.SECP256R1{}).GetTypeID(), auth.UnmarshalSECP256R1, false),
@@ -0,0 +1,217 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sausaging/hyper-pvzk/protocol"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+var (
+	ErrCircuitOpen     = errors.New("circuit breaker open")
+	ErrUnexpectedReply = errors.New("unexpected reply")
+)
+
+// Backoff bounds the retries of a request. The delay doubles after every
+// failed attempt, up to [Max].
+type Backoff struct {
+	Attempts int
+	Initial  time.Duration
+	Max      time.Duration
+}
+
+var DefaultBackoff = Backoff{
+	Attempts: 4,
+	Initial:  100 * time.Millisecond,
+	Max:      2 * time.Second,
+}
+
+func (b Backoff) delay(attempt int) time.Duration {
+	d := b.Initial << attempt
+	if d <= 0 || d > b.Max {
+		return b.Max
+	}
+	return d
+}
+
+// breaker stops sending requests to an endpoint after [threshold] failures
+// in a row. Once [cooldown] has passed a single trial request is let through,
+// closing the breaker again if it succeeds.
+type breaker struct {
+	l         sync.Mutex
+	threshold int
+	cooldown  time.Duration
+
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+func (b *breaker) allow() bool {
+	b.l.Lock()
+	defer b.l.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.trial || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+func (b *breaker) open() bool {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.failures >= b.threshold && (b.trial || time.Since(b.openedAt) < b.cooldown)
+}
+
+// record returns true if the breaker tripped. Only errors the endpoint is at
+// fault for count as failures, an endpoint rejecting a request still answered.
+func (b *breaker) record(err error) bool {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.trial = false
+	if errors.Is(err, context.Canceled) {
+		// the caller gave up, which tells nothing about the endpoint
+		return false
+	}
+	if !faulty(err) {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+// statusError is returned for non 2xx replies. Only server side errors are
+// retried, the request itself is at fault otherwise.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: status %d: %s", ErrUnexpectedReply, e.code, e.body)
+}
+
+// faulty reports whether the endpoint is at fault for [err]: it couldn't be
+// reached, timed out or failed on its side.
+func faulty(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError
+	}
+	return true
+}
+
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError || se.code == http.StatusTooManyRequests
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Post sends [args] as JSON to [path] and decodes the reply into [reply],
+// retrying with [Backoff] while the endpoint's circuit breaker allows it.
+// Every attempt carries [idempotencyKey], so the verifier can drop
+// submissions it already accepted.
+func (e *EndpointRequester) Post(ctx context.Context, path string, idempotencyKey string, args any, reply any) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request args: %w", err)
+	}
+	var lastErr error
+	for attempt := 0; attempt < e.Backoff.Attempts; attempt++ {
+		if attempt > 0 {
+			retries.WithLabelValues(e.Uri).Inc()
+			select {
+			case <-time.After(e.Backoff.delay(attempt - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if !e.breaker.allow() {
+			breakerRejections.WithLabelValues(e.Uri).Inc()
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, e.Uri)
+		}
+		lastErr = e.post(ctx, path, idempotencyKey, data, reply)
+		if e.breaker.record(lastErr) {
+			breakerTrips.WithLabelValues(e.Uri).Inc()
+		}
+		if lastErr == nil {
+			return nil
+		}
+		failures.WithLabelValues(e.Uri).Inc()
+		if !retryable(lastErr) {
+			break
+		}
+	}
+	return lastErr
+}
+
+func (e *EndpointRequester) post(ctx context.Context, path string, idempotencyKey string, data []byte, reply any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Uri+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(idempotencyKey) > 0 {
+		req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+	}
+	return e.do(req, reply)
+}
+
+func (e *EndpointRequester) get(ctx context.Context, path string, reply any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.Uri+path, nil)
+	if err != nil {
+		return fmt.Errorf("%w: can't request http", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return e.do(req, reply)
+}
+
+func (e *EndpointRequester) do(req *http.Request, reply any) error {
+	if v := e.version.Load(); v > 0 {
+		req.Header.Set(protocol.VersionHeader, strconv.FormatUint(uint64(v), 10))
+	}
+	resp, err := e.Cli.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: can't do request", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: can't read response body", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &statusError{code: resp.StatusCode, body: string(bytes.TrimSpace(body))}
+	}
+	if err := json.Unmarshal(body, reply); err != nil {
+		return fmt.Errorf("%w: can't unmarshal json", err)
+	}
+	return nil
+}
@@ -1,16 +1,5 @@
 package requester
 
-const (
-	SP1ENDPOINT        = "/sp1-verify"
-	RISCZEROENDPOINT   = "/risc0-verify"
-	MIDENENDPOINT      = "/miden-verify"
-	JOLTENDPOINT       = "/jolt-verify"
-	PLONKY2ENDPOINT    = "/plonky2-verify"
-	PINGENDPOINT       = "/ping"
-	PINGSINGLEENDPOINT = "/ping-single"
-	VERIFYENDPOINT     = "/verify"
-)
-
 const (
 	BASEFILEPATH = "/tmp/hypersdk/sausage/"
 )
@@ -0,0 +1,36 @@
+package requester
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	retries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "requester",
+		Name:      "retries",
+		Help:      "number of retried verifier requests",
+	}, []string{"endpoint"})
+	failures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "requester",
+		Name:      "failures",
+		Help:      "number of failed verifier request attempts",
+	}, []string{"endpoint"})
+	breakerTrips = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "requester",
+		Name:      "breaker_trips",
+		Help:      "number of times an endpoint's circuit breaker opened",
+	}, []string{"endpoint"})
+	breakerRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "requester",
+		Name:      "breaker_rejections",
+		Help:      "number of requests refused by an open circuit breaker",
+	}, []string{"endpoint"})
+	healthyEndpoints = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "requester",
+		Name:      "healthy_endpoints",
+		Help:      "number of verifier endpoints that passed their last health check",
+	})
+)
+
+// Collectors are the requester metrics, to be registered by the VM.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{retries, failures, breakerTrips, breakerRejections, healthyEndpoints}
+}
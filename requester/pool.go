@@ -0,0 +1,185 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultHealthCheckInterval = 10 * time.Second
+
+var (
+	ErrNoEndpoint  = errors.New("no verifier endpoint for proving system")
+	ErrUnreachable = errors.New("no verifier endpoint reachable")
+)
+
+type EndpointConfig struct {
+	Uri string `json:"uri"`
+	// ProvingSystems are the action TypeIDs the endpoint verifies. An empty
+	// list means every proving system.
+	ProvingSystems []uint8 `json:"provingSystems"`
+}
+
+type endpoint struct {
+	*EndpointRequester
+
+	provingSystems []uint8
+	healthy        atomic.Bool
+	inFlight       atomic.Int64
+}
+
+func (e *endpoint) available() bool {
+	return e.healthy.Load() && !e.breaker.open()
+}
+
+func (e *endpoint) supports(provingSystem uint8) bool {
+	return len(e.provingSystems) == 0 || slices.Contains(e.provingSystems, provingSystem)
+}
+
+// Pool routes verification requests to the endpoints able to verify them.
+// Requests go to the healthy endpoint with the fewest requests in flight and
+// fail over to the next one when the endpoint is at fault. Every endpoint has its own client, so a
+// slow verifier only holds up requests for the proving systems it serves.
+type Pool struct {
+	l         sync.RWMutex
+	endpoints []*endpoint
+	next      atomic.Uint64
+	version   atomic.Uint32
+
+	HealthCheckInterval time.Duration
+}
+
+func NewPool(configs []*EndpointConfig) *Pool {
+	p := &Pool{HealthCheckInterval: defaultHealthCheckInterval}
+	p.SetEndpoints(configs)
+	return p
+}
+
+// SetEndpoints replaces the endpoints of the pool. Requests in flight finish
+// against the endpoints they started with.
+func (p *Pool) SetEndpoints(configs []*EndpointConfig) {
+	endpoints := make([]*endpoint, len(configs))
+	for i, cfg := range configs {
+		e := &endpoint{
+			EndpointRequester: New(cfg.Uri),
+			provingSystems:    cfg.ProvingSystems,
+		}
+		// endpoints are trusted until a request or health check fails
+		e.healthy.Store(true)
+		endpoints[i] = e
+	}
+	p.l.Lock()
+	for _, e := range endpoints {
+		e.SetVersion(p.version.Load())
+	}
+	p.endpoints = endpoints
+	p.l.Unlock()
+	healthyEndpoints.Set(float64(len(endpoints)))
+}
+
+// SetVersion sets the protocol version negotiated with the hub, which every
+// endpoint sends from now on.
+func (p *Pool) SetVersion(version uint32) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.version.Store(version)
+	for _, e := range p.endpoints {
+		e.SetVersion(version)
+	}
+}
+
+// Version returns the negotiated protocol version, or 0 before negotiation.
+func (p *Pool) Version() uint32 {
+	return p.version.Load()
+}
+
+func (p *Pool) snapshot() []*endpoint {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.endpoints
+}
+
+// Len returns the number of endpoints in the pool.
+func (p *Pool) Len() int {
+	return len(p.snapshot())
+}
+
+// candidates orders the endpoints supporting [provingSystem]: healthy ones
+// first, least loaded first. Unhealthy endpoints are kept as a last resort.
+func (p *Pool) candidates(provingSystem uint8) []*endpoint {
+	endpoints := p.snapshot()
+	offset := int(p.next.Add(1))
+	candidates := make([]*endpoint, 0, len(endpoints))
+	for i := range endpoints {
+		// rotate the starting point so equally loaded endpoints share requests
+		e := endpoints[(i+offset)%len(endpoints)]
+		if e.supports(provingSystem) {
+			candidates = append(candidates, e)
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b *endpoint) int {
+		if ah, bh := a.available(), b.available(); ah != bh {
+			if ah {
+				return -1
+			}
+			return 1
+		}
+		return int(a.inFlight.Load() - b.inFlight.Load())
+	})
+	return candidates
+}
+
+// Do runs [f] against an endpoint verifying [provingSystem], failing over to
+// the next candidate until one succeeds or [ctx] is done. It returns
+// [ErrUnreachable] once every candidate failed. Errors the endpoint
+// isn't at fault for, like a rejected request, are returned right away: every
+// other endpoint would reject it as well.
+func (p *Pool) Do(ctx context.Context, provingSystem uint8, f func(context.Context, *EndpointRequester) error) error {
+	candidates := p.candidates(provingSystem)
+	if len(candidates) == 0 {
+		return fmt.Errorf("%w: %d", ErrNoEndpoint, provingSystem)
+	}
+	var errs []error
+	for _, e := range candidates {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e.inFlight.Add(1)
+		err := f(ctx, e.EndpointRequester)
+		e.inFlight.Add(-1)
+		if !faulty(err) {
+			// the endpoint answered, unless the caller gave up
+			if !errors.Is(err, context.Canceled) {
+				e.healthy.Store(true)
+			}
+			return err
+		}
+		e.healthy.Store(false)
+		errs = append(errs, fmt.Errorf("%s: %w", e.Uri, err))
+	}
+	return fmt.Errorf("%w: %w", ErrUnreachable, errors.Join(errs...))
+}
+
+// HealthCheck pings every endpoint and records which ones are reachable.
+func (p *Pool) HealthCheck() {
+	for _, e := range p.snapshot() {
+		success, err := PingSingle(e.EndpointRequester)
+		e.healthy.Store(err == nil && success)
+	}
+	healthyEndpoints.Set(float64(p.Healthy()))
+}
+
+// Healthy returns the number of endpoints that passed their last check.
+func (p *Pool) Healthy() int {
+	healthy := 0
+	for _, e := range p.snapshot() {
+		if e.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
@@ -1,27 +1,23 @@
 package requester
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"context"
 	"net/http"
+	"sync/atomic"
 	"time"
+
+	"github.com/sausaging/hyper-pvzk/protocol"
 )
 
 type EndpointRequester struct {
-	Cli *http.Client
-	Uri string
-}
-
-type PingReply struct {
-	Success   bool   `json:"success"`
-	RustPort  string `json:"rust_port"`
-	UnintPort string `json:"uinit_port"`
-}
+	Cli     *http.Client
+	Uri     string
+	Backoff Backoff
 
-type PingSingleReply struct {
-	Success bool `json:"success"`
+	breaker *breaker
+	// version is the negotiated protocol version, sent on every request
+	// once set.
+	version atomic.Uint32
 }
 
 func New(uri string) *EndpointRequester {
@@ -35,7 +31,12 @@ func New(uri string) *EndpointRequester {
 			Timeout:   8 * time.Second,
 			Transport: t,
 		},
-		Uri: uri,
+		Uri:     uri,
+		Backoff: DefaultBackoff,
+		breaker: &breaker{
+			threshold: defaultBreakerThreshold,
+			cooldown:  defaultBreakerCooldown,
+		},
 	}
 }
 
@@ -45,58 +46,30 @@ func New(uri string) *EndpointRequester {
 // 	return endpoint.Cli, endpoint.Uri
 // }
 
-func NewRequest(endPoint string, data []byte) (*http.Request, error) {
-	req, err := http.NewRequest(http.MethodPost, endPoint, bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	return req, nil
+// SetVersion sets the protocol version sent on every request.
+func (e *EndpointRequester) SetVersion(version uint32) {
+	e.version.Store(version)
 }
 
-// ping returns success along with rust endpoint and go endpoint for listening status of verify requests
-func Ping(client *EndpointRequester) (bool, string, string, error) {
-	endPointUri := client.Uri + PINGENDPOINT
-	req, err := http.NewRequest(http.MethodGet, endPointUri, bytes.NewBuffer([]byte{}))
-	if err != nil {
-		return false, "", "", fmt.Errorf("%s: can't request http", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := client.Cli.Do(req)
-	if err != nil {
-		return false, "", "", fmt.Errorf("%s: can't do request", err)
-	}
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return false, "", "", fmt.Errorf("%s: can't decode client response", err)
-	}
-	reply := new(PingReply)
-	err = json.Unmarshal(body, &reply)
-	if err != nil {
-		return false, "", "", fmt.Errorf("%s: can't unmarshal json", err)
+// Version returns the protocol version sent on every request.
+func (e *EndpointRequester) Version() uint32 {
+	return e.version.Load()
+}
+
+// Ping returns success along with rust endpoint, go endpoint for listening status of verify requests
+// and the protocol versions the hub speaks.
+func Ping(client *EndpointRequester) (*protocol.PingReply, error) {
+	reply := new(protocol.PingReply)
+	if err := client.get(context.Background(), protocol.PINGENDPOINT, reply); err != nil {
+		return nil, err
 	}
-	return reply.Success, reply.RustPort, reply.UnintPort, nil
+	return reply, nil
 }
 
 func PingSingle(client *EndpointRequester) (bool, error) {
-	endPointUri := client.Uri + PINGSINGLEENDPOINT
-	req, err := http.NewRequest(http.MethodGet, endPointUri, bytes.NewBuffer([]byte{}))
-	if err != nil {
-		return false, fmt.Errorf("%s: can't request http", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := client.Cli.Do(req)
-	if err != nil {
-		return false, fmt.Errorf("%s: can't do request", err)
-	}
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return false, fmt.Errorf("%s: can't decode client response", err)
-	}
-	reply := new(PingSingleReply)
-	err = json.Unmarshal(body, &reply)
-	if err != nil {
-		return false, fmt.Errorf("%s: can't unmarshal json", err)
+	reply := new(protocol.PingSingleReply)
+	if err := client.get(context.Background(), protocol.PINGSINGLEENDPOINT, reply); err != nil {
+		return false, err
 	}
 	return reply.Success, nil
 }
@@ -0,0 +1,79 @@
+package requester
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sausaging/hyper-pvzk/protocol"
+)
+
+var ErrStatusUnsupported = errors.New("verifier doesn't serve job status")
+
+func (e *EndpointRequester) statusPath(txID string, watch bool) (string, error) {
+	if v := e.version.Load(); v < protocol.StatusVersion {
+		return "", fmt.Errorf("%w: protocol version %d", ErrStatusUnsupported, v)
+	}
+	q := url.Values{"tx_id": {txID}}
+	if watch {
+		q.Set("watch", "true")
+	}
+	return protocol.STATUSENDPOINT + "?" + q.Encode(), nil
+}
+
+// Status returns the status of the job submitted for [txID].
+func (e *EndpointRequester) Status(ctx context.Context, txID string) (*protocol.JobStatus, error) {
+	path, err := e.statusPath(txID, false)
+	if err != nil {
+		return nil, err
+	}
+	status := new(protocol.JobStatus)
+	if err := e.get(ctx, path, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Watch streams the status of the job submitted for [txID] to [f] until the
+// job is done or [ctx] is done.
+func (e *EndpointRequester) Watch(ctx context.Context, txID string, f func(*protocol.JobStatus)) error {
+	path, err := e.statusPath(txID, true)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.Uri+path, nil)
+	if err != nil {
+		return fmt.Errorf("%w: can't request http", err)
+	}
+	req.Header.Set(protocol.VersionHeader, strconv.FormatUint(uint64(e.version.Load()), 10))
+	// the stream outlives the timeout of regular requests
+	cli := &http.Client{Transport: e.Cli.Transport}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: can't do request", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{code: resp.StatusCode, body: resp.Status}
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		status := new(protocol.JobStatus)
+		if err := json.Unmarshal(scanner.Bytes(), status); err != nil {
+			return fmt.Errorf("%w: can't unmarshal json", err)
+		}
+		f(status)
+		if status.State == protocol.JobDone {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: stream ended before the job was done", ErrUnexpectedReply)
+}
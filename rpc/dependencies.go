@@ -8,7 +8,9 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
+	"github.com/sausaging/hyper-pvzk/artifacts"
 	"github.com/sausaging/hyper-pvzk/genesis"
+	"github.com/sausaging/hyper-pvzk/storage"
 	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/fees"
 )
@@ -19,4 +21,12 @@ type Controller interface {
 	GetTransaction(context.Context, ids.ID) (bool, int64, bool, fees.Dimensions, uint64, error)
 	GetBalanceFromState(context.Context, codec.Address) (uint64, error)
 	GetVerifyStatusFromState(context.Context, ids.ID) (bool, error)
+	GetOutputsFromState(context.Context, ids.ID) (bool, []byte, error)
+	GetTallyFromState(context.Context, ids.ID) (*storage.Tally, error)
+	GetIVCFromState(context.Context, ids.ID) (*storage.IVC, error)
+	GetParticipationFromState(context.Context, codec.Address) (*storage.Participation, uint64, error)
+	GetCanaryStatsFromState(context.Context) (*storage.CanaryStats, error)
+	VerifierStatus() (bool, int, int, uint32, int)
+	DiskUsage() (*artifacts.Usage, error)
+	SubmitChunk(context.Context, ids.ID, uint16, uint16, []byte) (uint64, error)
 }
@@ -5,4 +5,7 @@ package rpc
 
 import "errors"
 
-var ErrTxNotFound = errors.New("tx not found")
+var (
+	ErrTxNotFound       = errors.New("tx not found")
+	ErrAdminAPIDisabled = errors.New("admin API disabled")
+)
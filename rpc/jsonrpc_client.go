@@ -5,6 +5,7 @@ package rpc
 
 import (
 	"context"
+	"encoding/hex"
 	"strings"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -108,6 +109,116 @@ func (cli *JSONRPCClient) VerifyStatus(ctx context.Context, id ids.ID) (bool, er
 	return resp.Status, err
 }
 
+// VerifyOutcomes returns the number of votes cast on [id] by outcome.
+func (cli *JSONRPCClient) VerifyOutcomes(ctx context.Context, id ids.ID) (map[string]uint64, error) {
+	resp := new(VerifyStatusReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"verifyStatus",
+		&VerifyStatusArgs{TxID: id},
+		resp,
+	)
+	return resp.Outcomes, err
+}
+
+// VerifyOutputs returns the digest of the public outputs validators agreed
+// on for [id], nil if the request wasn't verified or reported no outputs.
+func (cli *JSONRPCClient) VerifyOutputs(ctx context.Context, id ids.ID) ([]byte, error) {
+	resp := new(VerifyStatusReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"verifyStatus",
+		&VerifyStatusArgs{TxID: id},
+		resp,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.OutputsDigest) == 0 {
+		return nil, nil
+	}
+	return hex.DecodeString(resp.OutputsDigest)
+}
+
+func (cli *JSONRPCClient) IVCStatus(ctx context.Context, instanceID ids.ID) (*IVCStatusReply, error) {
+	resp := new(IVCStatusReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"ivcStatus",
+		&IVCStatusArgs{InstanceID: instanceID},
+		resp,
+	)
+	return resp, err
+}
+
+func (cli *JSONRPCClient) Participation(ctx context.Context, addr string) (*ParticipationReply, error) {
+	resp := new(ParticipationReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"participation",
+		&ParticipationArgs{
+			Address: addr,
+		},
+		resp,
+	)
+	return resp, err
+}
+
+func (cli *JSONRPCClient) CanaryStats(ctx context.Context) (*CanaryStatsReply, error) {
+	resp := new(CanaryStatsReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"canaryStats",
+		nil,
+		resp,
+	)
+	return resp, err
+}
+
+func (cli *JSONRPCClient) VerifierStatus(ctx context.Context) (*VerifierStatusReply, error) {
+	resp := new(VerifierStatusReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"verifierStatus",
+		nil,
+		resp,
+	)
+	return resp, err
+}
+
+func (cli *JSONRPCClient) SubmitChunk(
+	ctx context.Context,
+	imageID ids.ID,
+	valType uint16,
+	chunkIndex uint16,
+	data []byte,
+) (uint64, error) {
+	resp := new(SubmitChunkReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"submitChunk",
+		&SubmitChunkArgs{
+			ImageID:    imageID,
+			ValType:    valType,
+			ChunkIndex: chunkIndex,
+			Data:       data,
+		},
+		resp,
+	)
+	return resp.Remaining, err
+}
+
+func (cli *JSONRPCClient) DiskUsage(ctx context.Context) (*DiskUsageReply, error) {
+	resp := new(DiskUsageReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"diskUsage",
+		nil,
+		resp,
+	)
+	return resp, err
+}
+
 func (cli *JSONRPCClient) WaitForBalance(
 	ctx context.Context,
 	addr string,
@@ -160,12 +271,11 @@ func (p *Parser) ChainID() ids.ID {
 }
 
 func (p *Parser) Rules(t int64) chain.Rules {
-	r := req.New("")
 	return p.genesis.Rules(
 		t,
 		p.networkID,
 		p.chainID,
-		r,
+		req.NewPool(nil),
 		func(ctx context.Context) (map[ids.NodeID]*validators.GetValidatorOutput, map[string]struct{}) {
 			return map[ids.NodeID]*validators.GetValidatorOutput{}, map[string]struct{}{}
 		})
@@ -4,6 +4,7 @@
 package rpc
 
 import (
+	"encoding/hex"
 	"net/http"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -90,16 +91,201 @@ type VerifyStatusArgs struct {
 
 type VerifyStatusReply struct {
 	Status bool `json:"status"`
+	// Outcomes counts the votes by reported outcome, so submitters can tell
+	// a rejected proof from an artifact validators couldn't verify.
+	Outcomes map[string]uint64 `json:"outcomes"`
+	// OutputsDigest is the hex encoded digest of the public outputs
+	// validators agreed on, empty unless the verifiers reported them.
+	OutputsDigest string `json:"outputsDigest,omitempty"`
 }
 
 func (j *JSONRPCServer) VerifyStatus(req *http.Request, args *VerifyStatusArgs, reply *VerifyStatusReply) error {
 	ctx, span := j.c.Tracer().Start(req.Context(), "Server.VerifyStatus")
 	defer span.End()
 
-	status, err := j.c.GetVerifyStatusFromState(ctx, args.TxID)
+	status, outputsDigest, err := j.c.GetOutputsFromState(ctx, args.TxID)
+	if err != nil {
+		return err
+	}
+	tally, err := j.c.GetTallyFromState(ctx, args.TxID)
 	if err != nil {
 		return err
 	}
 	reply.Status = status
+	reply.OutputsDigest = hex.EncodeToString(outputsDigest)
+	reply.Outcomes = make(map[string]uint64, len(tally))
+	for i, count := range tally {
+		reply.Outcomes[consts.Outcome(i).String()] = count
+	}
+	return nil
+}
+
+type IVCStatusArgs struct {
+	InstanceID ids.ID `json:"instanceId"`
+}
+
+type IVCStatusReply struct {
+	ImageID ids.ID `json:"imageId"`
+	Owner   string `json:"owner"`
+	// Step is the latest verified step recorded, and StateCommitment the hex
+	// encoded digest of the state it reached.
+	Step            uint64 `json:"step"`
+	StateCommitment string `json:"stateCommitment,omitempty"`
+	InitialState    string `json:"initialState,omitempty"`
+}
+
+// IvcStatus is served as ivcStatus.
+func (j *JSONRPCServer) IvcStatus(req *http.Request, args *IVCStatusArgs, reply *IVCStatusReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.IvcStatus")
+	defer span.End()
+
+	ivc, err := j.c.GetIVCFromState(ctx, args.InstanceID)
+	if err != nil {
+		return err
+	}
+	reply.ImageID = ivc.ImageID
+	reply.Owner = codec.MustAddressBech32(consts.HRP, ivc.Owner)
+	reply.Step = ivc.Step
+	reply.StateCommitment = hex.EncodeToString(ivc.StateCommitment)
+	reply.InitialState = hex.EncodeToString(ivc.InitialState)
+	return nil
+}
+
+type ParticipationArgs struct {
+	Address string `json:"address"`
+}
+
+type ParticipationReply struct {
+	RequestsEligible uint64 `json:"requestsEligible"`
+	VotesCast        uint64 `json:"votesCast"`
+	VotesAgreeing    uint64 `json:"votesAgreeing"`
+	Rewards          uint64 `json:"rewards"`
+	Claimed          uint64 `json:"claimed"`
+	CanariesFailed   uint64 `json:"canariesFailed"`
+	Penalties        uint64 `json:"penalties"`
+	PenaltiesOwed    uint64 `json:"penaltiesOwed"`
+}
+
+func (j *JSONRPCServer) Participation(req *http.Request, args *ParticipationArgs, reply *ParticipationReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Participation")
+	defer span.End()
+
+	addr, err := codec.ParseAddressBech32(consts.HRP, args.Address)
+	if err != nil {
+		return err
+	}
+	p, eligible, err := j.c.GetParticipationFromState(ctx, addr)
+	if err != nil {
+		return err
+	}
+	reply.RequestsEligible = eligible
+	reply.VotesCast = p.VotesCast
+	reply.VotesAgreeing = p.VotesAgreeing
+	reply.Rewards = p.Rewards
+	reply.Claimed = p.Claimed
+	reply.CanariesFailed = p.CanariesFailed
+	reply.Penalties = p.Penalties
+	reply.PenaltiesOwed = p.PenaltiesOwed
+	return nil
+}
+
+type CanaryStatsReply struct {
+	Revealed  uint64 `json:"revealed"`
+	LazyVotes uint64 `json:"lazyVotes"`
+	Penalties uint64 `json:"penalties"`
+}
+
+func (j *JSONRPCServer) CanaryStats(req *http.Request, _ *struct{}, reply *CanaryStatsReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.CanaryStats")
+	defer span.End()
+
+	stats, err := j.c.GetCanaryStatsFromState(ctx)
+	if err != nil {
+		return err
+	}
+	reply.Revealed = stats.Revealed
+	reply.LazyVotes = stats.LazyVotes
+	reply.Penalties = stats.Penalties
+	return nil
+}
+
+type VerifierStatusReply struct {
+	Discovered       bool `json:"discovered"`
+	Endpoints        int  `json:"endpoints"`
+	HealthyEndpoints int  `json:"healthyEndpoints"`
+	// Verifying is false while the node runs without a verifier: it keeps
+	// following the chain, but doesn't vote on requests.
+	Verifying bool `json:"verifying"`
+	// ProtocolVersion is the protocol version negotiated with the hub, 0
+	// until it is discovered.
+	ProtocolVersion uint32 `json:"protocolVersion"`
+	// Unverified are the requests accepted while no verifier could be
+	// reached, handed to the verifier once it is back.
+	Unverified int `json:"unverified"`
+}
+
+func (j *JSONRPCServer) VerifierStatus(_ *http.Request, _ *struct{}, reply *VerifierStatusReply) error {
+	discovered, endpoints, healthy, version, unverified := j.c.VerifierStatus()
+	reply.Discovered = discovered
+	reply.Endpoints = endpoints
+	reply.HealthyEndpoints = healthy
+	reply.Verifying = discovered && healthy > 0
+	reply.ProtocolVersion = version
+	reply.Unverified = unverified
+	return nil
+}
+
+type SubmitChunkArgs struct {
+	ImageID    ids.ID `json:"imageID"`
+	ValType    uint16 `json:"valType"`
+	ChunkIndex uint16 `json:"chunkIndex"`
+	Data       []byte `json:"data"`
+}
+
+type SubmitChunkReply struct {
+	// Remaining is how many of the bytes paid for at registration are left.
+	Remaining uint64 `json:"remaining"`
+}
+
+// SubmitChunk uploads a chunk of a registered artifact, refusing chunks
+// beyond the size paid for at registration.
+func (j *JSONRPCServer) SubmitChunk(req *http.Request, args *SubmitChunkArgs, reply *SubmitChunkReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.SubmitChunk")
+	defer span.End()
+
+	remaining, err := j.c.SubmitChunk(ctx, args.ImageID, args.ValType, args.ChunkIndex, args.Data)
+	if err != nil {
+		return err
+	}
+	reply.Remaining = remaining
+	return nil
+}
+
+type DiskUsageReply struct {
+	// Files and Bytes are the artifacts stored by the node, checksums
+	// included.
+	Files int    `json:"files"`
+	Bytes uint64 `json:"bytes"`
+	// TrackedProofs and TrackedImages are the artifacts waiting to expire.
+	TrackedProofs int `json:"trackedProofs"`
+	TrackedImages int `json:"trackedImages"`
+	// CollectedFiles and CollectedBytes were removed since the node started.
+	CollectedFiles uint64 `json:"collectedFiles"`
+	CollectedBytes uint64 `json:"collectedBytes"`
+	Archival       bool   `json:"archival"`
+}
+
+func (j *JSONRPCServer) DiskUsage(_ *http.Request, _ *struct{}, reply *DiskUsageReply) error {
+	usage, err := j.c.DiskUsage()
+	if err != nil {
+		return err
+	}
+	reply.Files = usage.Files
+	reply.Bytes = usage.Bytes
+	reply.TrackedProofs = usage.TrackedProofs
+	reply.TrackedImages = usage.TrackedImages
+	reply.CollectedFiles = usage.CollectedFiles
+	reply.CollectedBytes = usage.CollectedBytes
+	reply.Archival = usage.Archival
 	return nil
 }
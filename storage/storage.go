@@ -8,6 +8,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/ava-labs/avalanchego/database"
@@ -18,6 +19,7 @@ import (
 	"github.com/sausaging/hypersdk/fees"
 	"github.com/sausaging/hypersdk/state"
 
+	"github.com/sausaging/hyper-pvzk/commitment"
 	mconsts "github.com/sausaging/hyper-pvzk/consts"
 )
 
@@ -26,6 +28,11 @@ type ReadState func(context.Context, [][]byte) ([][]byte, []error)
 // Metadata
 // 0x0/ (tx)
 //   -> [txID] => timestamp
+// 0x1/ (retention)
+//   -> [imageID|valType] => kind|timestamp
+// 0x2/ (requests)
+//   -> [timestamp] => total|count
+// 0x3/ (request total) => total
 //
 // State
 // / (height) => store in root
@@ -40,27 +47,80 @@ type ReadState func(context.Context, [][]byte) ([][]byte, []error)
 
 const (
 	// metaDB
-	txPrefix = 0x0
+	txPrefix           = 0x0
+	retentionPrefix    = 0x1
+	requestsPrefix     = 0x2
+	requestTotalPrefix = 0x3
 
 	// stateDB
-	balancePrefix      = 0x0
-	heightPrefix       = 0x1
-	timestampPrefix    = 0x2
-	feePrefix          = 0x3
-	incomingWarpPrefix = 0x4
-	outgoingWarpPrefix = 0x5
-	registerPrefix     = 0x6
-	deployPrefix       = 0x7
-	timeOutPrefix      = 0x8
-	weightPrefix       = 0x9
-	statusPreifx       = 0xa
-	votePrefix         = 0xb
+	balancePrefix        = 0x0
+	heightPrefix         = 0x1
+	timestampPrefix      = 0x2
+	feePrefix            = 0x3
+	incomingWarpPrefix   = 0x4
+	outgoingWarpPrefix   = 0x5
+	registerPrefix       = 0x6
+	deployPrefix         = 0x7
+	timeOutPrefix        = 0x8
+	weightPrefix         = 0x9
+	statusPreifx         = 0xa
+	votePrefix           = 0xb
+	participationPrefix  = 0xc
+	tallyPrefix          = 0xd
+	rewardPoolPrefix     = 0xe
+	requestCountPrefix   = 0xf // unused
+	commitDeadlinePrefix = 0x10
+	commitPrefix         = 0x11
+	canaryCommitPrefix   = 0x12
+	canaryPrefix         = 0x13
+	canaryStatsPrefix    = 0x14
+	requestPrefix        = 0x15
+	imagePrefix          = 0x16
+	ivcPrefix            = 0x17
+	ivcStepPrefix        = 0x18
+	voterPrefix          = 0x19
 )
 
 const (
 	BalanceChunks uint16 = 1
 	HashChunksMax uint16 = 10
 	TimeOutChunks uint16 = 1
+
+	ParticipationChunks uint16 = 2
+	TallyChunks         uint16 = 1
+	RewardPoolChunks    uint16 = 1
+	CommitChunks        uint16 = 1
+	CanaryChunks        uint16 = 1
+	RequestChunks       uint16 = 2
+	ImageChunks         uint16 = 1
+	IVCChunks           uint16 = 19 // fits [MaxIVCInitialStateLen]
+	IVCStepChunks       uint16 = 1
+)
+
+// MaxIVCInitialStateLen is the longest initial state an IVC instance can be
+// opened with.
+const MaxIVCInitialStateLen = 1024
+
+const (
+	participationFirstRequestAt = iota * consts.Uint64Len
+	participationVotesCast
+	participationVotesAgreeing
+	participationRewards
+	participationClaimed
+	participationCanariesFailed
+	participationPenalties
+	participationPenaltiesOwed
+	participationLen
+)
+
+// offset of the outputs digest in vote records
+const voteOutputs = 3
+
+const (
+	canaryStatsRevealed = iota * consts.Uint64Len
+	canaryStatsLazyVotes
+	canaryStatsPenalties
+	canaryStatsLen
 )
 
 // const registerChunks uint16 = consts.MaxUint16
@@ -71,6 +131,9 @@ var (
 	heightKey    = []byte{heightPrefix}
 	timestampKey = []byte{timestampPrefix}
 	feeKey       = []byte{feePrefix}
+
+	requestTotalKey = []byte{requestTotalPrefix}
+	canaryStatsKey  = binary.BigEndian.AppendUint16([]byte{canaryStatsPrefix}, CanaryChunks)
 )
 
 // [txPrefix] + [txID]
@@ -103,6 +166,128 @@ func StoreTransaction(
 	return db.Put(k, v)
 }
 
+// Retention kinds of the artifacts stored by the node.
+const (
+	// RetainProof artifacts are kept until some blocks after the voting
+	// window of the last request they were submitted with closed.
+	RetainProof = byte(0x0)
+	// RetainImage artifacts are kept while their image is in use.
+	RetainImage = byte(0x1)
+)
+
+// [retentionPrefix] + [imageID] + [valType]
+func RetentionKey(imageID ids.ID, valType uint16) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k[0] = retentionPrefix
+	copy(k[1:], imageID[:])
+	binary.BigEndian.PutUint16(k[1+consts.IDLen:], valType)
+	return
+}
+
+func RetentionPrefix() []byte {
+	return []byte{retentionPrefix}
+}
+
+// ParseRetentionKey returns the artifact of retention key [k].
+func ParseRetentionKey(k []byte) (ids.ID, uint16) {
+	var imageID ids.ID
+	copy(imageID[:], k[1:])
+	return imageID, binary.BigEndian.Uint16(k[1+consts.IDLen:])
+}
+
+func StoreRetention(
+	db database.KeyValueWriter,
+	imageID ids.ID,
+	valType uint16,
+	kind byte,
+	t int64,
+) error {
+	v := make([]byte, 1+consts.Uint64Len)
+	v[0] = kind
+	binary.BigEndian.PutUint64(v[1:], uint64(t))
+	return db.Put(RetentionKey(imageID, valType), v)
+}
+
+// ParseRetention returns the kind and timestamp of retention value [v].
+func ParseRetention(v []byte) (byte, int64) {
+	return v[0], int64(binary.BigEndian.Uint64(v[1:]))
+}
+
+func GetRetention(
+	db database.KeyValueReader,
+	imageID ids.ID,
+	valType uint16,
+) (byte, int64, bool, error) {
+	v, err := db.Get(RetentionKey(imageID, valType))
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, err
+	}
+	kind, t := ParseRetention(v)
+	return kind, t, true, nil
+}
+
+// [requestsPrefix] + [timestamp]
+func RequestsKey(t int64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len)
+	k[0] = requestsPrefix
+	binary.BigEndian.PutUint64(k[1:], uint64(t))
+	return
+}
+
+// StoreRequests records that [count] verification requests were accepted in
+// the block at [t], for a [total] accepted so far. Requests are counted by
+// the node rather than in state, so that requests don't all write the same
+// key.
+func StoreRequests(
+	db database.KeyValueWriter,
+	t int64,
+	total uint64,
+	count uint64,
+) error {
+	// [total] + [count]
+	v := binary.BigEndian.AppendUint64(nil, total)
+	if err := db.Put(RequestsKey(t), binary.BigEndian.AppendUint64(v, count)); err != nil {
+		return err
+	}
+	return db.Put(requestTotalKey, binary.BigEndian.AppendUint64(nil, total))
+}
+
+// GetRequestTotal returns the number of verification requests accepted so
+// far.
+func GetRequestTotal(db database.KeyValueReader) (uint64, error) {
+	v, err := db.Get(requestTotalKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// GetRequestsSince returns the number of verification requests accepted in
+// blocks at or after [t].
+func GetRequestsSince(
+	db database.Database,
+	t int64,
+) (uint64, error) {
+	total, err := GetRequestTotal(db)
+	if err != nil {
+		return 0, err
+	}
+	it := db.NewIteratorWithStartAndPrefix(RequestsKey(t), []byte{requestsPrefix})
+	defer it.Release()
+	if !it.Next() {
+		return 0, it.Error()
+	}
+	v := it.Value()
+	before := binary.BigEndian.Uint64(v) - binary.BigEndian.Uint64(v[consts.Uint64Len:])
+	return total - before, nil
+}
+
 func GetTransaction(
 	_ context.Context,
 	db database.KeyValueReader,
@@ -319,15 +504,50 @@ func HashKey(txID ids.ID, valType uint16) (k []byte) {
 	return k
 }
 
+// StoreHashKeyType registers the [valType] artifact of [imageID] for
+// [registrant], with its commitment and the [size] paid for.
 func StoreHashKeyType(
 	ctx context.Context,
 	mu state.Mutable,
 	imageID ids.ID,
 	valType uint16,
-	data []byte, // string is casted into []byte
+	registrant codec.Address,
+	size uint64,
+	c *commitment.Commitment,
 ) error {
 	k := HashKey(imageID, valType)
-	return mu.Insert(ctx, k, data)
+	// [size] + [registrant] + [algorithm] + [digest]
+	v := binary.BigEndian.AppendUint64(nil, size)
+	v = append(v, registrant[:]...)
+	return mu.Insert(ctx, k, append(v, c.Bytes()...))
+}
+
+func innerGetHashKeyType(v []byte, err error) (*commitment.Commitment, uint64, error) {
+	if err != nil {
+		return nil, 0, err
+	}
+	c, err := commitment.FromBytes(v[consts.Uint64Len+codec.AddressLen:])
+	if err != nil {
+		return nil, 0, err
+	}
+	return c, binary.BigEndian.Uint64(v), nil
+}
+
+// GetArtifactRegistrant returns the account that registered the [valType]
+// artifact of [imageID].
+func GetArtifactRegistrant(
+	ctx context.Context,
+	im state.Immutable,
+	imageID ids.ID,
+	valType uint16,
+) (codec.Address, error) {
+	v, err := im.GetValue(ctx, HashKey(imageID, valType))
+	if err != nil {
+		return codec.EmptyAddress, err
+	}
+	var registrant codec.Address
+	copy(registrant[:], v[consts.Uint64Len:])
+	return registrant, nil
 }
 
 // func InitiateDeployType(
@@ -341,14 +561,28 @@ func StoreHashKeyType(
 // 	return mu.Insert(ctx, k, initiationBytes)
 // }
 
+// GetHashKeyType returns the commitment registered for the [valType] artifact
+// of [imageID], and the size paid for.
 func GetHashKeyType(
 	ctx context.Context,
 	im state.Immutable,
 	imageID ids.ID,
 	valType uint16,
-) ([]byte, error) {
+) (*commitment.Commitment, uint64, error) {
 	k := HashKey(imageID, valType)
-	return im.GetValue(ctx, k)
+	return innerGetHashKeyType(im.GetValue(ctx, k))
+}
+
+// GetHashKeyTypeFromState returns the commitment registered for the
+// [valType] artifact of [imageID], and the size paid for.
+func GetHashKeyTypeFromState(
+	ctx context.Context,
+	f ReadState,
+	imageID ids.ID,
+	valType uint16,
+) (*commitment.Commitment, uint64, error) {
+	values, errs := f(ctx, [][]byte{HashKey(imageID, valType)})
+	return innerGetHashKeyType(values[0], errs[0])
 }
 
 func DeployKey(
@@ -368,13 +602,19 @@ func TimeOutKey(
 	return
 }
 
+// [weightPrefix] + [txID] + [outputsDigest]
+//
+// Valid votes are weighed by the public outputs they report, so that quorum
+// is only reached on outputs enough validators agree on.
 func WeightKey(
 	txID ids.ID,
+	outputsDigest []byte,
 ) (k []byte) {
-	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k = make([]byte, 1+consts.IDLen+len(outputsDigest)+consts.Uint16Len)
 	k[0] = weightPrefix
 	copy(k[1:], txID[:])
-	binary.BigEndian.PutUint16(k[1+consts.IDLen:], TimeOutChunks)
+	copy(k[1+consts.IDLen:], outputsDigest)
+	binary.BigEndian.PutUint16(k[1+consts.IDLen+len(outputsDigest):], TimeOutChunks)
 	// max weight don't cross uint64. 1 chunk = 64 bytes
 	return k
 }
@@ -401,6 +641,42 @@ func VoteKey(
 	return k
 }
 
+// [voterPrefix] + [txID] + [publicKey]
+//
+// Votes are recorded under the reward address of the validator, voters are
+// also recorded by key so that a validator votes once whatever address it
+// signs its vote for.
+func VoterKey(
+	txID ids.ID,
+	publicKey []byte,
+) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+len(publicKey)+consts.Uint16Len)
+	k[0] = voterPrefix
+	copy(k[1:], txID[:])
+	copy(k[1+consts.IDLen:], publicKey)
+	binary.BigEndian.PutUint16(k[1+consts.IDLen+len(publicKey):], TimeOutChunks)
+	return k
+}
+
+// StoreVoter records that the validator with [publicKey] voted on [txID]. It
+// returns [ErrAlreadyVoted] if it already did.
+func StoreVoter(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	publicKey []byte,
+) error {
+	k := VoterKey(txID, publicKey)
+	_, err := mu.GetValue(ctx, k)
+	if err == nil {
+		return ErrAlreadyVoted
+	}
+	if !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+	return mu.Insert(ctx, k, []byte{successByte})
+}
+
 func StoreTimeOut(
 	ctx context.Context,
 	mu state.Mutable,
@@ -409,13 +685,25 @@ func StoreTimeOut(
 	timeStamp int64,
 ) error {
 	k := TimeOutKey(txID)
+	// [timeOut] + [openedAt]
+	v := binary.BigEndian.AppendUint64(nil, uint64(TimeOutAt(timeStamp, timeOut)))
+	return mu.Insert(ctx, k, binary.BigEndian.AppendUint64(v, uint64(timeStamp)))
+}
+
+// TimeOutAt is when the voting window of a request made at [timeStamp] for
+// [timeOut] blocks closes.
+func TimeOutAt(timeStamp int64, timeOut uint64) int64 {
+	return timeStamp + int64(clampTimeOut(timeOut))*1000
+}
+
+func clampTimeOut(timeOut uint64) uint64 {
 	if timeOut < 20 {
 		timeOut = 20
 	}
 	if timeOut > 300 {
 		timeOut = 300
 	}
-	return mu.Insert(ctx, k, binary.BigEndian.AppendUint64(nil, uint64(timeStamp+int64(timeOut)*1000)))
+	return timeOut
 }
 
 func GetTimeOut(
@@ -431,14 +719,31 @@ func GetTimeOut(
 	return int64(binary.BigEndian.Uint64(val)), nil
 }
 
+// GetOpenedAt returns the timestamp of the block that accepted request [txID].
+func GetOpenedAt(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) (int64, error) {
+	val, err := im.GetValue(ctx, TimeOutKey(txID))
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(val[consts.Uint64Len:])), nil
+}
+
+// UpdateWeight adds [weight] to the valid votes reporting [outputsDigest]
+// on [txID]. The first outputs to cross quorum mark the request verified and
+// are stored with its status.
 func UpdateWeight(
 	ctx context.Context,
 	mu state.Mutable,
 	txID ids.ID,
+	outputsDigest []byte,
 	weight uint64,
 	totalWeight uint64,
 ) error { // @todo refactor the logic
-	k := WeightKey(txID)
+	k := WeightKey(txID, outputsDigest)
 	val, err := mu.GetValue(ctx, k)
 	if errors.Is(err, database.ErrNotFound) {
 		return mu.Insert(ctx, k, binary.BigEndian.AppendUint64(nil, weight))
@@ -446,24 +751,76 @@ func UpdateWeight(
 		vW := binary.BigEndian.Uint64(val)
 		nW := vW + weight
 		if nW > totalWeight {
-			sK := StatusKey(txID)
-			mu.Insert(ctx, sK, binary.BigEndian.AppendUint16(nil, 1))
+			verified, err := GetVerifyStatus(ctx, mu, txID)
+			if err != nil {
+				return err
+			}
+			if !verified {
+				if err := StoreVerified(ctx, mu, txID, outputsDigest); err != nil {
+					return err
+				}
+			}
 		}
 		return mu.Insert(ctx, k, binary.BigEndian.AppendUint64(nil, nW))
 	}
 }
 
+// StoreVerified marks [txID] verified with the digest of its public outputs.
+func StoreVerified(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	outputsDigest []byte,
+) error {
+	// [status] + [outputsDigest]
+	v := binary.BigEndian.AppendUint16(nil, 1)
+	return mu.Insert(ctx, StatusKey(txID), append(v, outputsDigest...))
+}
+
 func GetVerifyStatusFromState(
 	ctx context.Context,
 	f ReadState,
 	txID ids.ID,
 ) (bool, error) {
-	k := StatusKey(txID)
-	values, errs := f(ctx, [][]byte{k})
-	if errs[0] != nil {
-		return false, errs[0]
+	verified, _, err := GetOutputsFromState(ctx, f, txID)
+	return verified, err
+}
+
+// GetOutputsFromState returns whether [txID] was verified and the digest of
+// the public outputs validators agreed on, nil if the verifiers reported
+// none.
+func GetOutputsFromState(
+	ctx context.Context,
+	f ReadState,
+	txID ids.ID,
+) (bool, []byte, error) {
+	values, errs := f(ctx, [][]byte{StatusKey(txID)})
+	return innerGetOutputs(values[0], errs[0])
+}
+
+// GetOutputs returns whether [txID] was verified and the digest of the
+// public outputs validators agreed on.
+func GetOutputs(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) (bool, []byte, error) {
+	return innerGetOutputs(im.GetValue(ctx, StatusKey(txID)))
+}
+
+func innerGetOutputs(v []byte, err error) (bool, []byte, error) {
+	// requests that never reached quorum have no status
+	if errors.Is(err, database.ErrNotFound) {
+		return false, nil, nil
 	}
-	return binary.BigEndian.Uint16(values[0]) == 1, nil
+	if err != nil {
+		return false, nil, err
+	}
+	var outputsDigest []byte
+	if len(v) > consts.Uint16Len {
+		outputsDigest = v[consts.Uint16Len:]
+	}
+	return binary.BigEndian.Uint16(v) == 1, outputsDigest, nil
 }
 
 func GetVote(
@@ -473,22 +830,822 @@ func GetVote(
 	actor codec.Address,
 ) error {
 	k := VoteKey(txID, actor)
-	v, err := im.GetValue(ctx, k)
+	_, err := im.GetValue(ctx, k)
 	if errors.Is(err, database.ErrNotFound) {
 		return nil
 	}
-	if binary.BigEndian.Uint16(v) == 1 {
-		return ErrAlreadyVoted
+	if err != nil {
+		return err
 	}
-	return err
+	return ErrAlreadyVoted
 }
 
+// [outcome] + [settled] + [penalized] + [outputsDigest]
 func StoreVote(
 	ctx context.Context,
 	mu state.Mutable,
 	txID ids.ID,
 	actor codec.Address,
+	outcome mconsts.Outcome,
+	outputsDigest []byte,
+) error {
+	k := VoteKey(txID, actor)
+	return mu.Insert(ctx, k, append([]byte{byte(outcome), failureByte, failureByte}, outputsDigest...))
+}
+
+// GetVotedOutputs returns the digest of the public outputs [actor] reported
+// with its vote on [txID], nil if it reported none.
+func GetVotedOutputs(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+	actor codec.Address,
+) ([]byte, error) {
+	v, err := im.GetValue(ctx, VoteKey(txID, actor))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(v) <= voteOutputs {
+		return nil, nil
+	}
+	return v[voteOutputs:], nil
+}
+
+// GetVoteRecord returns whether [actor] voted on [txID], the outcome it
+// reported and whether the vote has already been settled by a reward claim.
+func GetVoteRecord(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+	actor codec.Address,
+) (bool, mconsts.Outcome, bool, error) {
+	k := VoteKey(txID, actor)
+	v, err := im.GetValue(ctx, k)
+	if errors.Is(err, database.ErrNotFound) {
+		return false, 0, false, nil
+	}
+	if err != nil {
+		return false, 0, false, err
+	}
+	return true, mconsts.Outcome(v[0]), v[1] == successByte, nil
+}
+
+func SettleVote(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	actor codec.Address,
+) error {
+	return setVoteFlag(ctx, mu, txID, actor, 1)
+}
+
+// IsVotePenalized returns true if [actor] was already penalized for its vote
+// on the canary request [txID].
+func IsVotePenalized(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+	actor codec.Address,
+) (bool, error) {
+	v, err := im.GetValue(ctx, VoteKey(txID, actor))
+	if errors.Is(err, database.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return v[2] == successByte, nil
+}
+
+func PenalizeVote(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	actor codec.Address,
+) error {
+	return setVoteFlag(ctx, mu, txID, actor, 2)
+}
+
+func setVoteFlag(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	actor codec.Address,
+	i int,
 ) error {
 	k := VoteKey(txID, actor)
-	return mu.Insert(ctx, k, binary.BigEndian.AppendUint16(nil, 1))
+	v, err := mu.GetValue(ctx, k)
+	if err != nil {
+		return err
+	}
+	v = slices.Clone(v)
+	v[i] = successByte
+	return mu.Insert(ctx, k, v)
+}
+
+func GetVerifyStatus(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) (bool, error) {
+	verified, _, err := GetOutputs(ctx, im, txID)
+	return verified, err
+}
+
+// [rewardPoolPrefix] + [txID]
+func RewardPoolKey(txID ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k[0] = rewardPoolPrefix
+	copy(k[1:], txID[:])
+	binary.BigEndian.PutUint16(k[1+consts.IDLen:], RewardPoolChunks)
+	return k
+}
+
+// StoreRewardPool moves [reward] from [actor] into the pool paid out to the
+// validators that agree with the outcome of [txID].
+func StoreRewardPool(
+	ctx context.Context,
+	mu state.Mutable,
+	actor codec.Address,
+	txID ids.ID,
+	reward uint64,
+) error {
+	if reward == 0 {
+		return nil
+	}
+	if err := SubBalance(ctx, mu, actor, reward); err != nil {
+		return err
+	}
+	return setRewardPool(ctx, mu, txID, reward, 0)
+}
+
+// GetRewardPool returns what is left in the reward pool of [txID], and how
+// many validators were paid from it.
+func GetRewardPool(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) (uint64, uint64, error) {
+	v, err := im.GetValue(ctx, RewardPoolKey(txID))
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return binary.BigEndian.Uint64(v), binary.BigEndian.Uint64(v[consts.Uint64Len:]), nil
+}
+
+// PayFromRewardPool takes the share of one of the [agreeing] validators from
+// the reward pool of [txID]. Shares are taken from what is left, so that the
+// last validator to claim gets what rounding left and the pool is never paid
+// out more than it holds.
+func PayFromRewardPool(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	agreeing uint64,
+) (uint64, error) {
+	remaining, paid, err := GetRewardPool(ctx, mu, txID)
+	if err != nil {
+		return 0, err
+	}
+	if paid >= agreeing {
+		return 0, nil
+	}
+	share := remaining / (agreeing - paid)
+	return share, setRewardPool(ctx, mu, txID, remaining-share, paid+1)
+}
+
+// [remaining] + [paid]
+func setRewardPool(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	remaining uint64,
+	paid uint64,
+) error {
+	v := binary.BigEndian.AppendUint64(nil, remaining)
+	return mu.Insert(ctx, RewardPoolKey(txID), binary.BigEndian.AppendUint64(v, paid))
+}
+
+// [tallyPrefix] + [txID]
+func TallyKey(txID ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k[0] = tallyPrefix
+	copy(k[1:], txID[:])
+	binary.BigEndian.PutUint16(k[1+consts.IDLen:], TallyChunks)
+	return k
+}
+
+// Tally counts the votes cast for a request by [mconsts.Outcome].
+type Tally [mconsts.NumOutcomes]uint64
+
+func GetTally(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) (*Tally, error) {
+	return innerGetTally(im.GetValue(ctx, TallyKey(txID)))
+}
+
+// Used to serve RPC queries
+func GetTallyFromState(
+	ctx context.Context,
+	f ReadState,
+	txID ids.ID,
+) (*Tally, error) {
+	values, errs := f(ctx, [][]byte{TallyKey(txID)})
+	return innerGetTally(values[0], errs[0])
+}
+
+func innerGetTally(v []byte, err error) (*Tally, error) {
+	var t Tally
+	if errors.Is(err, database.ErrNotFound) {
+		return &t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range t {
+		t[i] = binary.BigEndian.Uint64(v[i*consts.Uint64Len:])
+	}
+	return &t, nil
+}
+
+func AddTally(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	outcome mconsts.Outcome,
+) error {
+	t, err := GetTally(ctx, mu, txID)
+	if err != nil {
+		return err
+	}
+	t[outcome]++
+	v := make([]byte, 0, mconsts.NumOutcomes*consts.Uint64Len)
+	for _, count := range t {
+		v = binary.BigEndian.AppendUint64(v, count)
+	}
+	return mu.Insert(ctx, TallyKey(txID), v)
+}
+
+// [participationPrefix] + [address]
+func ParticipationKey(addr codec.Address) (k []byte) {
+	k = make([]byte, 1+codec.AddressLen+consts.Uint16Len)
+	k[0] = participationPrefix
+	copy(k[1:], addr[:])
+	binary.BigEndian.PutUint16(k[1+codec.AddressLen:], ParticipationChunks)
+	return k
+}
+
+// Participation tracks how actively a validator takes part in verification.
+type Participation struct {
+	// FirstRequestAt is when the first request the validator voted on was
+	// made.
+	FirstRequestAt int64  `json:"firstRequestAt"`
+	VotesCast      uint64 `json:"votesCast"`
+	VotesAgreeing  uint64 `json:"votesAgreeing"`
+	// Rewards are accrued but not yet claimed.
+	Rewards uint64 `json:"rewards"`
+	Claimed uint64 `json:"claimed"`
+	// CanariesFailed counts canary requests the validator voted valid on.
+	CanariesFailed uint64 `json:"canariesFailed"`
+	Penalties      uint64 `json:"penalties"`
+	// PenaltiesOwed are withheld from the next rewards claimed.
+	PenaltiesOwed uint64 `json:"penaltiesOwed"`
+}
+
+func GetParticipation(
+	ctx context.Context,
+	im state.Immutable,
+	addr codec.Address,
+) (*Participation, bool, error) {
+	return innerGetParticipation(im.GetValue(ctx, ParticipationKey(addr)))
+}
+
+// Used to serve RPC queries
+func GetParticipationFromState(
+	ctx context.Context,
+	f ReadState,
+	addr codec.Address,
+) (*Participation, bool, error) {
+	values, errs := f(ctx, [][]byte{ParticipationKey(addr)})
+	return innerGetParticipation(values[0], errs[0])
+}
+
+func innerGetParticipation(v []byte, err error) (*Participation, bool, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return &Participation{}, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &Participation{
+		FirstRequestAt: int64(binary.BigEndian.Uint64(v[participationFirstRequestAt:])),
+		VotesCast:      binary.BigEndian.Uint64(v[participationVotesCast:]),
+		VotesAgreeing:  binary.BigEndian.Uint64(v[participationVotesAgreeing:]),
+		Rewards:        binary.BigEndian.Uint64(v[participationRewards:]),
+		Claimed:        binary.BigEndian.Uint64(v[participationClaimed:]),
+		CanariesFailed: binary.BigEndian.Uint64(v[participationCanariesFailed:]),
+		Penalties:      binary.BigEndian.Uint64(v[participationPenalties:]),
+		PenaltiesOwed:  binary.BigEndian.Uint64(v[participationPenaltiesOwed:]),
+	}, true, nil
+}
+
+func SetParticipation(
+	ctx context.Context,
+	mu state.Mutable,
+	addr codec.Address,
+	p *Participation,
+) error {
+	v := make([]byte, participationLen)
+	binary.BigEndian.PutUint64(v[participationFirstRequestAt:], uint64(p.FirstRequestAt))
+	binary.BigEndian.PutUint64(v[participationVotesCast:], p.VotesCast)
+	binary.BigEndian.PutUint64(v[participationVotesAgreeing:], p.VotesAgreeing)
+	binary.BigEndian.PutUint64(v[participationRewards:], p.Rewards)
+	binary.BigEndian.PutUint64(v[participationClaimed:], p.Claimed)
+	binary.BigEndian.PutUint64(v[participationCanariesFailed:], p.CanariesFailed)
+	binary.BigEndian.PutUint64(v[participationPenalties:], p.Penalties)
+	binary.BigEndian.PutUint64(v[participationPenaltiesOwed:], p.PenaltiesOwed)
+	return mu.Insert(ctx, ParticipationKey(addr), v)
+}
+
+// RecordVoteCast counts a vote by [addr] on a request made at [openedAt],
+// registering the validator on its first vote.
+func RecordVoteCast(
+	ctx context.Context,
+	mu state.Mutable,
+	addr codec.Address,
+	openedAt int64,
+) error {
+	p, exists, err := GetParticipation(ctx, mu, addr)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		p.FirstRequestAt = openedAt
+	}
+	p.VotesCast++
+	return SetParticipation(ctx, mu, addr, p)
+}
+
+// CommitDeadline is the timestamp until which only vote commitments are
+// accepted for a request made at [timeStamp]. The commit phase never takes
+// more than half of the voting window if [commitBlocks] doesn't fit in it.
+func CommitDeadline(
+	timeStamp int64,
+	timeOut uint64,
+	commitBlocks uint64,
+) int64 {
+	timeOut = clampTimeOut(timeOut)
+	if commitBlocks >= timeOut {
+		commitBlocks = timeOut / 2
+	}
+	return timeStamp + int64(commitBlocks)*1000
+}
+
+// [commitDeadlinePrefix] + [txID]
+func CommitDeadlineKey(txID ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k[0] = commitDeadlinePrefix
+	copy(k[1:], txID[:])
+	binary.BigEndian.PutUint16(k[1+consts.IDLen:], TimeOutChunks)
+	return k
+}
+
+func StoreCommitDeadline(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	deadline int64,
+) error {
+	return mu.Insert(ctx, CommitDeadlineKey(txID), binary.BigEndian.AppendUint64(nil, uint64(deadline)))
+}
+
+// GetCommitDeadline returns false if [txID] doesn't use commit-reveal voting.
+func GetCommitDeadline(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) (int64, bool, error) {
+	v, err := im.GetValue(ctx, CommitDeadlineKey(txID))
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return int64(binary.BigEndian.Uint64(v)), true, nil
+}
+
+// [commitPrefix] + [txID] + [publicKey]
+//
+// Commitments are recorded by validator key, whoever submits them.
+func CommitKey(txID ids.ID, publicKey []byte) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+len(publicKey)+consts.Uint16Len)
+	k[0] = commitPrefix
+	copy(k[1:], txID[:])
+	copy(k[1+consts.IDLen:], publicKey)
+	binary.BigEndian.PutUint16(k[1+consts.IDLen+len(publicKey):], CommitChunks)
+	return k
+}
+
+func StoreCommitment(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	publicKey []byte,
+	commitment []byte,
+) error {
+	return mu.Insert(ctx, CommitKey(txID, publicKey), commitment)
+}
+
+// GetCommitment returns nil if the validator with [publicKey] didn't commit
+// to a vote on [txID].
+func GetCommitment(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+	publicKey []byte,
+) ([]byte, error) {
+	v, err := im.GetValue(ctx, CommitKey(txID, publicKey))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	return v, err
+}
+
+// [canaryCommitPrefix] + [commitment]
+func CanaryCommitKey(commitment []byte) (k []byte) {
+	k = make([]byte, 1+len(commitment)+consts.Uint16Len)
+	k[0] = canaryCommitPrefix
+	copy(k[1:], commitment)
+	binary.BigEndian.PutUint16(k[1+len(commitment):], CanaryChunks)
+	return k
+}
+
+func StoreCanaryCommitment(
+	ctx context.Context,
+	mu state.Mutable,
+	commitment []byte,
+	timeStamp int64,
+) error {
+	return mu.Insert(ctx, CanaryCommitKey(commitment), binary.BigEndian.AppendUint64(nil, uint64(timeStamp)))
+}
+
+// GetCanaryCommitment returns when [commitment] was made, or false if it
+// doesn't exist.
+func GetCanaryCommitment(
+	ctx context.Context,
+	im state.Immutable,
+	commitment []byte,
+) (int64, bool, error) {
+	v, err := im.GetValue(ctx, CanaryCommitKey(commitment))
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return int64(binary.BigEndian.Uint64(v)), true, nil
+}
+
+// [canaryPrefix] + [txID]
+func CanaryKey(txID ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k[0] = canaryPrefix
+	copy(k[1:], txID[:])
+	binary.BigEndian.PutUint16(k[1+consts.IDLen:], CanaryChunks)
+	return k
+}
+
+func MarkCanary(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+) error {
+	return mu.Insert(ctx, CanaryKey(txID), []byte{successByte})
+}
+
+// IsCanary returns true once [txID] has been revealed as a canary request.
+func IsCanary(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) (bool, error) {
+	_, err := im.GetValue(ctx, CanaryKey(txID))
+	if errors.Is(err, database.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// [canaryStatsPrefix]
+func CanaryStatsKey() (k []byte) {
+	return canaryStatsKey
+}
+
+// CanaryStats summarizes the canary requests revealed so far.
+type CanaryStats struct {
+	Revealed uint64 `json:"revealed"`
+	// LazyVotes are valid votes cast on canary requests.
+	LazyVotes uint64 `json:"lazyVotes"`
+	Penalties uint64 `json:"penalties"`
+}
+
+func GetCanaryStats(
+	ctx context.Context,
+	im state.Immutable,
+) (*CanaryStats, error) {
+	return innerGetCanaryStats(im.GetValue(ctx, canaryStatsKey))
+}
+
+// Used to serve RPC queries
+func GetCanaryStatsFromState(
+	ctx context.Context,
+	f ReadState,
+) (*CanaryStats, error) {
+	values, errs := f(ctx, [][]byte{canaryStatsKey})
+	return innerGetCanaryStats(values[0], errs[0])
+}
+
+func innerGetCanaryStats(v []byte, err error) (*CanaryStats, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return &CanaryStats{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &CanaryStats{
+		Revealed:  binary.BigEndian.Uint64(v[canaryStatsRevealed:]),
+		LazyVotes: binary.BigEndian.Uint64(v[canaryStatsLazyVotes:]),
+		Penalties: binary.BigEndian.Uint64(v[canaryStatsPenalties:]),
+	}, nil
+}
+
+func SetCanaryStats(
+	ctx context.Context,
+	mu state.Mutable,
+	stats *CanaryStats,
+) error {
+	v := make([]byte, canaryStatsLen)
+	binary.BigEndian.PutUint64(v[canaryStatsRevealed:], stats.Revealed)
+	binary.BigEndian.PutUint64(v[canaryStatsLazyVotes:], stats.LazyVotes)
+	binary.BigEndian.PutUint64(v[canaryStatsPenalties:], stats.Penalties)
+	return mu.Insert(ctx, canaryStatsKey, v)
+}
+
+// [requestPrefix] + [txID]
+func RequestKey(txID ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k[0] = requestPrefix
+	copy(k[1:], txID[:])
+	binary.BigEndian.PutUint16(k[1+consts.IDLen:], RequestChunks)
+	return k
+}
+
+// Request is what a verification request was made against. Votes are only
+// counted if they were cast for the same inputs.
+type Request struct {
+	ProvingSystem   uint8
+	ImageID         ids.ID
+	ArtifactsDigest []byte
+	TimeOut         int64
+}
+
+// [provingSystem] + [imageID] + [artifactsDigest]
+func StoreRequest(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	provingSystem uint8,
+	imageID ids.ID,
+	artifactsDigest []byte,
+) error {
+	v := make([]byte, 0, consts.ByteLen+consts.IDLen+len(artifactsDigest))
+	v = append(v, provingSystem)
+	v = append(v, imageID[:]...)
+	v = append(v, artifactsDigest...)
+	return mu.Insert(ctx, RequestKey(txID), v)
+}
+
+func GetRequest(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) (*Request, error) {
+	v, err := im.GetValue(ctx, RequestKey(txID))
+	if err != nil {
+		return nil, err
+	}
+	timeOut, err := GetTimeOut(ctx, im, txID)
+	if err != nil {
+		return nil, err
+	}
+	return innerGetRequest(v, timeOut), nil
+}
+
+// Used to serve RPC queries
+func GetRequestFromState(
+	ctx context.Context,
+	f ReadState,
+	txID ids.ID,
+) (*Request, error) {
+	values, errs := f(ctx, [][]byte{RequestKey(txID), TimeOutKey(txID)})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return innerGetRequest(values[0], int64(binary.BigEndian.Uint64(values[1]))), nil
+}
+
+func innerGetRequest(v []byte, timeOut int64) *Request {
+	r := &Request{
+		ProvingSystem:   v[0],
+		ArtifactsDigest: slices.Clone(v[consts.ByteLen+consts.IDLen:]),
+		TimeOut:         timeOut,
+	}
+	copy(r.ImageID[:], v[consts.ByteLen:])
+	return r
+}
+
+// [imagePrefix] + [imageID]
+func ImageKey(imageID ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k[0] = imagePrefix
+	copy(k[1:], imageID[:])
+	binary.BigEndian.PutUint16(k[1+consts.IDLen:], ImageChunks)
+	return k
+}
+
+// Image is what an image was registered for. ProgramID pins the program
+// proofs for the image must be generated for, such as the RISC Zero image ID
+// or the SP1 verifying key hash. It is empty for proving systems that don't
+// pin their program.
+type Image struct {
+	ProvingSystem uint64
+	ProgramID     []byte
+}
+
+// [provingSystem] + [programID]
+func StoreImage(
+	ctx context.Context,
+	mu state.Mutable,
+	imageID ids.ID,
+	image *Image,
+) error {
+	v := binary.BigEndian.AppendUint64(nil, image.ProvingSystem)
+	return mu.Insert(ctx, ImageKey(imageID), append(v, image.ProgramID...))
+}
+
+func GetImage(
+	ctx context.Context,
+	im state.Immutable,
+	imageID ids.ID,
+) (*Image, error) {
+	return innerGetImage(im.GetValue(ctx, ImageKey(imageID)))
+}
+
+// Used to serve RPC queries
+func GetImageFromState(
+	ctx context.Context,
+	f ReadState,
+	imageID ids.ID,
+) (*Image, error) {
+	values, errs := f(ctx, [][]byte{ImageKey(imageID)})
+	return innerGetImage(values[0], errs[0])
+}
+
+func innerGetImage(v []byte, err error) (*Image, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		ProvingSystem: binary.BigEndian.Uint64(v),
+		ProgramID:     slices.Clone(v[consts.Uint64Len:]),
+	}, nil
+}
+
+// [ivcPrefix] + [instanceID]
+func IVCKey(instanceID ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k[0] = ivcPrefix
+	copy(k[1:], instanceID[:])
+	binary.BigEndian.PutUint16(k[1+consts.IDLen:], IVCChunks)
+	return k
+}
+
+// IVC is an incrementally verifiable computation over the image [ImageID],
+// opened by [Owner] from [InitialState]. [Step] is the latest step a proof
+// was verified for and [StateCommitment] the digest of the state it reached
+// from [InitialState], as reported by the verifiers. Both are empty until a
+// first step is recorded.
+type IVC struct {
+	ImageID         ids.ID
+	Owner           codec.Address
+	InitialState    []byte
+	Step            uint64
+	StateCommitment []byte
+}
+
+// [imageID] + [owner] + [step] + [len(stateCommitment)] + [stateCommitment]
+// + [initialState]
+func StoreIVC(
+	ctx context.Context,
+	mu state.Mutable,
+	instanceID ids.ID,
+	ivc *IVC,
+) error {
+	v := make([]byte, 0, consts.IDLen+codec.AddressLen+consts.Uint64Len+consts.ByteLen+len(ivc.StateCommitment)+len(ivc.InitialState))
+	v = append(v, ivc.ImageID[:]...)
+	v = append(v, ivc.Owner[:]...)
+	v = binary.BigEndian.AppendUint64(v, ivc.Step)
+	v = append(v, byte(len(ivc.StateCommitment)))
+	v = append(v, ivc.StateCommitment...)
+	v = append(v, ivc.InitialState...)
+	return mu.Insert(ctx, IVCKey(instanceID), v)
+}
+
+func GetIVC(
+	ctx context.Context,
+	im state.Immutable,
+	instanceID ids.ID,
+) (*IVC, error) {
+	return innerGetIVC(im.GetValue(ctx, IVCKey(instanceID)))
+}
+
+// Used to serve RPC queries
+func GetIVCFromState(
+	ctx context.Context,
+	f ReadState,
+	instanceID ids.ID,
+) (*IVC, error) {
+	values, errs := f(ctx, [][]byte{IVCKey(instanceID)})
+	return innerGetIVC(values[0], errs[0])
+}
+
+func innerGetIVC(v []byte, err error) (*IVC, error) {
+	if err != nil {
+		return nil, err
+	}
+	ivc := &IVC{}
+	copy(ivc.ImageID[:], v)
+	v = v[consts.IDLen:]
+	copy(ivc.Owner[:], v)
+	v = v[codec.AddressLen:]
+	ivc.Step = binary.BigEndian.Uint64(v)
+	v = v[consts.Uint64Len:]
+	commitmentLen := int(v[0])
+	v = v[consts.ByteLen:]
+	if commitmentLen > 0 {
+		ivc.StateCommitment = slices.Clone(v[:commitmentLen])
+	}
+	if len(v) > commitmentLen {
+		ivc.InitialState = slices.Clone(v[commitmentLen:])
+	}
+	return ivc, nil
+}
+
+// [ivcStepPrefix] + [txID]
+func IVCStepKey(txID ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint16Len)
+	k[0] = ivcStepPrefix
+	copy(k[1:], txID[:])
+	binary.BigEndian.PutUint16(k[1+consts.IDLen:], IVCStepChunks)
+	return k
+}
+
+// [instanceID] + [step]
+//
+// StoreIVCStep records that request [txID] verifies [step] of [instanceID].
+func StoreIVCStep(
+	ctx context.Context,
+	mu state.Mutable,
+	txID ids.ID,
+	instanceID ids.ID,
+	step uint64,
+) error {
+	v := binary.BigEndian.AppendUint64(slices.Clone(instanceID[:]), step)
+	return mu.Insert(ctx, IVCStepKey(txID), v)
+}
+
+// GetIVCStep returns the instance and step request [txID] verifies.
+func GetIVCStep(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) (ids.ID, uint64, error) {
+	v, err := im.GetValue(ctx, IVCStepKey(txID))
+	if err != nil {
+		return ids.Empty, 0, err
+	}
+	var instanceID ids.ID
+	copy(instanceID[:], v)
+	return instanceID, binary.BigEndian.Uint64(v[consts.IDLen:]), nil
 }
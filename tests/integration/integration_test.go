@@ -42,13 +42,14 @@ import (
 	"github.com/sausaging/hypersdk/pubsub"
 	"github.com/sausaging/hypersdk/rpc"
 	hutils "github.com/sausaging/hypersdk/utils"
-	"github.com/sausaging/hypersdk/vm"
 
 	"github.com/sausaging/hyper-pvzk/actions"
+	"github.com/sausaging/hyper-pvzk/artifacts"
 	"github.com/sausaging/hyper-pvzk/auth"
 	lconsts "github.com/sausaging/hyper-pvzk/consts"
 	"github.com/sausaging/hyper-pvzk/controller"
 	"github.com/sausaging/hyper-pvzk/genesis"
+	"github.com/sausaging/hyper-pvzk/hub"
 	lrpc "github.com/sausaging/hyper-pvzk/rpc"
 )
 
@@ -124,13 +125,14 @@ var (
 type instance struct {
 	chainID           ids.ID
 	nodeID            ids.NodeID
-	vm                *vm.VM
+	vm                *controller.VM
 	toEngine          chan common.Message
 	JSONRPCServer     *httptest.Server
 	BaseJSONRPCServer *httptest.Server
 	WebSocketServer   *httptest.Server
 	cli               *rpc.JSONRPCClient // clients for embedded VMs
 	lcli              *lrpc.JSONRPCClient
+	hub               *hubServer // stand-in verifier
 }
 
 var _ = ginkgo.BeforeSuite(func() {
@@ -217,6 +219,9 @@ var _ = ginkgo.BeforeSuite(func() {
 
 		toEngine := make(chan common.Message, 1)
 		db := memdb.New()
+		verifier := startHub(hub.AlwaysValid())
+		valPriv, err := ed25519.GeneratePrivateKey()
+		gomega.Ω(err).Should(gomega.BeNil())
 
 		v := controller.New()
 		err = v.Initialize(
@@ -225,9 +230,7 @@ var _ = ginkgo.BeforeSuite(func() {
 			db,
 			genesisBytes,
 			nil,
-			[]byte(
-				`{"parallelism":3, "testMode":true, "logLevel":"debug"}`,
-			),
+			nodeConfig(verifier, hex.EncodeToString(valPriv[:]), artifacts.TransportPath, nil),
 			toEngine,
 			nil,
 			app,
@@ -251,6 +254,7 @@ var _ = ginkgo.BeforeSuite(func() {
 			WebSocketServer:   webSocketServer,
 			cli:               rpc.NewJSONRPCClient(jsonRPCServer.URL),
 			lcli:              lrpc.NewJSONRPCClient(ljsonRPCServer.URL, snowCtx.NetworkID, snowCtx.ChainID),
+			hub:               verifier,
 		}
 
 		// Force sync ready (to mimic bootstrapping from genesis)
@@ -284,6 +288,7 @@ var _ = ginkgo.AfterSuite(func() {
 		iv.JSONRPCServer.Close()
 		iv.BaseJSONRPCServer.Close()
 		iv.WebSocketServer.Close()
+		iv.hub.Close()
 		err := iv.vm.Shutdown(context.TODO())
 		gomega.Ω(err).Should(gomega.BeNil())
 	}
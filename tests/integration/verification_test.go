@@ -0,0 +1,2196 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/avalanchego/api/metrics"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
+	"github.com/sausaging/hypersdk/consts"
+	"github.com/sausaging/hypersdk/crypto/ed25519"
+	"github.com/sausaging/hypersdk/fees"
+	hrequester "github.com/sausaging/hypersdk/requester"
+	"github.com/sausaging/hypersdk/rpc"
+
+	"github.com/sausaging/hyper-pvzk/actions"
+	"github.com/sausaging/hyper-pvzk/artifacts"
+	"github.com/sausaging/hyper-pvzk/auth"
+	"github.com/sausaging/hyper-pvzk/commitment"
+	lconsts "github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/controller"
+	"github.com/sausaging/hyper-pvzk/genesis"
+	"github.com/sausaging/hyper-pvzk/groth16"
+	"github.com/sausaging/hyper-pvzk/hub"
+	"github.com/sausaging/hyper-pvzk/protocol"
+	"github.com/sausaging/hyper-pvzk/requester"
+	lrpc "github.com/sausaging/hyper-pvzk/rpc"
+	"github.com/sausaging/hyper-pvzk/storage"
+)
+
+// transports of the nodes of a verifierNetwork, in turn
+var transports = []string{artifacts.TransportPath, artifacts.TransportContent, artifacts.TransportURL}
+
+const (
+	validatorWeight  = 100
+	validatorBalance = 1_000_000
+	requestTimeOut   = 20 // blocks, the minimum
+)
+
+// val types of the artifacts registered for the test image
+const (
+	proofValType uint16 = iota + 2
+	invalidProofValType
+	commonDataValType
+	verifierDataValType
+	missingProofValType  // registered but never uploaded
+	expiringProofValType // collected once its request timed out
+	paidValType          // uploaded up to and beyond its paid size
+	midenCodeValType
+	midenOutputsValType
+	halo2VerifyingKeyValType
+	halo2InstancesValType
+	noirVerifyingKeyValType
+	noirPublicInputsValType // public inputs of the testdata/noir circuit
+	novaVerifyingKeyValType // registered for the Nova image only
+	segmentValType          // first of the segments registered for the RISC Zero image only
+	unregisteredValType
+	committedValType // first of the val types registered with each commitment algorithm
+)
+
+// verifierNode is a validator voting on requests through its stand-in hub.
+type verifierNode struct {
+	instance
+
+	factory *auth.ED25519Factory
+	addr    codec.Address
+	// artifacts serves the artifact API of the node
+	artifacts *httptest.Server
+	// publicKey and signer are the BLS key of the validator, for votes cast
+	// by hand
+	publicKey []byte
+	signer    warp.Signer
+	sender    *nodeSender
+}
+
+// sign signs [msg] the way [node] signs its votes.
+func (node *verifierNode) sign(msg []byte) []byte {
+	unsigned, err := warp.NewUnsignedMessage(networkID, node.chainID, msg)
+	gomega.Ω(err).Should(gomega.BeNil())
+	sig, err := node.signer.Sign(unsigned)
+	gomega.Ω(err).Should(gomega.BeNil())
+	return sig
+}
+
+// nodeSender is the app sender of a node of a verifier network. Requests are
+// recorded instead of delivered, and acknowledged as if the peer handled
+// them, and responses are recorded.
+type nodeSender struct {
+	*appSender
+	vm *controller.VM
+
+	l         sync.Mutex
+	requests  []appMessage
+	responses []appMessage
+}
+
+type appMessage struct {
+	nodeID    ids.NodeID
+	requestID uint32
+	msg       []byte
+}
+
+func (s *nodeSender) SendAppRequest(_ context.Context, nodeIDs set.Set[ids.NodeID], requestID uint32, msg []byte) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	for nodeID := range nodeIDs {
+		s.requests = append(s.requests, appMessage{nodeID: nodeID, requestID: requestID, msg: slices.Clone(msg)})
+		// the VM sends requests while holding the locks the response takes
+		go func(nodeID ids.NodeID) {
+			_ = s.vm.AppResponse(context.Background(), nodeID, requestID, nil)
+		}(nodeID)
+	}
+	return nil
+}
+
+func (s *nodeSender) SendAppResponse(_ context.Context, nodeID ids.NodeID, requestID uint32, msg []byte) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.responses = append(s.responses, appMessage{nodeID: nodeID, requestID: requestID, msg: slices.Clone(msg)})
+	return nil
+}
+
+// request returns the latest request sent to [nodeID] that [match]es.
+func (s *nodeSender) request(nodeID ids.NodeID, match func([]byte) bool) ([]byte, bool) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if r := s.requests[i]; r.nodeID == nodeID && match(r.msg) {
+			return r.msg, true
+		}
+	}
+	return nil, false
+}
+
+// response returns the response sent to [nodeID] for [requestID].
+func (s *nodeSender) response(nodeID ids.NodeID, requestID uint32) ([]byte, bool) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	for _, r := range s.responses {
+		if r.nodeID == nodeID && r.requestID == requestID {
+			return r.msg, true
+		}
+	}
+	return nil, false
+}
+
+// verifierNetwork is a network of validators, kept in sync by accepting every
+// block on every node.
+type verifierNetwork struct {
+	gen   *genesis.Genesis
+	nodes []*verifierNode
+
+	factory *auth.ED25519Factory // funds the requests
+	addr    codec.Address
+}
+
+// newVerifierNetwork starts [n] validators verifying with [policy], on a
+// chain whose genesis is adjusted by [configure].
+func newVerifierNetwork(n int, policy hub.Policy, configure ...func(*verifierNetwork)) *verifierNetwork {
+	userPriv, err := ed25519.GeneratePrivateKey()
+	gomega.Ω(err).Should(gomega.BeNil())
+	vnet := &verifierNetwork{
+		nodes:   make([]*verifierNode, n),
+		factory: auth.NewED25519Factory(userPriv),
+		addr:    auth.NewED25519Address(userPriv.PublicKey()),
+	}
+
+	var (
+		nodeIDs    = make([]ids.NodeID, n)
+		secretKeys = make([]*bls.SecretKey, n)
+		valPrivs   = make([]ed25519.PrivateKey, n)
+		vdrs       = make(map[ids.NodeID]*validators.GetValidatorOutput, n)
+	)
+	vnet.gen = genesis.Default()
+	vnet.gen.MinUnitPrice = fees.Dimensions{1, 1, 1, 1, 1}
+	vnet.gen.MinBlockGap = 0
+	// proof requests exceed the default block limits
+	vnet.gen.WindowTargetUnits = fees.Dimensions{consts.MaxUint64, consts.MaxUint64, consts.MaxUint64, consts.MaxUint64, consts.MaxUint64}
+	vnet.gen.MaxBlockUnits = fees.Dimensions{1_800_000, consts.MaxUint64, consts.MaxUint64, consts.MaxUint64, consts.MaxUint64}
+	vnet.gen.CustomAllocation = []*genesis.CustomAllocation{
+		{
+			Address: codec.MustAddressBech32(lconsts.HRP, vnet.addr),
+			Balance: 10_000_000,
+		},
+	}
+	for i := 0; i < n; i++ {
+		nodeIDs[i] = ids.GenerateTestNodeID()
+		secretKeys[i], err = bls.NewSecretKey()
+		gomega.Ω(err).Should(gomega.BeNil())
+		valPrivs[i], err = ed25519.GeneratePrivateKey()
+		gomega.Ω(err).Should(gomega.BeNil())
+		vdrs[nodeIDs[i]] = &validators.GetValidatorOutput{
+			NodeID:    nodeIDs[i],
+			PublicKey: bls.PublicFromSecretKey(secretKeys[i]),
+			Weight:    validatorWeight,
+		}
+		vnet.gen.CustomAllocation = append(vnet.gen.CustomAllocation, &genesis.CustomAllocation{
+			Address: codec.MustAddressBech32(lconsts.HRP, auth.NewED25519Address(valPrivs[i].PublicKey())),
+			Balance: validatorBalance,
+		})
+	}
+	for _, f := range configure {
+		f(vnet)
+	}
+	genesisBytes, err := json.Marshal(vnet.gen)
+	gomega.Ω(err).Should(gomega.BeNil())
+
+	subnetID := ids.GenerateTestID()
+	chainID := ids.GenerateTestID()
+	app := &appSender{}
+	for i := range vnet.nodes {
+		l, err := logFactory.Make(nodeIDs[i].String())
+		gomega.Ω(err).Should(gomega.BeNil())
+		dname, err := os.MkdirTemp("", fmt.Sprintf("%s-chainData", nodeIDs[i].String()))
+		gomega.Ω(err).Should(gomega.BeNil())
+		snowCtx := &snow.Context{
+			NetworkID:    networkID,
+			SubnetID:     subnetID,
+			ChainID:      chainID,
+			NodeID:       nodeIDs[i],
+			Log:          l,
+			ChainDataDir: dname,
+			Metrics:      metrics.NewOptionalGatherer(),
+			PublicKey:    bls.PublicFromSecretKey(secretKeys[i]),
+			WarpSigner:   warp.NewSigner(secretKeys[i], networkID, chainID),
+			ValidatorState: &validators.TestState{
+				GetCurrentHeightF: func(context.Context) (uint64, error) {
+					return 0, nil
+				},
+				GetValidatorSetF: func(context.Context, uint64, ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+					return vdrs, nil
+				},
+			},
+		}
+
+		toEngine := make(chan common.Message, 1)
+		verifier := startHub(policy)
+		v := controller.New()
+		sender := &nodeSender{appSender: app, vm: v}
+		err = v.Initialize(
+			context.TODO(),
+			snowCtx,
+			memdb.New(),
+			genesisBytes,
+			nil,
+			nodeConfig(verifier, hex.EncodeToString(valPrivs[i][:]), transports[i%len(transports)], map[string]any{
+				"proofRetentionBlocks": 0,
+				"gcInterval":           100 * time.Millisecond,
+				// the last node keeps every artifact
+				"archival": i == len(vnet.nodes)-1,
+				// the first node doesn't report on itself
+				"adminAPI": i > 0,
+			}),
+			toEngine,
+			nil,
+			sender,
+		)
+		gomega.Ω(err).Should(gomega.BeNil())
+
+		hd, err := v.CreateHandlers(context.TODO())
+		gomega.Ω(err).Should(gomega.BeNil())
+		jsonRPCServer := httptest.NewServer(hd[rpc.JSONRPCEndpoint])
+		ljsonRPCServer := httptest.NewServer(hd[lrpc.JSONRPCEndpoint])
+		webSocketServer := httptest.NewServer(hd[rpc.WebSocketEndpoint])
+		vnet.nodes[i] = &verifierNode{
+			instance: instance{
+				chainID:           chainID,
+				nodeID:            nodeIDs[i],
+				vm:                v,
+				toEngine:          toEngine,
+				JSONRPCServer:     jsonRPCServer,
+				BaseJSONRPCServer: ljsonRPCServer,
+				WebSocketServer:   webSocketServer,
+				cli:               rpc.NewJSONRPCClient(jsonRPCServer.URL),
+				lcli:              lrpc.NewJSONRPCClient(ljsonRPCServer.URL, networkID, chainID),
+				hub:               verifier,
+			},
+			factory:   auth.NewED25519Factory(valPrivs[i]),
+			addr:      auth.NewED25519Address(valPrivs[i].PublicKey()),
+			artifacts: httptest.NewServer(hd[artifacts.ServeEndpoint]),
+			publicKey: bls.PublicKeyToBytes(bls.PublicFromSecretKey(secretKeys[i])),
+			signer:    snowCtx.WarpSigner,
+			sender:    sender,
+		}
+		app.instances = append(app.instances, vnet.nodes[i].instance)
+		v.ForceReady()
+	}
+
+	// nodes find their verifier in the background
+	for _, node := range vnet.nodes {
+		gomega.Eventually(func() bool {
+			status, err := node.lcli.VerifierStatus(context.Background())
+			return err == nil && status.Verifying
+		}, requestTimeout, 100*time.Millisecond).Should(gomega.BeTrue())
+	}
+	return vnet
+}
+
+func (vnet *verifierNetwork) close() {
+	for _, node := range vnet.nodes {
+		node.JSONRPCServer.Close()
+		node.BaseJSONRPCServer.Close()
+		node.WebSocketServer.Close()
+		node.artifacts.Close()
+		node.hub.Close()
+		gomega.Ω(node.vm.Shutdown(context.TODO())).Should(gomega.BeNil())
+	}
+}
+
+// issue submits [action] signed by [factory] to [node].
+func (vnet *verifierNetwork) issue(node *verifierNode, action chain.Action, factory chain.AuthFactory) ids.ID {
+	parser, err := node.lcli.Parser(context.Background())
+	gomega.Ω(err).Should(gomega.BeNil())
+	submit, tx, _, err := node.cli.GenerateTransaction(context.Background(), parser, nil, action, factory)
+	gomega.Ω(err).Should(gomega.BeNil())
+	gomega.Ω(submit(context.Background())).Should(gomega.BeNil())
+	return tx.ID()
+}
+
+// produce builds a block with the mempool of [proposer] and has every node
+// accept it.
+func (vnet *verifierNetwork) produce(proposer *verifierNode) []*chain.Result {
+	ctx := context.TODO()
+	gomega.Ω(proposer.vm.Builder().Force(ctx)).Should(gomega.BeNil())
+	<-proposer.toEngine
+
+	blk, err := proposer.vm.BuildBlock(ctx)
+	gomega.Ω(err).Should(gomega.BeNil())
+	for _, node := range vnet.nodes {
+		nblk := blk
+		if node != proposer {
+			nblk, err = node.vm.ParseBlock(ctx, blk.Bytes())
+			gomega.Ω(err).Should(gomega.BeNil())
+		}
+		gomega.Ω(nblk.Verify(ctx)).Should(gomega.BeNil())
+		gomega.Ω(node.vm.SetPreference(ctx, nblk.ID())).Should(gomega.BeNil())
+		gomega.Ω(nblk.Accept(ctx)).Should(gomega.BeNil())
+		gomega.Ω(nblk.Status()).Should(gomega.Equal(choices.Accepted))
+	}
+	return blk.(*chain.StatelessBlock).Results()
+}
+
+// request issues verification request [action] and returns its ID once it is
+// accepted. The requester pays the fee and funds the reward pool.
+func (vnet *verifierNetwork) request(action chain.Action) ids.ID {
+	ctx := context.Background()
+	node := vnet.nodes[0]
+	addrStr := codec.MustAddressBech32(lconsts.HRP, vnet.addr)
+	before, err := node.lcli.Balance(ctx, addrStr)
+	gomega.Ω(err).Should(gomega.BeNil())
+
+	txID := vnet.issue(node, action, vnet.factory)
+	results := vnet.produce(node)
+	gomega.Ω(results).Should(gomega.HaveLen(1))
+	gomega.Ω(results[0].Success).Should(gomega.BeTrue(), string(results[0].Output))
+
+	after, err := node.lcli.Balance(ctx, addrStr)
+	gomega.Ω(err).Should(gomega.BeNil())
+	gomega.Ω(results[0].Fee).Should(gomega.BeNumerically(">", 0))
+	gomega.Ω(before - after).Should(gomega.Equal(results[0].Fee + vnet.gen.VerificationReward))
+	return txID
+}
+
+// awaitVotes waits for every node to have its vote on [txID] in its mempool.
+func (vnet *verifierNetwork) awaitVotes(txID ids.ID) {
+	for _, node := range vnet.nodes {
+		gomega.Eventually(func() bool {
+			for _, r := range node.hub.Results() {
+				if r.TxID == txID.String() {
+					gomega.Ω(r.Err).Should(gomega.BeNil())
+					return true
+				}
+			}
+			return false
+		}, requestTimeout, 100*time.Millisecond).Should(gomega.BeTrue())
+		gomega.Eventually(func() int {
+			return node.vm.Mempool().Len(context.Background())
+		}, requestTimeout, 100*time.Millisecond).Should(gomega.Equal(1))
+	}
+}
+
+// vote has every node propose a block with its vote on [txID].
+func (vnet *verifierNetwork) vote(txID ids.ID) []*chain.Result {
+	vnet.awaitVotes(txID)
+	var results []*chain.Result
+	for _, node := range vnet.nodes {
+		blkResults := vnet.produce(node)
+		gomega.Ω(blkResults).Should(gomega.HaveLen(1))
+		results = append(results, blkResults...)
+	}
+	return results
+}
+
+// pending returns the action waiting in the mempool of [node] that [match]
+// finds.
+func pending(node *verifierNode, match func(chain.Action) bool) chain.Action {
+	var action chain.Action
+	gomega.Ω(node.vm.Mempool().Top(context.Background(), time.Second, func(_ context.Context, tx *chain.Transaction) (bool, bool, error) {
+		if match(tx.Action) {
+			action = tx.Action
+		}
+		return true, true, nil
+	})).Should(gomega.BeNil())
+	gomega.Ω(action).ShouldNot(gomega.BeNil())
+	return action
+}
+
+// pendingVote returns the vote of [node] on [txID] waiting in its mempool.
+func pendingVote(node *verifierNode, txID ids.ID) *actions.ValidatorVote {
+	return pending(node, func(action chain.Action) bool {
+		v, ok := action.(*actions.ValidatorVote)
+		return ok && v.TxID == txID
+	}).(*actions.ValidatorVote)
+}
+
+// pendingCommitment returns the vote commitment of [node] on [txID] waiting
+// in its mempool.
+func pendingCommitment(node *verifierNode, txID ids.ID) *actions.CommitVote {
+	return pending(node, func(action chain.Action) bool {
+		c, ok := action.(*actions.CommitVote)
+		return ok && c.TxID == txID
+	}).(*actions.CommitVote)
+}
+
+// voteMessage returns the message signed for [vote].
+func voteMessage(vote *actions.ValidatorVote) *actions.VoteMessage {
+	return &actions.VoteMessage{
+		RewardAddress:   vote.RewardAddress,
+		ProvingSystem:   vote.ProvingSystem,
+		TxID:            vote.TxID,
+		ImageID:         vote.ImageID,
+		ArtifactsDigest: vote.ArtifactsDigest,
+		Deadline:        vote.Deadline,
+		Outcome:         vote.Outcome,
+		OutputsDigest:   vote.OutputsDigest,
+	}
+}
+
+// awaitPending waits for [node] to have a single transaction in its mempool.
+func awaitPending(node *verifierNode) {
+	gomega.Eventually(func() int {
+		return node.vm.Mempool().Len(context.Background())
+	}, requestTimeout, 100*time.Millisecond).Should(gomega.Equal(1))
+}
+
+// expectFailure expects the only result of [results] to have failed with
+// [err].
+func expectFailure(results []*chain.Result, err error) {
+	gomega.Ω(results).Should(gomega.HaveLen(1))
+	gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+	gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(err.Error()))
+}
+
+func (vnet *verifierNetwork) expectOutcome(txID ids.ID, valid bool, outcome lconsts.Outcome, votes uint64) {
+	for _, node := range vnet.nodes {
+		status, err := node.lcli.VerifyStatus(context.Background(), txID)
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(status).Should(gomega.Equal(valid))
+		outcomes, err := node.lcli.VerifyOutcomes(context.Background(), txID)
+		gomega.Ω(err).Should(gomega.BeNil())
+		for o, n := range outcomes {
+			if o == outcome.String() {
+				gomega.Ω(n).Should(gomega.Equal(votes))
+			} else {
+				gomega.Ω(n).Should(gomega.BeZero(), o)
+			}
+		}
+	}
+}
+
+// registerSP1 registers an SP1 image and uploads its ELF and a proof, under
+// [proofValType], to every node.
+func (vnet *verifierNetwork) registerSP1() ids.ID {
+	vkeyHash := sha256.Sum256([]byte("sp1 vkey"))
+	imageID := vnet.issue(vnet.nodes[0], &actions.Register{ProovingSystem: uint64(lconsts.SP1ID), ProgramID: vkeyHash[:]}, vnet.factory)
+	expectSuccess(vnet.produce(vnet.nodes[0]))
+	vnet.upload(imageID, actions.ELFValType, []byte("elf"))
+	vnet.upload(imageID, proofValType, []byte("proof"))
+	return imageID
+}
+
+// upload registers [data] as artifact [valType] of [imageID] and uploads it
+// to every node.
+func (vnet *verifierNetwork) upload(imageID ids.ID, valType uint16, data []byte) {
+	root := sha256.Sum256(data)
+	vnet.issue(vnet.nodes[0], &actions.RegisterImage{
+		ImageID:      imageID,
+		ValType:      uint64(valType),
+		Algorithm:    uint8(commitment.SHA256),
+		Digest:       root[:],
+		ArtifactSize: uint64(len(data)),
+	}, vnet.factory)
+	expectSuccess(vnet.produce(vnet.nodes[0]))
+	for _, node := range vnet.nodes {
+		gomega.Ω(submitChunk(node, imageID, valType, data)).Should(gomega.BeNil())
+	}
+}
+
+// submitChunk uploads [data] as the only chunk of an artifact to [node].
+func submitChunk(node *verifierNode, imageID ids.ID, valType uint16, data []byte) error {
+	_, err := node.lcli.SubmitChunk(context.Background(), imageID, valType, 0, data)
+	return err
+}
+
+// submitChunks uploads [data] to [node] with the hypersdk submitChunks RPC.
+// The hypersdk client can't decode its empty reply, so the request is sent
+// here.
+func submitChunks(node *verifierNode, imageID ids.ID, valType uint16, data []byte) error {
+	req := hrequester.New(strings.TrimSuffix(node.JSONRPCServer.URL, "/")+rpc.JSONRPCEndpoint, rpc.Name)
+	return req.SendRequest(context.Background(), "submitChunks", &rpc.SubmitChunksArgs{
+		ImageID:      imageID,
+		ProofValType: valType,
+		Data:         data,
+	}, new(struct{}))
+}
+
+func expectSuccess(results []*chain.Result) {
+	for _, result := range results {
+		gomega.Ω(result.Success).Should(gomega.BeTrue(), string(result.Output))
+	}
+}
+
+var _ = ginkgo.Describe("[Verification]", ginkgo.Ordered, func() {
+	var (
+		vnet    *verifierNetwork
+		imageID ids.ID
+		files   = map[uint16][]byte{
+			actions.ELFValType:       []byte("elf"),
+			proofValType:             []byte("proof"),
+			invalidProofValType:      []byte("invalid proof"),
+			commonDataValType:        []byte("plonky2 common data"),
+			verifierDataValType:      []byte("plonky2 verifier data"),
+			missingProofValType:      []byte("missing proof"),
+			expiringProofValType:     []byte("expiring proof"),
+			midenCodeValType:         []byte("begin push.1 push.2 add end"),
+			midenOutputsValType:      []byte(`{"stack":[3]}`),
+			halo2VerifyingKeyValType: []byte("halo2 verifying key"),
+			halo2InstancesValType:    []byte("halo2 instances"),
+			noirVerifyingKeyValType:  []byte("noir verification key"),
+		}
+		validSP1 ids.ID
+		policy   hub.Policy
+
+		sp1VKeyHash  = sha256.Sum256([]byte("sp1 vkey"))
+		risc0ImageID = sha256.Sum256([]byte("risc0 guest"))
+		risc0Image   ids.ID // registered for RISC Zero, with [risc0ImageID] pinned
+	)
+
+	ginkgo.BeforeAll(func() {
+		// the stand-in verifier accepts every proof but the invalid one
+		publicInputs, err := os.ReadFile(filepath.Join("testdata", "noir", "public_inputs"))
+		gomega.Ω(err).Should(gomega.BeNil())
+		files[noirPublicInputsValType] = publicInputs
+
+		invalid := sha256.Sum256(files[invalidProofValType])
+		policy = hub.HashBased(
+			map[[sha256.Size]byte]lconsts.Outcome{invalid: lconsts.OutcomeInvalid},
+			hub.AlwaysValid(),
+		)
+		vnet = newVerifierNetwork(3, policy)
+	})
+
+	ginkgo.AfterAll(func() {
+		vnet.close()
+	})
+
+	ginkgo.It("registers an image and uploads its artifacts", func() {
+		ginkgo.By("register", func() {
+			imageID = vnet.issue(vnet.nodes[0], &actions.Register{ProovingSystem: uint64(lconsts.SP1ID), ProgramID: sp1VKeyHash[:]}, vnet.factory)
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			expectSuccess(results)
+		})
+
+		ginkgo.By("register artifacts", func() {
+			for valType, data := range files {
+				root := sha256.Sum256(data)
+				vnet.issue(vnet.nodes[0], &actions.RegisterImage{
+					ImageID:      imageID,
+					ValType:      uint64(valType),
+					Algorithm:    uint8(commitment.SHA256),
+					Digest:       root[:],
+					ArtifactSize: uint64(len(data)),
+				}, vnet.factory)
+			}
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(len(files)))
+			expectSuccess(results)
+		})
+
+		ginkgo.By("upload artifacts to every node", func() {
+			for _, node := range vnet.nodes {
+				for valType, data := range files {
+					if valType == missingProofValType {
+						continue
+					}
+					gomega.Ω(submitChunk(node, imageID, valType, data)).Should(gomega.BeNil())
+				}
+			}
+		})
+	})
+
+	ginkgo.It("verifies an SP1 proof", func() {
+		validSP1 = vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(proofValType),
+			TimeOutBlocks: requestTimeOut,
+		})
+		expectSuccess(vnet.vote(validSP1))
+		vnet.expectOutcome(validSP1, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+		for _, node := range vnet.nodes {
+			gomega.Ω(node.hub.Requests()).Should(gomega.HaveKeyWithValue(validSP1.String(), gomega.HaveField("ProvingSystem", "sp1")))
+			req := node.hub.Requests()[validSP1.String()]
+			gomega.Ω(req.Args).Should(gomega.HaveKeyWithValue("vkey_hash", hex.EncodeToString(sp1VKeyHash[:])))
+		}
+	})
+
+	ginkgo.It("speaks the negotiated protocol version", func() {
+		for _, node := range vnet.nodes {
+			status, err := node.lcli.VerifierStatus(context.Background())
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(status.ProtocolVersion).Should(gomega.Equal(protocol.Version))
+			gomega.Ω(node.hub.Requests()).Should(gomega.HaveKeyWithValue(validSP1.String(), gomega.HaveField("Version", protocol.Version)))
+
+			cli := requester.New(node.hub.URL)
+			cli.SetVersion(status.ProtocolVersion)
+			job, err := cli.Status(context.Background(), validSP1.String())
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(job.State).Should(gomega.Equal(protocol.JobDone))
+			gomega.Ω(*job.Outcome).Should(gomega.Equal(lconsts.OutcomeValid))
+		}
+	})
+
+	ginkgo.It("sends artifacts with the transport of each node", func() {
+		proof := files[proofValType]
+		for i, node := range vnet.nodes {
+			req := node.hub.Requests()[validSP1.String()]
+			gomega.Ω(req).ShouldNot(gomega.BeNil())
+			artifact := req.Artifacts[protocol.ArtifactProof]
+			gomega.Ω(artifact).ShouldNot(gomega.BeNil())
+			gomega.Ω(artifact.Check(proof)).Should(gomega.BeNil())
+			gomega.Ω(req.Artifact(protocol.ArtifactProof)).Should(gomega.Equal(proof))
+
+			switch transports[i%len(transports)] {
+			case artifacts.TransportPath:
+				gomega.Ω(req.ProofFilePath()).ShouldNot(gomega.BeEmpty())
+				gomega.Ω(artifact.Data).Should(gomega.BeEmpty())
+				gomega.Ω(artifact.URL).Should(gomega.BeEmpty())
+			case artifacts.TransportContent:
+				gomega.Ω(req.ProofFilePath()).Should(gomega.BeEmpty())
+				gomega.Ω(artifact.Data).Should(gomega.Equal(proof))
+			case artifacts.TransportURL:
+				gomega.Ω(req.ProofFilePath()).Should(gomega.BeEmpty())
+				gomega.Ω(artifact.Data).Should(gomega.BeEmpty())
+
+				ginkgo.By("only serve signed urls", func() {
+					resp, err := http.Get(artifact.URL)
+					gomega.Ω(err).Should(gomega.BeNil())
+					body, err := io.ReadAll(resp.Body)
+					resp.Body.Close()
+					gomega.Ω(err).Should(gomega.BeNil())
+					gomega.Ω(resp.StatusCode).Should(gomega.Equal(http.StatusOK))
+					gomega.Ω(body).Should(gomega.Equal(proof))
+
+					u, err := url.Parse(artifact.URL)
+					gomega.Ω(err).Should(gomega.BeNil())
+					q := u.Query()
+					q.Set("expires", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+					u.RawQuery = q.Encode()
+					resp, err = http.Get(u.String())
+					gomega.Ω(err).Should(gomega.BeNil())
+					resp.Body.Close()
+					gomega.Ω(resp.StatusCode).Should(gomega.Equal(http.StatusForbidden))
+				})
+			}
+		}
+	})
+
+	ginkgo.It("serves uploaded artifacts", func() {
+		do := func(method string, valType uint16, header http.Header) (*http.Response, []byte) {
+			q := url.Values{
+				"image_id": {imageID.String()},
+				"val_type": {strconv.Itoa(int(valType))},
+			}
+			req, err := http.NewRequest(method, vnet.nodes[0].artifacts.URL+"?"+q.Encode(), nil)
+			gomega.Ω(err).Should(gomega.BeNil())
+			for k, v := range header {
+				req.Header[k] = v
+			}
+			resp, err := http.DefaultClient.Do(req)
+			gomega.Ω(err).Should(gomega.BeNil())
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			gomega.Ω(err).Should(gomega.BeNil())
+			return resp, body
+		}
+		get := func(valType uint16, header http.Header) (*http.Response, []byte) {
+			return do(http.MethodGet, valType, header)
+		}
+		proof := files[proofValType]
+		root := sha256.Sum256(proof)
+		etag := `"sha256:` + hex.EncodeToString(root[:]) + `"`
+
+		resp, body := get(proofValType, nil)
+		gomega.Ω(resp.StatusCode).Should(gomega.Equal(http.StatusOK))
+		gomega.Ω(body).Should(gomega.Equal(proof))
+		gomega.Ω(resp.Header.Get("ETag")).Should(gomega.Equal(etag))
+
+		resp, body = get(proofValType, http.Header{"Range": {"bytes=1-3"}})
+		gomega.Ω(resp.StatusCode).Should(gomega.Equal(http.StatusPartialContent))
+		gomega.Ω(body).Should(gomega.Equal(proof[1:4]))
+
+		resp, _ = get(proofValType, http.Header{"If-None-Match": {etag}})
+		gomega.Ω(resp.StatusCode).Should(gomega.Equal(http.StatusNotModified))
+
+		resp, body = do(http.MethodHead, proofValType, nil)
+		gomega.Ω(resp.StatusCode).Should(gomega.Equal(http.StatusOK))
+		gomega.Ω(resp.ContentLength).Should(gomega.Equal(int64(len(proof))))
+		gomega.Ω(resp.Header.Get("ETag")).Should(gomega.Equal(etag))
+		gomega.Ω(body).Should(gomega.BeEmpty())
+
+		resp, _ = get(missingProofValType, nil)
+		gomega.Ω(resp.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		resp, _ = get(unregisteredValType, nil)
+		gomega.Ω(resp.StatusCode).Should(gomega.Equal(http.StatusNotFound))
+	})
+
+	ginkgo.It("charges storage fees and refuses chunks beyond the paid size", func() {
+		ctx := context.Background()
+		node := vnet.nodes[0]
+		addrStr := codec.MustAddressBech32(lconsts.HRP, vnet.addr)
+		data := []byte("paid artifact")
+		root := sha256.Sum256(data)
+
+		ginkgo.By("refuse chunks of unregistered artifacts", func() {
+			_, err := node.lcli.SubmitChunk(ctx, imageID, paidValType, 0, data)
+			gomega.Ω(err).Should(gomega.MatchError(gomega.ContainSubstring(artifacts.ErrNotRegistered.Error())))
+		})
+
+		ginkgo.By("charge the declared size", func() {
+			before, err := node.lcli.Balance(ctx, addrStr)
+			gomega.Ω(err).Should(gomega.BeNil())
+			vnet.issue(node, &actions.RegisterImage{
+				ImageID:      imageID,
+				ValType:      uint64(paidValType),
+				Algorithm:    uint8(commitment.SHA256),
+				Digest:       root[:],
+				ArtifactSize: uint64(len(data)),
+			}, vnet.factory)
+			results := vnet.produce(node)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			expectSuccess(results)
+			after, err := node.lcli.Balance(ctx, addrStr)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(before - after).Should(gomega.Equal(results[0].Fee + uint64(len(data))*vnet.gen.StorageFeePerByte))
+		})
+
+		ginkgo.By("refuse registrations the registrant can't pay for", func() {
+			vnet.issue(node, &actions.RegisterImage{
+				ImageID:      imageID,
+				ValType:      uint64(unregisteredValType),
+				Algorithm:    uint8(commitment.SHA256),
+				Digest:       root[:],
+				ArtifactSize: consts.MaxUint64 / vnet.gen.StorageFeePerByte,
+			}, vnet.factory)
+			results := vnet.produce(node)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring("storage fee"))
+		})
+
+		ginkgo.By("accept chunks up to the paid size", func() {
+			remaining, err := node.lcli.SubmitChunk(ctx, imageID, paidValType, 0, data[:5])
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(remaining).Should(gomega.Equal(uint64(len(data) - 5)))
+			_, err = node.lcli.SubmitChunk(ctx, imageID, paidValType, 1, append(slices.Clone(data[5:]), '!'))
+			gomega.Ω(err).Should(gomega.MatchError(gomega.ContainSubstring(artifacts.ErrExceedsPaidSize.Error())))
+			remaining, err = node.lcli.SubmitChunk(ctx, imageID, paidValType, 1, data[5:])
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(remaining).Should(gomega.BeZero())
+		})
+
+		status := func(node *verifierNode) int {
+			q := url.Values{
+				"image_id": {imageID.String()},
+				"val_type": {strconv.Itoa(int(paidValType))},
+			}
+			resp, err := http.Get(node.artifacts.URL + "?" + q.Encode())
+			gomega.Ω(err).Should(gomega.BeNil())
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		gomega.Ω(status(node)).Should(gomega.Equal(http.StatusOK))
+
+		ginkgo.By("refuse chunks on the hypersdk submitChunks RPC", func() {
+			err := submitChunks(node, imageID, paidValType, []byte("!"))
+			gomega.Ω(err).Should(gomega.MatchError(gomega.ContainSubstring(controller.ErrSubmitChunksDisabled.Error())))
+			gomega.Ω(status(node)).Should(gomega.Equal(http.StatusOK))
+		})
+
+		ginkgo.By("refuse chunks broadcast beyond the paid size", func() {
+			// the chunk as broadcast to a peer by the hypersdk VM of the node
+			peer := vnet.nodes[1]
+			var chunk []byte
+			gomega.Eventually(func() bool {
+				var ok bool
+				chunk, ok = node.sender.request(peer.nodeID, func(msg []byte) bool {
+					return bytes.HasSuffix(msg, data[5:])
+				})
+				return ok
+			}).WithTimeout(requestTimeout).Should(gomega.BeTrue())
+			deliver := func(requestID uint32) []byte {
+				gomega.Ω(peer.vm.AppRequest(ctx, node.nodeID, requestID, time.Now().Add(time.Second), chunk)).Should(gomega.BeNil())
+				response, ok := peer.sender.response(node.nodeID, requestID)
+				gomega.Ω(ok).Should(gomega.BeTrue())
+				return response
+			}
+			gomega.Ω(deliver(math.MaxUint32 - 1)).Should(gomega.BeEmpty())
+			// twice the chunk is beyond the paid size
+			gomega.Ω(string(deliver(math.MaxUint32))).Should(gomega.ContainSubstring(artifacts.ErrExceedsPaidSize.Error()))
+		})
+
+		register := func(factory chain.AuthFactory, digest []byte, size uint64) []*chain.Result {
+			vnet.issue(node, &actions.RegisterImage{
+				ImageID:      imageID,
+				ValType:      uint64(paidValType),
+				Algorithm:    uint8(commitment.SHA256),
+				Digest:       digest,
+				ArtifactSize: size,
+			}, factory)
+			return vnet.produce(node)
+		}
+		other := sha256.Sum256([]byte("other artifact"))
+
+		ginkgo.By("refuse registrations over the artifact of another account", func() {
+			expectFailure(register(vnet.nodes[1].factory, root[:], 1), actions.ErrNotArtifactRegistrant)
+			expectFailure(register(vnet.nodes[1].factory, other[:], 1), actions.ErrNotArtifactRegistrant)
+		})
+
+		ginkgo.By("refuse registrations for another commitment", func() {
+			expectFailure(register(vnet.factory, other[:], 1), actions.ErrArtifactRegistered)
+			gomega.Ω(status(node)).Should(gomega.Equal(http.StatusOK))
+		})
+
+		ginkgo.By("charge the size increase only", func() {
+			before, err := node.lcli.Balance(ctx, addrStr)
+			gomega.Ω(err).Should(gomega.BeNil())
+			results := register(vnet.factory, root[:], uint64(len(data))+5)
+			expectSuccess(results)
+			after, err := node.lcli.Balance(ctx, addrStr)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(before - after).Should(gomega.Equal(results[0].Fee + 5*vnet.gen.StorageFeePerByte))
+
+			// the paid size never shrinks
+			before = after
+			results = register(vnet.factory, root[:], 1)
+			expectSuccess(results)
+			after, err = node.lcli.Balance(ctx, addrStr)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(before - after).Should(gomega.Equal(results[0].Fee))
+			gomega.Ω(status(node)).Should(gomega.Equal(http.StatusOK))
+		})
+	})
+
+	ginkgo.It("checks artifacts against their commitment", func() {
+		ctx := context.Background()
+		node := vnet.nodes[0]
+		// spans several merkle chunks
+		data := make([]byte, 2*commitment.MerkleChunkSize+1)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		register := func(valType uint16, algorithm uint8, digest []byte) *chain.Result {
+			vnet.issue(node, &actions.RegisterImage{
+				ImageID:      imageID,
+				ValType:      uint64(valType),
+				Algorithm:    algorithm,
+				Digest:       digest,
+				ArtifactSize: uint64(len(data)),
+			}, vnet.factory)
+			results := vnet.produce(node)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			return results[0]
+		}
+		get := func(valType uint16) *http.Response {
+			q := url.Values{
+				"image_id": {imageID.String()},
+				"val_type": {strconv.Itoa(int(valType))},
+			}
+			resp, err := http.Get(node.artifacts.URL + "?" + q.Encode())
+			gomega.Ω(err).Should(gomega.BeNil())
+			resp.Body.Close()
+			return resp
+		}
+
+		algorithms := commitment.Algorithms()
+		for i, algorithm := range algorithms {
+			valType := committedValType + uint16(i)
+			c, err := commitment.Compute(algorithm, data)
+			gomega.Ω(err).Should(gomega.BeNil())
+			expectSuccess([]*chain.Result{register(valType, uint8(algorithm), c.Digest)})
+			remaining, err := node.lcli.SubmitChunk(ctx, imageID, valType, 0, data)
+			gomega.Ω(err).Should(gomega.BeNil(), algorithm.String())
+			gomega.Ω(remaining).Should(gomega.BeZero())
+			resp := get(valType)
+			gomega.Ω(resp.StatusCode).Should(gomega.Equal(http.StatusOK))
+			gomega.Ω(resp.Header.Get("ETag")).Should(gomega.Equal(strconv.Quote(c.String())))
+		}
+
+		ginkgo.By("refuse invalid commitments", func() {
+			valType := committedValType + uint16(len(algorithms))
+			result := register(valType, 0, make([]byte, 32))
+			gomega.Ω(result.Success).Should(gomega.BeFalse())
+			gomega.Ω(string(result.Output)).Should(gomega.ContainSubstring(commitment.ErrUnknownAlgorithm.Error()))
+			result = register(valType, uint8(commitment.Keccak256), make([]byte, 31))
+			gomega.Ω(result.Success).Should(gomega.BeFalse())
+			gomega.Ω(string(result.Output)).Should(gomega.ContainSubstring(commitment.ErrDigestLength.Error()))
+		})
+
+		ginkgo.By("drop uploads that don't match their commitment", func() {
+			valType := committedValType + uint16(len(algorithms))
+			c, err := commitment.Compute(commitment.BLAKE3, []byte("something else"))
+			gomega.Ω(err).Should(gomega.BeNil())
+			expectSuccess([]*chain.Result{register(valType, uint8(c.Algorithm), c.Digest)})
+			_, err = node.lcli.SubmitChunk(ctx, imageID, valType, 0, data)
+			gomega.Ω(err).Should(gomega.MatchError(gomega.ContainSubstring(commitment.ErrMismatch.Error())))
+			gomega.Ω(get(valType).StatusCode).Should(gomega.Equal(http.StatusNotFound))
+		})
+	})
+
+	ginkgo.It("checks artifacts on receipt", func() {
+		h := startHub(hub.AlwaysValid())
+		defer h.Close()
+		cli := requester.New(h.URL)
+		cli.SetVersion(protocol.Version)
+
+		proof := protocol.NewArtifact(files[proofValType])
+		proof.Data = []byte("tampered proof")
+		gomega.Ω(cli.Post(context.Background(), protocol.RISCZEROENDPOINT, "", &protocol.RiscZeroJob{
+			TxID:      ids.Empty.String(),
+			Artifacts: map[string]*protocol.Artifact{protocol.ArtifactProof: proof},
+		}, new(protocol.SubmitReply))).Should(gomega.BeNil())
+		gomega.Ω(cli.Post(context.Background(), protocol.VERIFYENDPOINT, "", &protocol.VerifyRequest{
+			TxID:       ids.Empty.String(),
+			VerifyType: protocol.RISCZEROVERIFY,
+		}, new(protocol.SubmitReply))).Should(gomega.BeNil())
+		gomega.Eventually(h.Results, requestTimeout, 50*time.Millisecond).Should(gomega.ContainElement(
+			gomega.HaveField("Outcome", lconsts.OutcomeMalformedArtifact),
+		))
+	})
+
+	ginkgo.It("streams the status of a job", func() {
+		for _, node := range vnet.nodes {
+			node.hub.SetPolicy(hub.Delay(2*time.Second, policy))
+		}
+		defer func() {
+			for _, node := range vnet.nodes {
+				node.hub.SetPolicy(policy)
+			}
+		}()
+		txID := vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(proofValType),
+			TimeOutBlocks: requestTimeOut + 1,
+		})
+		cli := requester.New(vnet.nodes[0].hub.URL)
+		cli.SetVersion(protocol.Version)
+		gomega.Eventually(func() protocol.JobState {
+			job, err := cli.Status(context.Background(), txID.String())
+			if err != nil {
+				return ""
+			}
+			return job.State
+		}, requestTimeout, 50*time.Millisecond).Should(gomega.Equal(protocol.JobVerifying))
+
+		var states []protocol.JobState
+		var outcome *lconsts.Outcome
+		gomega.Ω(cli.Watch(context.Background(), txID.String(), func(job *protocol.JobStatus) {
+			states = append(states, job.State)
+			outcome = job.Outcome
+		})).Should(gomega.BeNil())
+		gomega.Ω(states).Should(gomega.Equal([]protocol.JobState{protocol.JobVerifying, protocol.JobDone}))
+		gomega.Ω(*outcome).Should(gomega.Equal(lconsts.OutcomeValid))
+
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+	})
+
+	ginkgo.It("verifies requests accepted while no verifier was reachable", func() {
+		for _, node := range vnet.nodes {
+			node.hub.SetPolicy(hub.Error(errors.New("verifier down")))
+		}
+		txID := vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(proofValType),
+			TimeOutBlocks: requestTimeOut + 2,
+		})
+		for _, node := range vnet.nodes {
+			gomega.Eventually(func() int {
+				status, err := node.lcli.VerifierStatus(context.Background())
+				gomega.Ω(err).Should(gomega.BeNil())
+				return status.Unverified
+			}, requestTimeout, 100*time.Millisecond).Should(gomega.Equal(1))
+			node.hub.SetPolicy(policy)
+		}
+
+		// handed to the verifier again on the next health check
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+		for _, node := range vnet.nodes {
+			status, err := node.lcli.VerifierStatus(context.Background())
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(status.Unverified).Should(gomega.BeZero())
+		}
+	})
+
+	ginkgo.It("negotiates the protocol version with older hubs", func() {
+		legacy := startHubWithConfig(&hub.Config{
+			Policy:   hub.AlwaysValid(),
+			Versions: []uint32{protocol.LegacyVersion},
+		})
+		defer legacy.Close()
+
+		reply, err := requester.Ping(requester.New(legacy.URL))
+		gomega.Ω(err).Should(gomega.BeNil())
+		version, err := protocol.Negotiate(reply.Versions)
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(version).Should(gomega.Equal(protocol.LegacyVersion))
+
+		// the hub refuses versions it didn't advertise
+		cli := requester.New(legacy.URL)
+		cli.SetVersion(protocol.Version)
+		_, err = requester.PingSingle(cli)
+		gomega.Ω(err).Should(gomega.MatchError(gomega.ContainSubstring(protocol.ErrUnsupportedVersion.Error())))
+		// and the node doesn't ask hubs for job status they don't serve
+		cli.SetVersion(version)
+		_, err = cli.Status(context.Background(), ids.Empty.String())
+		gomega.Ω(err).Should(gomega.MatchError(requester.ErrStatusUnsupported))
+
+		_, err = protocol.Negotiate([]uint32{protocol.Version + 1})
+		gomega.Ω(err).Should(gomega.MatchError(protocol.ErrUnsupportedVersion))
+	})
+
+	ginkgo.It("pins programs when registering images", func() {
+		ginkgo.By("refuse SP1 and RISC Zero images without program", func() {
+			for _, ps := range []uint8{lconsts.SP1ID, lconsts.RiscZeroID} {
+				vnet.issue(vnet.nodes[0], &actions.Register{ProovingSystem: uint64(ps)}, vnet.factory)
+				results := vnet.produce(vnet.nodes[0])
+				gomega.Ω(results).Should(gomega.HaveLen(1))
+				gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+				gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrProgramIDLength.Error()))
+			}
+		})
+
+		ginkgo.By("register a RISC Zero image", func() {
+			risc0Image = vnet.issue(vnet.nodes[0], &actions.Register{ProovingSystem: uint64(lconsts.RiscZeroID), ProgramID: risc0ImageID[:]}, vnet.factory)
+			proof := files[proofValType]
+			root := sha256.Sum256(proof)
+			vnet.issue(vnet.nodes[0], &actions.RegisterImage{
+				ImageID:      risc0Image,
+				ValType:      uint64(proofValType),
+				Algorithm:    uint8(commitment.SHA256),
+				Digest:       root[:],
+				ArtifactSize: uint64(len(proof)),
+			}, vnet.factory)
+			expectSuccess(vnet.produce(vnet.nodes[0]))
+			for _, node := range vnet.nodes {
+				gomega.Ω(submitChunk(node, risc0Image, proofValType, proof)).Should(gomega.BeNil())
+			}
+		})
+
+		ginkgo.By("refuse requests against another program", func() {
+			for _, action := range []*actions.RiscZero{
+				{ImageID: imageID, ProofValType: uint64(proofValType), TimeOutBlocks: requestTimeOut},
+				{ImageID: risc0Image, ProofValType: uint64(proofValType), RiscZeroImageID: hex.EncodeToString(sp1VKeyHash[:]), TimeOutBlocks: requestTimeOut},
+			} {
+				vnet.issue(vnet.nodes[0], action, vnet.factory)
+			}
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(2))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrImageProvingSystem.Error()))
+			gomega.Ω(results[1].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[1].Output)).Should(gomega.ContainSubstring(actions.ErrProgramIDMismatch.Error()))
+		})
+	})
+
+	ginkgo.It("verifies a Cairo proof against its pinned program hash", func() {
+		// program hashes are field elements, printed without leading zeros
+		const programHash = "0x3e6ff6b4c8a9c8e2c8f0d6c37b0c5a2f4f5e3c3d9e1f3a7b2c6d8e9f0a1b2c"
+		pinned, err := actions.ParseCairoProgramHash(programHash)
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(pinned).Should(gomega.HaveLen(actions.ProgramIDLen))
+
+		var cairoImage ids.ID
+		ginkgo.By("register a Cairo image", func() {
+			vnet.issue(vnet.nodes[0], &actions.Register{ProovingSystem: uint64(lconsts.CairoID)}, vnet.factory)
+			cairoImage = vnet.issue(vnet.nodes[0], &actions.Register{ProovingSystem: uint64(lconsts.CairoID), ProgramID: pinned}, vnet.factory)
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(2))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrProgramIDLength.Error()))
+			expectSuccess(results[1:])
+
+			proof := files[proofValType]
+			root := sha256.Sum256(proof)
+			vnet.issue(vnet.nodes[0], &actions.RegisterImage{
+				ImageID:      cairoImage,
+				ValType:      uint64(proofValType),
+				Algorithm:    uint8(commitment.SHA256),
+				Digest:       root[:],
+				ArtifactSize: uint64(len(proof)),
+			}, vnet.factory)
+			expectSuccess(vnet.produce(vnet.nodes[0]))
+			for _, node := range vnet.nodes {
+				gomega.Ω(submitChunk(node, cairoImage, proofValType, proof)).Should(gomega.BeNil())
+			}
+		})
+
+		ginkgo.By("refuse requests naming another program", func() {
+			vnet.issue(vnet.nodes[0], &actions.Cairo{
+				ImageID:       cairoImage,
+				ProofValType:  uint64(proofValType),
+				ProgramHash:   "0x1",
+				TimeOutBlocks: requestTimeOut,
+			}, vnet.factory)
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrProgramIDMismatch.Error()))
+		})
+
+		// verifiers report the hash of the program output
+		outputHash := sha256.Sum256([]byte("cairo program output"))
+		for _, node := range vnet.nodes {
+			node.hub.SetPolicy(hub.Outputs(func(*hub.Request) []byte { return outputHash[:] }, policy))
+		}
+		defer func() {
+			for _, node := range vnet.nodes {
+				node.hub.SetPolicy(policy)
+			}
+		}()
+		txID := vnet.request(&actions.Cairo{
+			ImageID:       cairoImage,
+			ProofValType:  uint64(proofValType),
+			ProgramHash:   programHash,
+			TimeOutBlocks: requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+		for _, node := range vnet.nodes {
+			req := node.hub.Requests()[txID.String()]
+			gomega.Ω(req).ShouldNot(gomega.BeNil())
+			gomega.Ω(req.ProvingSystem).Should(gomega.Equal("cairo"))
+			gomega.Ω(req.Args).Should(gomega.HaveKeyWithValue("program_hash", hex.EncodeToString(pinned)))
+			outputs, err := node.lcli.VerifyOutputs(context.Background(), txID)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(outputs).Should(gomega.Equal(outputHash[:]))
+		}
+	})
+
+	ginkgo.It("records IVC steps of increasing step counts", func() {
+		initialState := []byte("z0")
+		var novaImage, instanceID ids.ID
+		ginkgo.By("open an instance over a Nova image", func() {
+			novaImage = vnet.issue(vnet.nodes[0], &actions.Register{ProovingSystem: uint64(lconsts.NovaID)}, vnet.factory)
+			expectSuccess(vnet.produce(vnet.nodes[0]))
+			vnet.upload(novaImage, proofValType, files[proofValType])
+			vnet.upload(novaImage, novaVerifyingKeyValType, []byte("nova verifying key"))
+
+			vnet.issue(vnet.nodes[0], &actions.OpenIVC{ImageID: imageID, InitialState: initialState}, vnet.factory)
+			instanceID = vnet.issue(vnet.nodes[0], &actions.OpenIVC{ImageID: novaImage, InitialState: initialState}, vnet.factory)
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(2))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrImageProvingSystem.Error()))
+			expectSuccess(results[1:])
+		})
+
+		// verifiers report the digest of the state reached after the step
+		stateCommitment := func(step uint64) []byte {
+			digest := sha256.Sum256([]byte(fmt.Sprintf("state %d", step)))
+			return digest[:]
+		}
+		for _, node := range vnet.nodes {
+			node.hub.SetPolicy(hub.Outputs(func(req *hub.Request) []byte {
+				step, _ := req.Args["step"].(float64)
+				return stateCommitment(uint64(step))
+			}, policy))
+		}
+		defer func() {
+			for _, node := range vnet.nodes {
+				node.hub.SetPolicy(policy)
+			}
+		}()
+		step := func(n uint64) *actions.IVCStep {
+			return &actions.IVCStep{
+				InstanceID:          instanceID,
+				ImageID:             novaImage,
+				Step:                n,
+				ProofValType:        uint64(proofValType),
+				VerifyingKeyValType: uint64(novaVerifyingKeyValType),
+				TimeOutBlocks:       requestTimeOut,
+			}
+		}
+		record := func(txID ids.ID) *chain.Result {
+			vnet.issue(vnet.nodes[0], &actions.RecordIVCStep{InstanceID: instanceID, TxID: txID}, vnet.factory)
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			return results[0]
+		}
+		expectStep := func(n uint64) {
+			for _, node := range vnet.nodes {
+				ivc, err := node.lcli.IVCStatus(context.Background(), instanceID)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(ivc.ImageID).Should(gomega.Equal(novaImage))
+				gomega.Ω(ivc.Step).Should(gomega.Equal(n))
+				gomega.Ω(ivc.StateCommitment).Should(gomega.Equal(hex.EncodeToString(stateCommitment(n))))
+			}
+		}
+
+		ginkgo.By("verify and record a step", func() {
+			txID := vnet.request(step(2))
+			expectSuccess(vnet.vote(txID))
+			vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+			for _, node := range vnet.nodes {
+				req := node.hub.Requests()[txID.String()]
+				gomega.Ω(req).ShouldNot(gomega.BeNil())
+				gomega.Ω(req.ProvingSystem).Should(gomega.Equal("nova"))
+				gomega.Ω(req.Args).Should(gomega.HaveKeyWithValue("instance_id", instanceID.String()))
+				gomega.Ω(req.Args).Should(gomega.HaveKeyWithValue("initial_state", hex.EncodeToString(initialState)))
+			}
+			expectSuccess([]*chain.Result{record(txID)})
+			expectStep(2)
+		})
+
+		ginkgo.By("refuse steps not beyond the latest verified one", func() {
+			vnet.issue(vnet.nodes[0], step(1), vnet.factory)
+			vnet.issue(vnet.nodes[0], step(3), vnet.nodes[0].factory)
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(2))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrStaleIVCStep.Error()))
+			gomega.Ω(results[1].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[1].Output)).Should(gomega.ContainSubstring(actions.ErrNotIVCOwner.Error()))
+		})
+
+		ginkgo.By("never record steps verified out of order", func() {
+			later := vnet.request(step(5))
+			expectSuccess(vnet.vote(later))
+			earlier := vnet.request(step(4))
+			expectSuccess(vnet.vote(earlier))
+			expectSuccess([]*chain.Result{record(later)})
+			result := record(earlier)
+			gomega.Ω(result.Success).Should(gomega.BeFalse())
+			gomega.Ω(string(result.Output)).Should(gomega.ContainSubstring(actions.ErrStaleIVCStep.Error()))
+			expectStep(5)
+		})
+	})
+
+	ginkgo.It("verifies a RiscZero proof", func() {
+		txID := vnet.request(&actions.RiscZero{
+			ImageID:         risc0Image,
+			ProofValType:    uint64(proofValType),
+			RiscZeroImageID: "0x" + hex.EncodeToString(risc0ImageID[:]),
+			TimeOutBlocks:   requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+		for _, node := range vnet.nodes {
+			req := node.hub.Requests()[txID.String()]
+			gomega.Ω(req).ShouldNot(gomega.BeNil())
+			gomega.Ω(req.Args).Should(gomega.HaveKeyWithValue("risc_zero_image_id", hex.EncodeToString(risc0ImageID[:])))
+		}
+	})
+
+	ginkgo.It("verifies RISC Zero proofs split in segments", func() {
+		// segments run the execution from one cycle to another, and continue
+		// the segment before them if they start where it stopped
+		segments := []string{"0 100", "100 250", "250 300"}
+		for i, segment := range segments {
+			vnet.upload(risc0Image, segmentValType+uint16(i), []byte(segment))
+		}
+		cycles := func(segment []byte) (start int, end int) {
+			_, _ = fmt.Sscanf(string(segment), "%d %d", &start, &end)
+			return start, end
+		}
+		for _, node := range vnet.nodes {
+			node.hub.SetPolicy(hub.Segmented(func(prev, segment []byte) lconsts.Outcome {
+				_, stopped := cycles(prev)
+				if start, _ := cycles(segment); start != stopped {
+					return lconsts.OutcomeInvalid
+				}
+				return lconsts.OutcomeValid
+			}))
+		}
+		defer func() {
+			for _, node := range vnet.nodes {
+				node.hub.SetPolicy(policy)
+			}
+		}()
+		request := func(segments ...uint16) *actions.RiscZero {
+			return &actions.RiscZero{
+				ImageID:         risc0Image,
+				ProofValType:    uint64(segmentValType),
+				SegmentValTypes: segments,
+				TimeOutBlocks:   requestTimeOut,
+			}
+		}
+		expectStatus := func(txID ids.ID, verified int, failed *int) {
+			for _, node := range vnet.nodes {
+				gomega.Ω(node.hub.Requests()[txID.String()].Segments).Should(gomega.Equal(len(segments)))
+				cli := requester.New(node.hub.URL)
+				cli.SetVersion(protocol.Version)
+				job, err := cli.Status(context.Background(), txID.String())
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(job.Segments).Should(gomega.Equal(len(segments)))
+				gomega.Ω(job.VerifiedSegments).Should(gomega.Equal(verified))
+				gomega.Ω(job.FailedSegment).Should(gomega.Equal(failed))
+			}
+		}
+
+		ginkgo.By("verify every segment in order", func() {
+			txID := vnet.request(request(segmentValType+1, segmentValType+2))
+			expectSuccess(vnet.vote(txID))
+			vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+			expectStatus(txID, len(segments), nil)
+		})
+
+		ginkgo.By("report the first segment not continuing the one before it", func() {
+			txID := vnet.request(request(segmentValType+2, segmentValType+1))
+			expectSuccess(vnet.vote(txID))
+			vnet.expectOutcome(txID, false, lconsts.OutcomeInvalid, uint64(len(vnet.nodes)))
+			failed := 1
+			expectStatus(txID, 1, &failed)
+		})
+
+		ginkgo.By("refuse segments requested twice", func() {
+			vnet.issue(vnet.nodes[0], request(segmentValType+1, segmentValType+1), vnet.factory)
+			vnet.issue(vnet.nodes[0], request(segmentValType+1, segmentValType), vnet.factory)
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(2))
+			for _, result := range results {
+				gomega.Ω(result.Success).Should(gomega.BeFalse())
+				gomega.Ω(string(result.Output)).Should(gomega.ContainSubstring(actions.ErrDuplicateSegment.Error()))
+			}
+		})
+	})
+
+	ginkgo.It("verifies a Miden proof", func() {
+		txID := vnet.request(&actions.Miden{
+			ImageID:         imageID,
+			ProofValType:    uint64(proofValType),
+			CodeFrontEnd:    "begin push.1 push.2 add end",
+			InputsFrontEnd:  `{"operand_stack":[]}`,
+			OutputsFrontEnd: `{"stack":[3]}`,
+			TimeOutBlocks:   requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+	})
+
+	ginkgo.It("verifies a Miden proof against registered artifacts", func() {
+		txID := vnet.request(&actions.Miden{
+			ImageID:        imageID,
+			ProofValType:   uint64(proofValType),
+			CodeValType:    uint64(midenCodeValType),
+			OutputsValType: uint64(midenOutputsValType),
+			InputsFrontEnd: `{"operand_stack":[]}`,
+			TimeOutBlocks:  requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+		for _, node := range vnet.nodes {
+			req := node.hub.Requests()[txID.String()]
+			gomega.Ω(req).ShouldNot(gomega.BeNil())
+			gomega.Ω(req.Artifact(protocol.ArtifactCode)).Should(gomega.Equal(files[midenCodeValType]))
+			gomega.Ω(req.Artifact(protocol.ArtifactOutputs)).Should(gomega.Equal(files[midenOutputsValType]))
+			gomega.Ω(req.Artifact(protocol.ArtifactInputs)).Should(gomega.BeNil())
+			gomega.Ω(req.Args).Should(gomega.HaveKeyWithValue("inputs_front_end", `{"operand_stack":[]}`))
+		}
+	})
+
+	ginkgo.It("refuses Miden inputs both inline and registered", func() {
+		vnet.issue(vnet.nodes[0], &actions.Miden{
+			ImageID:         imageID,
+			ProofValType:    uint64(proofValType),
+			CodeValType:     uint64(midenCodeValType),
+			CodeFrontEnd:    "begin push.1 end",
+			InputsFrontEnd:  `{"operand_stack":[]}`,
+			OutputsFrontEnd: `{"stack":[1]}`,
+			TimeOutBlocks:   requestTimeOut,
+		}, vnet.factory)
+		results := vnet.produce(vnet.nodes[0])
+		gomega.Ω(results).Should(gomega.HaveLen(1))
+		gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+		gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrInlineAndArtifact.Error()))
+	})
+
+	ginkgo.It("verifies a Jolt proof", func() {
+		txID := vnet.request(&actions.Jolt{
+			ImageID:       imageID,
+			ProofValType:  uint64(proofValType),
+			TimeOutBlocks: requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+	})
+
+	ginkgo.It("verifies a PLONKY2 proof", func() {
+		txID := vnet.request(&actions.PLONKY2{
+			ImageID:             imageID,
+			ProofValType:        uint64(proofValType),
+			CommonDataValType:   uint64(commonDataValType),
+			VerifierDataValType: uint64(verifierDataValType),
+			TimeOutBlocks:       requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+		for _, node := range vnet.nodes {
+			gomega.Ω(node.hub.Requests()).Should(gomega.HaveKeyWithValue(txID.String(), gomega.HaveField("ProvingSystem", "plonky2")))
+		}
+	})
+
+	ginkgo.It("verifies a Halo2 proof", func() {
+		txID := vnet.request(&actions.Halo2{
+			ImageID:             imageID,
+			ProofValType:        uint64(proofValType),
+			VerifyingKeyValType: uint64(halo2VerifyingKeyValType),
+			InstancesValType:    uint64(halo2InstancesValType),
+			TimeOutBlocks:       requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+		for _, node := range vnet.nodes {
+			req := node.hub.Requests()[txID.String()]
+			gomega.Ω(req).ShouldNot(gomega.BeNil())
+			gomega.Ω(req.ProvingSystem).Should(gomega.Equal("halo2"))
+			gomega.Ω(req.Artifact(protocol.ArtifactVerifyingKey)).Should(gomega.Equal(files[halo2VerifyingKeyValType]))
+			gomega.Ω(req.Artifact(protocol.ArtifactInstances)).Should(gomega.Equal(files[halo2InstancesValType]))
+		}
+	})
+
+	ginkgo.It("verifies a Noir proof", func() {
+		txID := vnet.request(&actions.Noir{
+			ImageID:             imageID,
+			Flavor:              uint8(lconsts.NoirUltraHonk),
+			ProofValType:        uint64(proofValType),
+			VerifyingKeyValType: uint64(noirVerifyingKeyValType),
+			PublicInputsValType: uint64(noirPublicInputsValType),
+			TimeOutBlocks:       requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+		for _, node := range vnet.nodes {
+			req := node.hub.Requests()[txID.String()]
+			gomega.Ω(req).ShouldNot(gomega.BeNil())
+			gomega.Ω(req.ProvingSystem).Should(gomega.Equal("noir"))
+			gomega.Ω(req.Args).Should(gomega.HaveKeyWithValue("flavor", "ultra_honk"))
+			gomega.Ω(req.Artifact(protocol.ArtifactVerifyingKey)).Should(gomega.Equal(files[noirVerifyingKeyValType]))
+			gomega.Ω(req.Artifact(protocol.ArtifactPublicInputs)).Should(gomega.Equal(files[noirPublicInputsValType]))
+		}
+	})
+
+	ginkgo.It("refuses Noir proofs of unknown flavors", func() {
+		vnet.issue(vnet.nodes[0], &actions.Noir{
+			ImageID:             imageID,
+			Flavor:              uint8(lconsts.NoirUltraHonk) + 1,
+			ProofValType:        uint64(proofValType),
+			VerifyingKeyValType: uint64(noirVerifyingKeyValType),
+			PublicInputsValType: uint64(noirPublicInputsValType),
+			TimeOutBlocks:       requestTimeOut,
+		}, vnet.factory)
+		results := vnet.produce(vnet.nodes[0])
+		gomega.Ω(results).Should(gomega.HaveLen(1))
+		gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+		gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrUnknownNoirFlavor.Error()))
+	})
+
+	ginkgo.It("verifies a snarkjs Groth16 proof in-process", func() {
+		// testdata/groth16 holds a proof simulated with the trapdoor of its
+		// verifying key, in the format of snarkjs
+		fixture := func(name string) string {
+			data, err := os.ReadFile(filepath.Join("testdata", "groth16", name))
+			gomega.Ω(err).Should(gomega.BeNil())
+			return string(data)
+		}
+		action := &actions.Groth16{
+			Proof:        fixture("proof.json"),
+			VerifyingKey: fixture("verification_key.json"),
+			PublicInputs: fixture("public.json"),
+		}
+		inputs, err := groth16.ParsePublicInputs([]byte(action.PublicInputs))
+		gomega.Ω(err).Should(gomega.BeNil())
+
+		txID := vnet.issue(vnet.nodes[0], action, vnet.factory)
+		results := vnet.produce(vnet.nodes[0])
+		gomega.Ω(results).Should(gomega.HaveLen(1))
+		expectSuccess(results)
+		gomega.Ω(results[0].Consumed[fees.Compute]).Should(gomega.BeNumerically(">=", actions.Groth16ComputeUnits+2*actions.Groth16PublicInputComputeUnits))
+		for _, node := range vnet.nodes {
+			gomega.Ω(node.hub.Requests()).ShouldNot(gomega.HaveKey(txID.String()))
+			outputs, err := node.lcli.VerifyOutputs(context.Background(), txID)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(outputs).Should(gomega.Equal(groth16.Digest(inputs)))
+		}
+
+		ginkgo.By("reject the proof for other public inputs", func() {
+			action.PublicInputs = `["33", "4"]`
+			txID := vnet.issue(vnet.nodes[0], action, vnet.factory)
+			results := vnet.produce(vnet.nodes[0])
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(groth16.ErrInvalidProof.Error()))
+			status, err := vnet.nodes[0].lcli.VerifyStatus(context.Background(), txID)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(status).Should(gomega.BeFalse())
+		})
+
+		ginkgo.By("reject proofs with points at infinity", func() {
+			infinity := map[string]any{
+				"pi_a": []string{"0", "0", "1"},
+				"pi_b": [][]string{{"0", "0"}, {"0", "0"}, {"1", "0"}},
+				"pi_c": []string{"0", "0", "1"},
+			}
+			for name, point := range infinity {
+				var proof map[string]any
+				gomega.Ω(json.Unmarshal([]byte(fixture("proof.json")), &proof)).Should(gomega.BeNil())
+				proof[name] = point
+				b, err := json.Marshal(proof)
+				gomega.Ω(err).Should(gomega.BeNil())
+				_, err = groth16.ParseProof(b)
+				gomega.Ω(err).Should(gomega.MatchError(groth16.ErrMalformed))
+				gomega.Ω(err).Should(gomega.MatchError(gomega.ContainSubstring("point at infinity")))
+			}
+		})
+
+		ginkgo.By("reject proofs with points outside of the subgroup", func() {
+			// on the twist, but not in the subgroup of prime order
+			var proof map[string]any
+			gomega.Ω(json.Unmarshal([]byte(fixture("proof.json")), &proof)).Should(gomega.BeNil())
+			proof["pi_b"] = [][]string{
+				{"1", "0"},
+				{
+					"18278151005453108793778860132295291098363647455926340152056652516292830556603",
+					"5912654199736721486680175016176231956195085055698687135131307249486702594212",
+				},
+				{"1", "0"},
+			}
+			b, err := json.Marshal(proof)
+			gomega.Ω(err).Should(gomega.BeNil())
+			_, err = groth16.ParseProof(b)
+			gomega.Ω(err).Should(gomega.MatchError(groth16.ErrMalformed))
+			gomega.Ω(err).Should(gomega.MatchError(gomega.ContainSubstring("malformed point")))
+		})
+	})
+
+	ginkgo.It("rejects an invalid proof", func() {
+		txID := vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(invalidProofValType),
+			TimeOutBlocks: requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, false, lconsts.OutcomeInvalid, uint64(len(vnet.nodes)))
+	})
+
+	ginkgo.It("reports proofs the verifier can't find", func() {
+		txID := vnet.request(&actions.Jolt{
+			ImageID:       imageID,
+			ProofValType:  uint64(missingProofValType),
+			TimeOutBlocks: requestTimeOut,
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, false, lconsts.OutcomeArtifactMissing, uint64(len(vnet.nodes)))
+	})
+
+	ginkgo.It("refuses requests for unregistered artifacts", func() {
+		txID := vnet.issue(vnet.nodes[0], &actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(unregisteredValType),
+			TimeOutBlocks: requestTimeOut,
+		}, vnet.factory)
+		results := vnet.produce(vnet.nodes[0])
+		gomega.Ω(results).Should(gomega.HaveLen(1))
+		gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+		gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrArtifactNotRegistered.Error()))
+
+		// failed requests are never handed to the verifiers
+		time.Sleep(time.Second)
+		for _, node := range vnet.nodes {
+			gomega.Ω(node.hub.Requests()).ShouldNot(gomega.HaveKey(txID.String()))
+		}
+	})
+
+	ginkgo.It("refuses votes for other artifacts", func() {
+		txID := vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(proofValType),
+			TimeOutBlocks: 2 * requestTimeOut, // not to clash with other requests
+		})
+		vnet.awaitVotes(txID)
+		// a vote for the request, bound to another image
+		node := vnet.nodes[0]
+		forged := &actions.ValidatorVote{
+			TxID:            txID,
+			RewardAddress:   node.addr,
+			Outcome:         lconsts.OutcomeValid,
+			ProvingSystem:   lconsts.SP1ID,
+			ImageID:         ids.GenerateTestID(),
+			ArtifactsDigest: make([]byte, sha256.Size),
+			Deadline:        1,
+			Signature:       make([]byte, bls.SignatureLen),
+			PublicKey:       make([]byte, bls.PublicKeyLen),
+		}
+		gomega.Ω(node.vm.Mempool().Len(context.Background())).Should(gomega.Equal(1))
+		vnet.issue(node, forged, node.factory)
+		results := vnet.produce(node)
+		gomega.Ω(results).Should(gomega.HaveLen(2))
+		var failed int
+		for _, result := range results {
+			if !result.Success {
+				failed++
+				gomega.Ω(string(result.Output)).Should(gomega.ContainSubstring(actions.ErrVoteBindingMismatch.Error()))
+			}
+		}
+		gomega.Ω(failed).Should(gomega.Equal(1))
+		for _, node := range vnet.nodes[1:] {
+			expectSuccess(vnet.produce(node))
+		}
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+	})
+
+	ginkgo.It("counts votes relayed by other accounts once", func() {
+		ctx := context.Background()
+		txID := vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(proofValType),
+			TimeOutBlocks: 2*requestTimeOut + 1, // not to clash with other requests
+		})
+		vnet.awaitVotes(txID)
+		// anyone can relay a signed vote, it still counts for the validator
+		node := vnet.nodes[0]
+		vnet.issue(node, pendingVote(node, txID), vnet.factory)
+		results := vnet.produce(node)
+		gomega.Ω(results).Should(gomega.HaveLen(2))
+		var failed int
+		for _, result := range results {
+			if !result.Success {
+				failed++
+				gomega.Ω(string(result.Output)).Should(gomega.ContainSubstring("already voted"))
+			}
+		}
+		gomega.Ω(failed).Should(gomega.Equal(1))
+		for _, node := range vnet.nodes[1:] {
+			expectSuccess(vnet.produce(node))
+		}
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+
+		relayer, err := node.lcli.Participation(ctx, codec.MustAddressBech32(lconsts.HRP, vnet.addr))
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(relayer.VotesCast).Should(gomega.BeZero())
+		validator, err := node.lcli.Participation(ctx, codec.MustAddressBech32(lconsts.HRP, node.addr))
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(validator.RequestsEligible).Should(gomega.BeNumerically(">=", validator.VotesCast))
+	})
+
+	ginkgo.It("refuses votes once a request timed out", func() {
+		txID := vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(expiringProofValType),
+			TimeOutBlocks: requestTimeOut,
+		})
+		vnet.awaitVotes(txID)
+		time.Sleep(requestTimeOut*time.Second + time.Second)
+		for _, node := range vnet.nodes {
+			results := vnet.produce(node)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring("timeout"))
+		}
+		vnet.expectOutcome(txID, false, lconsts.OutcomeValid, 0)
+	})
+
+	ginkgo.It("collects proofs once their request timed out", func() {
+		status := func(node *verifierNode, valType uint16) int {
+			q := url.Values{
+				"image_id": {imageID.String()},
+				"val_type": {strconv.Itoa(int(valType))},
+			}
+			resp, err := http.Get(node.artifacts.URL + "?" + q.Encode())
+			gomega.Ω(err).Should(gomega.BeNil())
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		for i, node := range vnet.nodes {
+			archival := i == len(vnet.nodes)-1
+			if archival {
+				gomega.Consistently(func() int {
+					return status(node, expiringProofValType)
+				}, time.Second, 100*time.Millisecond).Should(gomega.Equal(http.StatusOK))
+			} else {
+				gomega.Eventually(func() int {
+					return status(node, expiringProofValType)
+				}, requestTimeout, 100*time.Millisecond).Should(gomega.Equal(http.StatusNotFound))
+			}
+			// ELFs are kept while their image is in use
+			gomega.Ω(status(node, actions.ELFValType)).Should(gomega.Equal(http.StatusOK))
+
+			usage, err := node.lcli.DiskUsage(context.Background())
+			if i == 0 {
+				gomega.Ω(err).Should(gomega.MatchError(gomega.ContainSubstring(lrpc.ErrAdminAPIDisabled.Error())))
+				continue
+			}
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(usage.Archival).Should(gomega.Equal(archival))
+			gomega.Ω(usage.Files).Should(gomega.BeNumerically(">", 0))
+			if archival {
+				gomega.Ω(usage.CollectedFiles).Should(gomega.BeZero())
+				gomega.Ω(usage.TrackedProofs).Should(gomega.BeZero())
+			} else {
+				gomega.Ω(usage.CollectedFiles).Should(gomega.BeNumerically(">", 0))
+				gomega.Ω(usage.CollectedBytes).Should(gomega.BeNumerically(">", len(files[expiringProofValType])))
+				gomega.Ω(usage.TrackedImages).Should(gomega.BeNumerically(">", 0))
+			}
+		}
+	})
+
+	ginkgo.It("pays validators that agreed with the outcome", func() {
+		ctx := context.Background()
+		var paid uint64
+		for _, node := range vnet.nodes {
+			addrStr := codec.MustAddressBech32(lconsts.HRP, node.addr)
+			before, err := node.lcli.Balance(ctx, addrStr)
+			gomega.Ω(err).Should(gomega.BeNil())
+
+			vnet.issue(node, &actions.ClaimRewards{To: node.addr, TxIDs: []ids.ID{validSP1}}, node.factory)
+			results := vnet.produce(node)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			expectSuccess(results)
+
+			after, err := node.lcli.Balance(ctx, addrStr)
+			gomega.Ω(err).Should(gomega.BeNil())
+			reward := after + results[0].Fee - before
+			gomega.Ω(reward).Should(gomega.BeNumerically("~", vnet.gen.VerificationReward/uint64(len(vnet.nodes)), 1))
+			paid += reward
+
+			participation, err := node.lcli.Participation(ctx, addrStr)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(participation.Claimed).Should(gomega.Equal(reward))
+		}
+		// the last validator to claim is paid what rounding left
+		gomega.Ω(paid).Should(gomega.Equal(vnet.gen.VerificationReward))
+
+		ginkgo.By("refuse claiming twice", func() {
+			node := vnet.nodes[0]
+			vnet.issue(node, &actions.ClaimRewards{To: vnet.addr, TxIDs: []ids.ID{validSP1}}, node.factory)
+			results := vnet.produce(node)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrVoteSettled.Error()))
+		})
+	})
+
+	ginkgo.It("agrees on the public outputs of a proof", func() {
+		agreed := sha256.Sum256([]byte("public values"))
+		other := sha256.Sum256([]byte("other public values"))
+		for i, node := range vnet.nodes {
+			digest := agreed
+			if i == len(vnet.nodes)-1 {
+				digest = other
+			}
+			node.hub.SetPolicy(hub.Outputs(func(*hub.Request) []byte { return digest[:] }, policy))
+		}
+		defer func() {
+			for _, node := range vnet.nodes {
+				node.hub.SetPolicy(policy)
+			}
+		}()
+		txID := vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(proofValType),
+			TimeOutBlocks: requestTimeOut + 2, // not to clash with other requests
+		})
+		expectSuccess(vnet.vote(txID))
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(vnet.nodes)))
+		for _, node := range vnet.nodes {
+			outputs, err := node.lcli.VerifyOutputs(context.Background(), txID)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(outputs).Should(gomega.Equal(agreed[:]))
+		}
+
+		ginkgo.By("refuse claims while voting is open", func() {
+			node := vnet.nodes[0]
+			vnet.issue(node, &actions.ClaimRewards{To: node.addr, TxIDs: []ids.ID{txID}}, node.factory)
+			results := vnet.produce(node)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.ContainSubstring(actions.ErrVotingOpen.Error()))
+		})
+
+		ginkgo.By("only reward the validators reporting the agreed outputs", func() {
+			ctx := context.Background()
+			time.Sleep((requestTimeOut+2)*time.Second + time.Second)
+			for i, node := range vnet.nodes {
+				addrStr := codec.MustAddressBech32(lconsts.HRP, node.addr)
+				before, err := node.lcli.Participation(ctx, addrStr)
+				gomega.Ω(err).Should(gomega.BeNil())
+				vnet.issue(node, &actions.ClaimRewards{To: node.addr, TxIDs: []ids.ID{txID}}, node.factory)
+				results := vnet.produce(node)
+				gomega.Ω(results).Should(gomega.HaveLen(1))
+				expectSuccess(results)
+				after, err := node.lcli.Participation(ctx, addrStr)
+				gomega.Ω(err).Should(gomega.BeNil())
+				if i == len(vnet.nodes)-1 {
+					gomega.Ω(after.VotesAgreeing).Should(gomega.Equal(before.VotesAgreeing))
+				} else {
+					gomega.Ω(after.VotesAgreeing).Should(gomega.Equal(before.VotesAgreeing + 1))
+				}
+			}
+		})
+	})
+})
+
+var _ = ginkgo.Describe("[Commit-reveal]", ginkgo.Ordered, func() {
+	const commitBlocks = 5
+
+	var (
+		vnet    *verifierNetwork
+		imageID ids.ID
+		// voters commit and reveal through their verifier, the last node
+		// votes by hand
+		voters []*verifierNode
+		manual *verifierNode
+	)
+
+	ginkgo.BeforeAll(func() {
+		vnet = newVerifierNetwork(3, hub.AlwaysValid(), func(vnet *verifierNetwork) {
+			vnet.gen.CommitReveal = []*genesis.CommitRevealConfig{{ProvingSystem: lconsts.SP1ID, CommitBlocks: commitBlocks}}
+		})
+		voters, manual = vnet.nodes[:2], vnet.nodes[2]
+		manual.hub.SetPolicy(hub.Error(errors.New("voting by hand")))
+		imageID = vnet.registerSP1()
+	})
+
+	ginkgo.AfterAll(func() {
+		vnet.close()
+	})
+
+	// commit has every voter propose a block with its commitment on [txID].
+	commit := func(txID ids.ID) {
+		for _, node := range voters {
+			awaitPending(node)
+			pendingCommitment(node, txID)
+			expectSuccess(vnet.produce(node))
+		}
+	}
+
+	// reveal has every voter propose a block with its revealed vote on [txID].
+	reveal := func(txID ids.ID) {
+		for _, node := range voters {
+			awaitPending(node)
+			pendingVote(node, txID)
+			expectSuccess(vnet.produce(node))
+		}
+	}
+
+	ginkgo.It("refuses reveals of copied commitments", func() {
+		txID := vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(proofValType),
+			TimeOutBlocks: requestTimeOut,
+		})
+		ginkgo.By("copy the commitment of another validator", func() {
+			awaitPending(voters[0])
+			copied := pendingCommitment(voters[0], txID).Commitment
+			vnet.issue(manual, &actions.CommitVote{
+				TxID:       txID,
+				Commitment: copied,
+				Signature:  manual.sign(actions.GetCommitMessage(txID, copied)),
+				PublicKey:  manual.publicKey,
+			}, manual.factory)
+			expectSuccess(vnet.produce(manual))
+			commit(txID)
+		})
+
+		ginkgo.By("reveal the copied vote", func() {
+			awaitPending(voters[0])
+			copied := *pendingVote(voters[0], txID)
+			copied.RewardAddress = manual.addr
+			copied.PublicKey = manual.publicKey
+			copied.Signature = manual.sign(voteMessage(&copied).Bytes())
+			vnet.issue(manual, &copied, manual.factory)
+			expectFailure(vnet.produce(manual), actions.ErrCommitmentMismatch)
+			reveal(txID)
+		})
+		vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(voters)))
+	})
+
+	ginkgo.It("commits to votes and reveals them once the commit phase is over", func() {
+		ctx := context.Background()
+		txID := vnet.request(&actions.SP1{
+			ImageID:       imageID,
+			ProofValType:  uint64(proofValType),
+			TimeOutBlocks: requestTimeOut + 1, // not to clash with other requests
+		})
+		request, err := storage.GetRequestFromState(ctx, manual.vm.ReadState, txID)
+		gomega.Ω(err).Should(gomega.BeNil())
+		msg := actions.NewVoteMessage(manual.addr, txID, request, lconsts.OutcomeValid, nil)
+		salt := sha256.Sum256([]byte("salt"))
+		vote := &actions.ValidatorVote{
+			TxID:            txID,
+			RewardAddress:   msg.RewardAddress,
+			Outcome:         msg.Outcome,
+			ProvingSystem:   msg.ProvingSystem,
+			ImageID:         msg.ImageID,
+			ArtifactsDigest: msg.ArtifactsDigest,
+			Deadline:        msg.Deadline,
+			Signature:       manual.sign(msg.Bytes()),
+			PublicKey:       manual.publicKey,
+			Salt:            salt[:],
+		}
+
+		ginkgo.By("commit", func() {
+			commitment := actions.VoteCommitment(msg, manual.publicKey, salt[:])
+			vnet.issue(manual, &actions.CommitVote{
+				TxID:       txID,
+				Commitment: commitment,
+				Signature:  manual.sign(actions.GetCommitMessage(txID, commitment)),
+				PublicKey:  manual.publicKey,
+			}, manual.factory)
+			expectSuccess(vnet.produce(manual))
+			commit(txID)
+			vnet.expectOutcome(txID, false, lconsts.OutcomeValid, 0)
+		})
+
+		ginkgo.By("refuse reveals during the commit phase", func() {
+			vnet.issue(manual, vote, manual.factory)
+			expectFailure(vnet.produce(manual), actions.ErrCommitPhaseOpen)
+		})
+
+		ginkgo.By("refuse reveals with the wrong salt", func() {
+			awaitPending(voters[0])
+			wrong := *vote
+			wrong.Salt = make([]byte, len(salt))
+			vnet.issue(manual, &wrong, manual.factory)
+			expectFailure(vnet.produce(manual), actions.ErrCommitmentMismatch)
+		})
+
+		ginkgo.By("reveal", func() {
+			reveal(txID)
+			vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(voters)))
+		})
+
+		ginkgo.By("refuse reveals once voting is over", func() {
+			time.Sleep(time.Until(time.UnixMilli(request.TimeOut)) + time.Second)
+			vnet.issue(manual, vote, manual.factory)
+			expectFailure(vnet.produce(manual), errors.New("timeout"))
+			vnet.expectOutcome(txID, true, lconsts.OutcomeValid, uint64(len(voters)))
+		})
+	})
+})
+
+var _ = ginkgo.Describe("[Canary]", ginkgo.Ordered, func() {
+	const revealBlocks = 10
+
+	var (
+		vnet    *verifierNetwork
+		imageID ids.ID
+	)
+
+	ginkgo.BeforeAll(func() {
+		// validators find every proof valid, canaries included
+		vnet = newVerifierNetwork(3, hub.AlwaysValid(), func(vnet *verifierNetwork) {
+			vnet.gen.CanaryAuthority = codec.MustAddressBech32(lconsts.HRP, vnet.addr)
+			vnet.gen.CanaryRevealBlocks = revealBlocks
+		})
+		imageID = vnet.registerSP1()
+	})
+
+	ginkgo.AfterAll(func() {
+		vnet.close()
+	})
+
+	claim := func(node *verifierNode, txIDs ...ids.ID) []*chain.Result {
+		vnet.issue(node, &actions.ClaimRewards{To: node.addr, TxIDs: txIDs}, node.factory)
+		return vnet.produce(node)
+	}
+
+	ginkgo.It("withholds rewards until canaries can no longer be revealed", func() {
+		ctx := context.Background()
+		node := vnet.nodes[0]
+		salt := sha256.Sum256([]byte("canary salt"))
+		var canaryID, txID ids.ID
+
+		ginkgo.By("designate a canary before requesting it", func() {
+			parser, err := node.lcli.Parser(ctx)
+			gomega.Ω(err).Should(gomega.BeNil())
+			submit, tx, _, err := node.cli.GenerateTransaction(ctx, parser, nil, &actions.SP1{
+				ImageID:       imageID,
+				ProofValType:  uint64(proofValType),
+				TimeOutBlocks: requestTimeOut,
+			}, vnet.factory)
+			gomega.Ω(err).Should(gomega.BeNil())
+			canaryID = tx.ID()
+			vnet.issue(node, &actions.CommitCanary{Commitment: actions.CanaryCommitment(canaryID, salt[:])}, vnet.factory)
+			expectSuccess(vnet.produce(node))
+			gomega.Ω(submit(ctx)).Should(gomega.BeNil())
+			expectSuccess(vnet.produce(node))
+			expectSuccess(vnet.vote(canaryID))
+
+			txID = vnet.request(&actions.SP1{
+				ImageID:       imageID,
+				ProofValType:  uint64(proofValType),
+				TimeOutBlocks: requestTimeOut + 1, // not to clash with the canary
+			})
+			expectSuccess(vnet.vote(txID))
+		})
+
+		request, err := storage.GetRequestFromState(ctx, node.vm.ReadState, txID)
+		gomega.Ω(err).Should(gomega.BeNil())
+
+		ginkgo.By("refuse claims while the canary can be revealed", func() {
+			time.Sleep(time.Until(time.UnixMilli(request.TimeOut)) + time.Second)
+			expectFailure(claim(node, canaryID), actions.ErrCanaryRevealOpen)
+			expectFailure(claim(node, txID), actions.ErrCanaryRevealOpen)
+		})
+
+		ginkgo.By("reveal the canary", func() {
+			voters := make([]codec.Address, len(vnet.nodes))
+			for i, node := range vnet.nodes {
+				voters[i] = node.addr
+			}
+			vnet.issue(node, &actions.RevealCanary{TxID: canaryID, Salt: salt[:], Voters: voters}, vnet.factory)
+			expectSuccess(vnet.produce(node))
+			for _, node := range vnet.nodes {
+				p, err := node.lcli.Participation(ctx, codec.MustAddressBech32(lconsts.HRP, node.addr))
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(p.CanariesFailed).Should(gomega.Equal(uint64(1)))
+				gomega.Ω(p.Penalties).Should(gomega.Equal(vnet.gen.CanaryPenalty))
+				gomega.Ω(p.PenaltiesOwed).Should(gomega.Equal(vnet.gen.CanaryPenalty))
+			}
+		})
+
+		ginkgo.By("withhold penalties from rewards", func() {
+			time.Sleep(time.Until(time.UnixMilli(request.TimeOut)) + revealBlocks*time.Second + time.Second)
+			for _, node := range vnet.nodes {
+				addrStr := codec.MustAddressBech32(lconsts.HRP, node.addr)
+				before, err := node.lcli.Balance(ctx, addrStr)
+				gomega.Ω(err).Should(gomega.BeNil())
+				// valid votes on the canary earn nothing
+				results := claim(node, canaryID, txID)
+				expectSuccess(results)
+				after, err := node.lcli.Balance(ctx, addrStr)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(before - after).Should(gomega.Equal(results[0].Fee))
+
+				p, err := node.lcli.Participation(ctx, addrStr)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(p.VotesAgreeing).Should(gomega.Equal(uint64(1)))
+				gomega.Ω(p.Claimed).Should(gomega.BeZero())
+				gomega.Ω(p.PenaltiesOwed).Should(gomega.BeNumerically("<", vnet.gen.CanaryPenalty))
+			}
+		})
+
+		ginkgo.By("refuse reveals once the reveal window is over", func() {
+			vnet.issue(node, &actions.RevealCanary{TxID: canaryID, Salt: salt[:]}, vnet.factory)
+			expectFailure(vnet.produce(node), actions.ErrCanaryRevealOver)
+		})
+	})
+})
+
+var _ = ginkgo.Describe("[Requester]", func() {
+	post := func(cli *requester.EndpointRequester) error {
+		return cli.Post(context.Background(), protocol.VERIFYENDPOINT, "", &protocol.VerifyRequest{
+			TxID:       ids.Empty.String(),
+			VerifyType: protocol.SP1VERIFY,
+		}, new(protocol.SubmitReply))
+	}
+
+	ginkgo.It("opens the breaker on server errors only", func() {
+		rejecting := startReplyServer(http.StatusBadRequest)
+		defer rejecting.Close()
+		cli := requester.New(rejecting.URL)
+		for i := 0; i < 10; i++ {
+			err := post(cli)
+			gomega.Ω(err).Should(gomega.MatchError(gomega.ContainSubstring("status 400")))
+			gomega.Ω(err).ShouldNot(gomega.MatchError(requester.ErrCircuitOpen))
+		}
+		// rejected requests are not retried either
+		gomega.Ω(rejecting.requests.Load()).Should(gomega.Equal(int64(10)))
+
+		failing := startReplyServer(http.StatusInternalServerError)
+		defer failing.Close()
+		cli = requester.New(failing.URL)
+		cli.Backoff = requester.Backoff{Attempts: 1}
+		gomega.Eventually(func() error {
+			return post(cli)
+		}).WithTimeout(time.Second).Should(gomega.MatchError(requester.ErrCircuitOpen))
+	})
+
+	ginkgo.It("doesn't hold requests the caller cancelled against the endpoint", func() {
+		var requests atomic.Int64
+		received := make(chan struct{}, 10)
+		release := make(chan struct{})
+		hanging := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			received <- struct{}{}
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}))
+		defer hanging.Close()
+		defer close(release)
+		cli := requester.New(hanging.URL)
+		for i := 0; i < 10; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				<-received
+				cancel()
+			}()
+			err := cli.Post(ctx, protocol.VERIFYENDPOINT, "", &protocol.VerifyRequest{
+				TxID:       ids.Empty.String(),
+				VerifyType: protocol.SP1VERIFY,
+			}, new(protocol.SubmitReply))
+			gomega.Ω(err).Should(gomega.MatchError(context.Canceled))
+			gomega.Ω(err).ShouldNot(gomega.MatchError(requester.ErrCircuitOpen))
+		}
+		// nor retried
+		gomega.Ω(requests.Load()).Should(gomega.Equal(int64(10)))
+	})
+
+	ginkgo.It("fails over to other endpoints on server errors only", func() {
+		failing := startReplyServer(http.StatusInternalServerError)
+		defer failing.Close()
+		rejecting := startReplyServer(http.StatusBadRequest)
+		defer rejecting.Close()
+		spare := startReplyServer(http.StatusBadRequest)
+		defer spare.Close()
+
+		do := func(pool *requester.Pool) error {
+			return pool.Do(context.Background(), lconsts.SP1ID, func(_ context.Context, cli *requester.EndpointRequester) error {
+				cli.Backoff = requester.Backoff{Attempts: 1}
+				return post(cli)
+			})
+		}
+		pool := requester.NewPool([]*requester.EndpointConfig{{Uri: failing.URL}, {Uri: rejecting.URL}})
+		gomega.Ω(do(pool)).Should(gomega.MatchError(gomega.ContainSubstring("status 400")))
+		gomega.Ω(failing.requests.Load()).Should(gomega.BeNumerically("<=", 1))
+		gomega.Ω(rejecting.requests.Load()).Should(gomega.Equal(int64(1)))
+		// only an endpoint tried before the rejecting one is unhealthy
+		gomega.Ω(pool.Healthy()).Should(gomega.Equal(2 - int(failing.requests.Load())))
+
+		// a rejected request is not the endpoint's fault
+		pool = requester.NewPool([]*requester.EndpointConfig{{Uri: rejecting.URL}, {Uri: spare.URL}})
+		for i := 0; i < 10; i++ {
+			gomega.Ω(do(pool)).Should(gomega.MatchError(gomega.ContainSubstring("status 400")))
+		}
+		gomega.Ω(rejecting.requests.Load() + spare.requests.Load()).Should(gomega.Equal(int64(11)))
+		gomega.Ω(pool.Healthy()).Should(gomega.Equal(2))
+	})
+})
@@ -0,0 +1,84 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package integration_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/onsi/gomega"
+
+	"github.com/sausaging/hyper-pvzk/hub"
+)
+
+// hubServer is a stand-in for the Rust verifier hub of a single node.
+type hubServer struct {
+	*hub.Hub
+	*httptest.Server
+
+	listenerPort string
+}
+
+func freePort() string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	gomega.Ω(err).Should(gomega.BeNil())
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+func startHub(policy hub.Policy) *hubServer {
+	return startHubWithConfig(&hub.Config{Policy: policy})
+}
+
+// startHubWithConfig starts a hub with [config], pointing it to a free
+// listener port.
+func startHubWithConfig(config *hub.Config) *hubServer {
+	config.ListenerPort = freePort()
+	h := hub.New(config)
+	return &hubServer{
+		Hub:          h,
+		Server:       httptest.NewServer(h),
+		listenerPort: config.ListenerPort,
+	}
+}
+
+// replyServer answers every request with [code], counting the requests it
+// received.
+type replyServer struct {
+	*httptest.Server
+
+	requests atomic.Int64
+}
+
+func startReplyServer(code int) *replyServer {
+	s := new(replyServer)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.requests.Add(1)
+		w.WriteHeader(code)
+	}))
+	return s
+}
+
+// nodeConfig points a node to [h] and has it vote from the account of
+// [valPrivKey], sending artifacts with [transport].
+func nodeConfig(h *hubServer, valPrivKey string, transport string, options map[string]any) []byte {
+	config := map[string]any{
+		"parallelism":       3,
+		"testMode":          true,
+		"logLevel":          "debug",
+		"hubPorturi":        h.URL,
+		"valPrivKey":        valPrivKey,
+		"artifactTransport": transport,
+	}
+	for k, v := range options {
+		config[k] = v
+	}
+	b, err := json.Marshal(config)
+	gomega.Ω(err).Should(gomega.BeNil())
+	return b
+}
@@ -41,7 +41,6 @@ import (
 	"github.com/sausaging/hypersdk/fees"
 	"github.com/sausaging/hypersdk/pebble"
 	hutils "github.com/sausaging/hypersdk/utils"
-	"github.com/sausaging/hypersdk/vm"
 	"github.com/sausaging/hypersdk/workers"
 
 	"github.com/sausaging/hyper-pvzk/actions"
@@ -74,7 +73,7 @@ func init() {
 type instance struct {
 	chainID            ids.ID
 	nodeID             ids.NodeID
-	vm                 *vm.VM
+	vm                 *controller.VM
 	toEngine           chan common.Message
 	JSONRPCServer      *httptest.Server
 	TokenJSONRPCServer *httptest.Server
@@ -2,6 +2,7 @@ package trustless
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -16,7 +17,10 @@ import (
 	"github.com/sausaging/hyper-pvzk/actions"
 	"github.com/sausaging/hyper-pvzk/auth"
 	"github.com/sausaging/hyper-pvzk/consts"
+	"github.com/sausaging/hyper-pvzk/protocol"
+	"github.com/sausaging/hyper-pvzk/storage"
 	"github.com/sausaging/hypersdk/chain"
+	"github.com/sausaging/hypersdk/codec"
 	"github.com/sausaging/hypersdk/crypto/bls"
 	"github.com/sausaging/hypersdk/crypto/ed25519"
 	"github.com/sausaging/hypersdk/fees"
@@ -24,10 +28,11 @@ import (
 	"go.uber.org/zap"
 )
 
+// revealDelay gives the chain time to produce a block past the commit deadline
+const revealDelay = 2 * time.Second
+
 // trustless is the module for rust-server to submit results of verification
 type Trustless struct {
-	port         string
-	listenerPort string
 	// rules        chain.Rules
 	warpSigner *warp.Signer
 	publicKey  *bls.PublicKey
@@ -35,19 +40,25 @@ type Trustless struct {
 
 	l sync.Mutex
 
-	queuedActions   map[ids.ID]uint64
-	verifiedActions map[ids.ID]bool
+	// queuedActions are the requests accepted on chain that the validator
+	// hasn't voted on yet
+	queuedActions map[ids.ID]*queuedAction
 
 	unitPrices func() (fees.Dimensions, error)
 	submit     func(context.Context, bool, []*chain.Transaction) []error
 	rules      func(int64) chain.Rules
+	readState  storage.ReadState
 
 	authFactory chain.AuthFactory
+	// address submits the votes of the validator and is paid their rewards
+	address codec.Address
 }
 
-type SubmitResultArgs struct {
-	TxID    string `json:"tx_id"`
-	IsValid bool   `json:"is_valid"`
+type queuedAction struct {
+	// timeOut is when the request stops taking votes
+	timeOut int64
+	// commitDeadline is 0 unless the request uses commit-reveal voting
+	commitDeadline int64
 }
 
 var _ chain.Parser = (*Parser)(nil)
@@ -65,20 +76,19 @@ func (p *Parser) Registry() (chain.ActionRegistry, chain.AuthRegistry) {
 }
 
 // can be a bit loose as the communiation happens between the two trusted/integrated parties
-func New(port string, listenerPort string, warpSigner *warp.Signer, publicKey *bls.PublicKey, valPrivKey string, logger logging.Logger, unitPrices func() (fees.Dimensions, error), submit func(context.Context, bool, []*chain.Transaction) []error, rules func(int64) chain.Rules) *Trustless {
-	authFactory := auth.NewED25519Factory(ed25519.PrivateKey(common.Hex2Bytes(valPrivKey)))
+func New(warpSigner *warp.Signer, publicKey *bls.PublicKey, valPrivKey string, logger logging.Logger, unitPrices func() (fees.Dimensions, error), submit func(context.Context, bool, []*chain.Transaction) []error, rules func(int64) chain.Rules, readState storage.ReadState) *Trustless {
+	priv := ed25519.PrivateKey(common.Hex2Bytes(valPrivKey))
 	return &Trustless{
-		port:            port,
-		listenerPort:    listenerPort,
-		warpSigner:      warpSigner,
-		publicKey:       publicKey,
-		logger:          logger,
-		queuedActions:   make(map[ids.ID]uint64),
-		verifiedActions: make(map[ids.ID]bool),
-		unitPrices:      unitPrices,
-		submit:          submit,
-		rules:           rules,
-		authFactory:     authFactory,
+		warpSigner:    warpSigner,
+		publicKey:     publicKey,
+		logger:        logger,
+		queuedActions: make(map[ids.ID]*queuedAction),
+		unitPrices:    unitPrices,
+		submit:        submit,
+		rules:         rules,
+		readState:     readState,
+		authFactory:   auth.NewED25519Factory(priv),
+		address:       auth.NewED25519Address(priv.PublicKey()),
 	}
 }
 
@@ -86,23 +96,51 @@ func (t *Trustless) Parser() chain.Parser {
 	return &Parser{t: t}
 }
 
-func (t *Trustless) ListenActions(txID ids.ID, timeOut uint64 /*any further data? like verify type*/) error {
+func (t *Trustless) ListenActions(txID ids.ID, timeOut int64, commitDeadline int64 /*any further data? like verify type*/) error {
 	t.l.Lock()
-	t.queuedActions[txID] = timeOut
+	t.queuedActions[txID] = &queuedAction{
+		timeOut:        timeOut,
+		commitDeadline: commitDeadline,
+	}
 	t.l.Unlock()
 	return nil
 }
 
-func (t *Trustless) ListenResults() {
+// Expire forgets the requests that timed out by [now], whether or not a
+// result was submitted for them.
+func (t *Trustless) Expire(now int64) {
+	t.l.Lock()
+	defer t.l.Unlock()
+	for txID, queued := range t.queuedActions {
+		if queued.timeOut <= now {
+			delete(t.queuedActions, txID)
+		}
+	}
+}
+
+// voted forgets [txID] once the vote of the validator on it is submitted.
+func (t *Trustless) voted(txID ids.ID) {
+	t.l.Lock()
+	defer t.l.Unlock()
+	delete(t.queuedActions, txID)
+}
+
+// ListenResults serves verification results submitted to [listenerPort]
+// until the server fails. [handlers] are served next to the results, by path
+// prefix.
+func (t *Trustless) ListenResults(listenerPort string, handlers map[string]http.Handler) error {
 	r := mux.NewRouter()
+	for prefix, h := range handlers {
+		r.PathPrefix(prefix).Handler(h)
+	}
 
-	r.HandleFunc("/ping", t.ping).Methods("GET")
-	r.HandleFunc("/submit-result", t.submitResult).Methods("POST")
+	r.HandleFunc(protocol.PINGENDPOINT, t.ping).Methods("GET")
+	r.HandleFunc(protocol.SUBMITRESULTENDPOINT, t.submitResult).Methods("POST")
 	srv := &http.Server{
-		Addr:    ":" + t.listenerPort,
+		Addr:    ":" + listenerPort,
 		Handler: r,
 	}
-	srv.ListenAndServe()
+	return srv.ListenAndServe()
 }
 
 func (*Trustless) ping(w http.ResponseWriter, r *http.Request) {
@@ -110,45 +148,118 @@ func (*Trustless) ping(w http.ResponseWriter, r *http.Request) {
 }
 
 func (t *Trustless) submitResult(w http.ResponseWriter, r *http.Request) {
-	var req SubmitResultArgs
+	var req protocol.SubmitResult
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if !protocol.Accepts(req.Version) {
+		http.Error(w, fmt.Sprintf("%s: %d", protocol.ErrUnsupportedVersion, req.Version), http.StatusBadRequest)
+		return
+	}
 	id, err := ids.FromString(req.TxID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	t.l.Lock()
-	t.verifiedActions[id] = req.IsValid
-	t.l.Unlock()
-	// @todo should we return any response??
-	msg := actions.GetMessage(id, req.IsValid)
-	unSigMsg, err := warp.NewUnsignedMessage(t.rules(0).NetworkID(), t.rules(0).ChainID(), msg)
+	// votes are bound to the request as recorded on chain
+	request, err := storage.GetRequestFromState(r.Context(), t.readState, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	outcome := req.Result()
+	outputsDigest, err := req.Outputs()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	sig, err := (*t.warpSigner).Sign(unSigMsg)
+	t.l.Lock()
+	queued := t.queuedActions[id]
+	t.l.Unlock()
+	// @todo should we return any response??
+	msg := actions.NewVoteMessage(t.address, id, request, outcome, outputsDigest)
+	sig, err := t.sign(msg.Bytes())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 	// signature should be valid -> any one can submit it. we check for the public key
-	action := actions.ValidatorVote{
-		TxID:      id,
-		Vote:      req.IsValid,
-		Signature: sig,
-		PublicKey: bls.PublicKeyToBytes(t.publicKey),
+	action := &actions.ValidatorVote{
+		TxID:            id,
+		RewardAddress:   msg.RewardAddress,
+		Outcome:         outcome,
+		ProvingSystem:   msg.ProvingSystem,
+		ImageID:         msg.ImageID,
+		ArtifactsDigest: msg.ArtifactsDigest,
+		Deadline:        msg.Deadline,
+		Signature:       sig,
+		PublicKey:       bls.PublicKeyToBytes(t.publicKey),
+		OutputsDigest:   msg.OutputsDigest,
 	}
 	parser := t.Parser()
 
-	txID, err := t.GenerateTransaction(context.Background(), parser, &action, t.authFactory)
+	if queued != nil && queued.commitDeadline > 0 {
+		txID, err := t.commitVote(parser, action, msg, queued.commitDeadline)
+		if err != nil {
+			t.logger.Error("error in submitting commitment", zap.Error(err))
+		}
+		fmt.Fprintf(w, "Commitment submitted. %s\n", txID.String())
+		return
+	}
+	txID, err := t.GenerateTransaction(context.Background(), parser, action, t.authFactory)
 	if err != nil {
 		t.logger.Error("error in submitting tx", zap.Error(err))
+	} else {
+		t.voted(id)
 	}
 	fmt.Fprintf(w, "Result submitted. %s\n", txID.String())
 }
 
+// commitVote submits a commitment to [vote], signed over [msg], and reveals
+// it once the commit phase ending at [deadline] is over.
+func (t *Trustless) commitVote(parser chain.Parser, vote *actions.ValidatorVote, msg *actions.VoteMessage, deadline int64) (ids.ID, error) {
+	salt := make([]byte, actions.MaxSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return ids.Empty, fmt.Errorf("%w: unable to generate salt", err)
+	}
+	vote.Salt = salt
+	commitment := actions.VoteCommitment(msg, vote.PublicKey, salt)
+	sig, err := t.sign(actions.GetCommitMessage(vote.TxID, commitment))
+	if err != nil {
+		return ids.Empty, err
+	}
+	txID, err := t.GenerateTransaction(context.Background(), parser, &actions.CommitVote{
+		TxID:       vote.TxID,
+		Commitment: commitment,
+		Signature:  sig,
+		PublicKey:  vote.PublicKey,
+	}, t.authFactory)
+	if err != nil {
+		return txID, err
+	}
+	go func() {
+		// reveals are only accepted in blocks produced after the deadline
+		time.Sleep(time.Until(time.UnixMilli(deadline)) + revealDelay)
+		if _, err := t.GenerateTransaction(context.Background(), parser, vote, t.authFactory); err != nil {
+			t.logger.Error("error in revealing vote", zap.Stringer("txID", vote.TxID), zap.Error(err))
+			return
+		}
+		t.voted(vote.TxID)
+	}()
+	return txID, nil
+}
+
+func (t *Trustless) sign(msg []byte) ([]byte, error) {
+	rules := t.rules(0)
+	unSigMsg, err := warp.NewUnsignedMessage(rules.NetworkID(), rules.ChainID(), msg)
+	if err != nil {
+		return nil, err
+	}
+	return (*t.warpSigner).Sign(unSigMsg)
+}
+
 func (t *Trustless) GenerateTransaction(
 	ctx context.Context,
 	parser chain.Parser,